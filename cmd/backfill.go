@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
+
+	"github.com/konstantinfoerster/card-service-go/internal/aio"
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/imaging"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/postgres"
+	"github.com/konstantinfoerster/card-service-go/internal/config"
+)
+
+// defaultHashBatchSize the number of images that are hashed and written back together.
+const defaultHashBatchSize = 100
+
+// backfill computes the missing hashes of all card images.
+func backfill(cfg config.Config, args []string) error {
+	fs := flag.NewFlagSet("hash-backfill", flag.ContinueOnError)
+	workers := fs.Int("workers", runtime.NumCPU(), "number of images that are hashed at the same time")
+	batchSize := fs.Int("batch", defaultHashBatchSize, "number of images that are written back together")
+	afterID := fs.Int("after", 0, "skip all images up to this id, used to continue an aborted run")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	dbCon, err := postgres.Connect(ctx, cfg.Database)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database %w", err)
+	}
+	defer aio.Close(dbCon)
+
+	return runBackfill(ctx, cfg, dbCon, *workers, *batchSize, *afterID)
+}
+
+// runBackfill computes the missing image and region hashes of the configured detection.
+func runBackfill(
+	ctx context.Context, cfg config.Config, dbCon *postgres.DBConnection, workers, batchSize, afterID int,
+) error {
+	store, err := cfg.Images.Store()
+	if err != nil {
+		return err
+	}
+	svc := cards.NewBackfillService(postgres.NewHashRepository(dbCon), imaging.NewHasher(store))
+	result, err := svc.Run(ctx, cards.BackfillConfig{
+		Algorithms: cfg.Detection.Algorithms(),
+		Rerank:     cfg.Detection.Rerank,
+		Workers:    workers,
+		BatchSize:  batchSize,
+		AfterID:    afterID,
+	})
+	if err != nil {
+		return fmt.Errorf("hash backfill stopped, continue with -after %d, %w", result.LastID, err)
+	}
+
+	return nil
+}
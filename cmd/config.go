@@ -0,0 +1,23 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/konstantinfoerster/card-service-go/internal/config"
+)
+
+// validateConfig checks the configuration, it is already loaded and validated by the setup.
+// The image store is created as well to find an invalid store configuration before the start.
+func validateConfig(cfg config.Config, args []string) error {
+	if len(args) != 1 || args[0] != "validate" {
+		return fmt.Errorf("%w config %v, usage: config validate", errUnknownCommand, args)
+	}
+
+	if _, err := cfg.Images.Store(); err != nil {
+		return fmt.Errorf("invalid image store, %w", err)
+	}
+
+	fmt.Println("configuration is valid")
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/evaluation"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/imaging"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/memory"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/storage"
+	"github.com/konstantinfoerster/card-service-go/internal/config"
+)
+
+var errUnknownFormat = errors.New("unknown format, supported formats are markdown and json")
+
+// detect-eval runs a directory of labeled photos through the detection and reports the accuracy.
+func main() {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	slog.SetDefault(logger)
+
+	var configPath, samplesDir, catalogPath, imagesDir, format string
+	flag.StringVar(&configPath, "c", "", "optional configuration file, only the detection settings are used")
+	flag.StringVar(&samplesDir, "samples", "", "directory with photos named <card id>-<face id>[-description].<ext>")
+	flag.StringVar(&catalogPath, "cards", "", "json file with the cards that can be detected")
+	flag.StringVar(&imagesDir, "images", ".", "directory with the card images referenced by the cards file")
+	flag.StringVar(&format, "format", "markdown", "output format, markdown or json")
+	flag.Parse()
+
+	if err := run(configPath, samplesDir, catalogPath, imagesDir, format); err != nil {
+		slog.Error("evaluation failed", slog.Any("error", err))
+		os.Exit(1)
+	}
+}
+
+func run(configPath, samplesDir, catalogPath, imagesDir, format string) error {
+	if format != "markdown" && format != "json" {
+		return errUnknownFormat
+	}
+
+	dCfg := cards.DetectConfig{}
+	if configPath != "" {
+		cfg, err := config.NewConfig(configPath)
+		if err != nil {
+			return err
+		}
+		dCfg = cfg.Detection
+	}
+
+	samples, err := evaluation.LoadSamples(samplesDir)
+	if err != nil {
+		return err
+	}
+	catalog, err := evaluation.LoadCatalog(catalogPath)
+	if err != nil {
+		return err
+	}
+
+	cRepo, err := memory.NewCardRepository(catalog, nil)
+	if err != nil {
+		return err
+	}
+	dRepo, err := memory.NewDetectRepository(catalog, storage.NewLocalStore(imagesDir), dCfg)
+	if err != nil {
+		return fmt.Errorf("failed to hash card images %w", err)
+	}
+
+	eval := evaluation.New(dCfg, cRepo, dRepo, imaging.NewDetector(), cards.WithOCR(imaging.NewOCR()))
+	report := eval.Run(context.Background(), samples)
+	if format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(report)
+	}
+
+	return report.WriteMarkdown(os.Stdout)
+}
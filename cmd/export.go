@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/konstantinfoerster/card-service-go/internal/aio"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/catalog"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/postgres"
+	"github.com/konstantinfoerster/card-service-go/internal/config"
+)
+
+// export writes all card images with their cards as json, e.g. as cards file of the detection evaluation.
+func export(cfg config.Config, args []string) error {
+	defaultBatchSize := 1000
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	output := fs.String("o", "-", "file the cards are written to, - writes to stdout")
+	batchSize := fs.Int("batch", defaultBatchSize, "number of cards that are read together")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	dbCon, err := postgres.Connect(ctx, cfg.Database)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database %w", err)
+	}
+	defer aio.Close(dbCon)
+
+	exporter := catalog.NewExporter(postgres.NewCatalogRepository(dbCon))
+	if *output == "-" {
+		return writeExport(ctx, exporter, os.Stdout, *batchSize)
+	}
+
+	f, err := os.Create(filepath.Clean(*output))
+	if err != nil {
+		return fmt.Errorf("failed to create export file %w", err)
+	}
+	if err := writeExport(ctx, exporter, f, *batchSize); err != nil {
+		aio.Close(f)
+
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close export file %w", err)
+	}
+
+	return nil
+}
+
+func writeExport(ctx context.Context, exporter *catalog.Exporter, out io.Writer, batchSize int) error {
+	w := bufio.NewWriter(out)
+	if _, err := exporter.Run(ctx, w, batchSize); err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
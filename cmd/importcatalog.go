@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
+
+	"github.com/konstantinfoerster/card-service-go/internal/aio"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/catalog"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/postgres"
+	"github.com/konstantinfoerster/card-service-go/internal/config"
+)
+
+var errMissingFile = errors.New("missing catalog file")
+
+// importCatalog stores the cards of a Scryfall or MTGJSON bulk file.
+func importCatalog(cfg config.Config, args []string) error {
+	defaultBatchSize := 500
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	batchSize := fs.Int("batch", defaultBatchSize, "number of cards that are written together")
+	hash := fs.Bool("hash", false, "compute the missing image and region hashes after the import")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("%w, usage: import [-batch n] [-hash] <file>", errMissingFile)
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("failed to open catalog file %w", err)
+	}
+	defer aio.Close(f)
+
+	r, err := catalog.NewReader(f)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	dbCon, err := postgres.Connect(ctx, cfg.Database)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database %w", err)
+	}
+	defer aio.Close(dbCon)
+
+	result, err := catalog.NewImporter(postgres.NewCatalogRepository(dbCon)).Run(ctx, r, *batchSize)
+	if err != nil {
+		return fmt.Errorf("catalog import stopped after %d added and %d updated cards, %w",
+			result.Added, result.Updated, err)
+	}
+
+	fmt.Printf("added: %d, updated: %d, skipped: %d\n", result.Added, result.Updated, result.Skipped)
+
+	if !*hash {
+		return nil
+	}
+
+	return runBackfill(ctx, cfg, dbCon, runtime.NumCPU(), defaultHashBatchSize, 0)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
+
+	"github.com/konstantinfoerster/card-service-go/internal/aio"
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/postgres"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/storage"
+	"github.com/konstantinfoerster/card-service-go/internal/config"
+)
+
+var errNoImageStore = errors.New("images.dir or images.s3 must be configured to store the images")
+
+// defaultIngestBatchSize the number of images that are read from the database together.
+const defaultIngestBatchSize = 100
+
+// ingest downloads the images of all cards from the image host and stores them in the configured image store.
+func ingest(cfg config.Config, args []string) error {
+	fs := flag.NewFlagSet("ingest-images", flag.ContinueOnError)
+	source := fs.String("source", cfg.Images.Host, "directory or http(s) URL the images are copied from")
+	workers := fs.Int("workers", runtime.NumCPU(), "number of images that are downloaded at the same time")
+	batchSize := fs.Int("batch", defaultIngestBatchSize, "number of images that are read together")
+	afterID := fs.Int("after", 0, "skip all images up to this id, used to continue an aborted run")
+	overwrite := fs.Bool("overwrite", false, "replace images that already exist in the image store")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !cfg.Images.Served() {
+		return errNoImageStore
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	dbCon, err := postgres.Connect(ctx, cfg.Database)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database %w", err)
+	}
+	defer aio.Close(dbCon)
+
+	from, err := storage.New(*source, storage.S3Config{})
+	if err != nil {
+		return err
+	}
+	to, err := cfg.Images.Store()
+	if err != nil {
+		return err
+	}
+	svc := cards.NewIngestService(postgres.NewImageRepository(dbCon), from, to)
+	result, err := svc.Run(ctx, cards.IngestConfig{
+		Workers:   *workers,
+		BatchSize: *batchSize,
+		AfterID:   *afterID,
+		Overwrite: *overwrite,
+	})
+	if err != nil {
+		return fmt.Errorf("image ingest stopped, continue with -after %d, %w", result.LastID, err)
+	}
+	fmt.Printf("stored: %d, skipped: %d, failed: %d\n", result.Stored, result.Skipped, result.Failed)
+
+	return nil
+}
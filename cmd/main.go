@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log/slog"
@@ -17,15 +18,21 @@ import (
 	"github.com/konstantinfoerster/card-service-go/internal/auth"
 	"github.com/konstantinfoerster/card-service-go/internal/cards"
 	"github.com/konstantinfoerster/card-service-go/internal/cards/imaging"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/memory"
 	"github.com/konstantinfoerster/card-service-go/internal/cards/postgres"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/storage"
 	"github.com/konstantinfoerster/card-service-go/internal/config"
+	"github.com/konstantinfoerster/card-service-go/internal/jobs"
 	"golang.org/x/sync/errgroup"
 )
 
-func setup() config.Config {
+var errUnknownCommand = errors.New("unknown command")
+
+// setup configures the logger and loads the configuration, shared by all commands.
+func setup() (config.Config, error) {
 	wd, err := os.Getwd()
 	if err != nil {
-		panic(err)
+		return config.Config{}, err
 	}
 	wd += "/"
 
@@ -50,15 +57,16 @@ func setup() config.Config {
 	var configPath string
 	flag.StringVar(&configPath, "c", "./configs/application.yaml", "path to the configuration file")
 	flag.StringVar(&configPath, "config", "./configs/application.yaml", "path to the configuration file")
+	flag.Usage = usage
 	flag.Parse()
 
 	cfg, err := config.NewConfig(configPath)
 	if err != nil {
-		panic(err)
+		return config.Config{}, fmt.Errorf("failed to load config %s, %w", configPath, err)
 	}
 
 	if err := logLevel.UnmarshalText([]byte(cfg.Logging.Level)); err != nil {
-		panic(err)
+		return config.Config{}, fmt.Errorf("invalid logging level, %w", err)
 	}
 
 	slog.Info("logging", slog.String("value", logLevel.Level().Level().String()))
@@ -68,19 +76,90 @@ func setup() config.Config {
 		slog.Int("cpu", runtime.NumCPU()),
 	))
 
-	return cfg
+	return cfg, nil
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: %s [-c config] <command> [flags] [args]\n\nCommands:\n", os.Args[0])
+	fmt.Fprint(out, `  serve            start the web and probe server, the default command
+  migrate          apply, revert or list the database migrations (up|down|status)
+  import           import the cards of a Scryfall or MTGJSON bulk file
+  export           export the card images with their cards as json
+  hash-backfill    compute the missing hashes of all card images
+  ingest-images    copy the card images from the image host into the image store
+  user             show a user, grant or revoke a role or delete a user (show|grant|revoke|delete)
+  config validate  check the configuration file
+
+Deprecated:
+  import-catalog   use import
+  backfill-hashes  use hash-backfill
+
+Flags:
+`)
+	flag.PrintDefaults()
 }
 
 func main() {
-	cfg := setup()
+	cfg, err := setup()
+	if err != nil {
+		slog.Error("setup error", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	args := flag.Args()
+	if len(args) == 0 {
+		args = []string{"serve"}
+	}
+
+	cmd := args[0]
+	if name, ok := renamedCommand(cmd); ok {
+		slog.Warn("the command is deprecated and will be removed", slog.String("command", cmd),
+			slog.String("use", name))
+		cmd = name
+	}
+
+	switch cmd {
+	case "serve":
+		err = serve(cfg)
+	case "migrate":
+		err = migrate(cfg, args[1:])
+	case "import":
+		err = importCatalog(cfg, args[1:])
+	case "export":
+		err = export(cfg, args[1:])
+	case "hash-backfill":
+		err = backfill(cfg, args[1:])
+	case "ingest-images":
+		err = ingest(cfg, args[1:])
+	case "user":
+		err = user(cfg, args[1:])
+	case "config":
+		err = validateConfig(cfg, args[1:])
+	default:
+		err = fmt.Errorf("%w %s", errUnknownCommand, cmd)
+	}
 
-	if err := run(cfg); err != nil {
+	if err != nil {
 		slog.Error("run error", slog.Any("error", err))
 		os.Exit(1)
 	}
 }
 
-func run(cfg config.Config) error {
+// renamedCommand returns the current name of a renamed command, the former names keep working until they are removed.
+func renamedCommand(cmd string) (string, bool) {
+	switch cmd {
+	case "import-catalog":
+		return "import", true
+	case "backfill-hashes":
+		return "hash-backfill", true
+	default:
+		return cmd, false
+	}
+}
+
+// serve starts the web server, the probe server and the background workers.
+func serve(cfg config.Config) error {
 	ctx := context.Background()
 	dbCon, err := postgres.Connect(ctx, cfg.Database)
 	if err != nil {
@@ -88,7 +167,14 @@ func run(cfg config.Config) error {
 	}
 	defer aio.Close(dbCon)
 
-	oidcProvider, err := auth.FromConfiguration(cfg.Oidc)
+	if cfg.Database.AutoMigrate {
+		if _, err := postgres.NewMigrator(dbCon).Up(ctx); err != nil {
+			return fmt.Errorf("failed to migrate database %w", err)
+		}
+	}
+
+	accountRepo := postgres.NewAccountRepository(dbCon)
+	oidcProvider, err := auth.FromConfiguration(cfg.Oidc, accountRepo)
 	if err != nil {
 		return fmt.Errorf("failed to load oidc provider, %w", err)
 	}
@@ -103,10 +189,38 @@ func run(cfg config.Config) error {
 	collectRepo := postgres.NewCollectionRepository(dbCon, cfg.Images)
 	collectSvc := cards.NewCollectionService(collectRepo)
 
-	detectRep := postgres.NewDetectRepository(dbCon, cfg.Images)
-	detectSvc := cards.NewDetectService(cardRepo, detectRep, detector)
+	detectRep := postgres.NewDetectRepository(dbCon, cfg.Images, cfg.Detection)
+	if err = detectRep.Load(ctx); err != nil {
+		return fmt.Errorf("failed to load hash index, %w", err)
+	}
+	detectSvc := cards.NewDetectService(
+		cfg.Detection, cardRepo, detectRep, collectRepo, detector, cards.WithOCR(imaging.NewOCR()),
+	)
+
+	imageStore, err := cfg.Images.Store()
+	if err != nil {
+		return err
+	}
+	thumbnails := imaging.NewThumbnails(imageStore, storage.NewLocalStore(cfg.Images.CacheDir))
+	imageSvc := cards.NewImageService(postgres.NewImageRepository(dbCon), thumbnails)
+
+	var jobRepo jobs.Repository = memory.NewJobRepository()
+	if cfg.Jobs.Store == jobs.StorePostgres {
+		jobRepo = postgres.NewJobRepository(dbCon)
+	}
+	jobQueue := jobs.NewQueue(cfg.Jobs, jobRepo)
+
+	tokenRepo := postgres.NewTokenRepository(dbCon)
+	tokenSvc := auth.NewTokenService(tokenRepo, timeSvc)
 
-	authMiddleware := web.NewAuthMiddleware(cfg.Oidc, authSvc)
+	roleRepo := postgres.NewRoleRepository(dbCon)
+	roleSvc := auth.NewRoleService(cfg.Oidc, roleRepo)
+
+	userRepo := postgres.NewUserRepository(dbCon)
+	userSvc := auth.NewUserService(userRepo, timeSvc)
+
+	authMiddleware := web.NewAuthMiddleware(cfg.Oidc, authSvc,
+		auth.WithBearer(tokenSvc), auth.WithProfile(userSvc), auth.WithRoles(roleSvc))
 
 	srv := web.NewServer(cfg.Server).RegisterRoutes(func(r fiber.Router) {
 		r.Static("/public", "./public")
@@ -114,11 +228,20 @@ func run(cfg config.Config) error {
 		cardsapi.DashboardRoutes(r, authMiddleware)
 		cardsapi.SearchRoutes(r, authMiddleware, cardSvc)
 		cardsapi.CollectionRoutes(r, authMiddleware, collectSvc)
-		cardsapi.DetectRoutes(r, authMiddleware, detectSvc)
+		cardsapi.DetectRoutes(r, authMiddleware, cfg.Server.Upload, detectSvc)
+		if cfg.Images.Served() {
+			cardsapi.ImageRoutes(r, imageSvc)
+		}
 
 		apiV1 := r.Group("/api").Group("/v1")
 
-		loginapi.Routes(apiV1, authMiddleware, cfg.Oidc, authSvc, timeSvc)
+		cardsapi.DetectJobRoutes(apiV1, authMiddleware, cfg.Server.Upload, detectSvc, jobQueue)
+		loginapi.Routes(apiV1, authMiddleware, cfg.Oidc, authSvc, userSvc, timeSvc)
+		loginapi.UserRoutes(apiV1, authMiddleware, cfg.Oidc, userSvc, authSvc, timeSvc)
+		loginapi.TokenRoutes(apiV1, authMiddleware, tokenSvc)
+		if local, ok := oidcProvider.Local(); ok {
+			loginapi.LocalRoutes(apiV1, authMiddleware, local)
+		}
 	})
 
 	errg, ctx := errgroup.WithContext(context.Background())
@@ -127,6 +250,16 @@ func run(cfg config.Config) error {
 		return srv.Run(ctx)
 	})
 
+	// execute the detection jobs
+	errg.Go(func() error {
+		return jobQueue.Run(ctx)
+	})
+
+	// keep the hash index up to date
+	errg.Go(func() error {
+		return detectRep.Watch(ctx, cfg.Detection.IndexRefresh)
+	})
+
 	readinessProbe := func(c *fiber.Ctx) bool {
 		// TODO: implement readiness probe
 		return true
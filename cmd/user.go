@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/konstantinfoerster/card-service-go/internal/aio"
+	"github.com/konstantinfoerster/card-service-go/internal/auth"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/postgres"
+	"github.com/konstantinfoerster/card-service-go/internal/config"
+)
+
+var errUnknownRole = errors.New("unknown role")
+
+// user shows a user with its roles, grants or revokes a role of a user or deletes a user with all its data.
+func user(cfg config.Config, args []string) error {
+	fs := flag.NewFlagSet("user", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	action, userID := fs.Arg(0), fs.Arg(1)
+	wantArgs := map[string]int{"show": 2, "grant": 3, "revoke": 3, "delete": 2}
+	if n, ok := wantArgs[action]; !ok || fs.NArg() != n || userID == "" {
+		return fmt.Errorf("%w user %q, usage: user show <id> | user grant <id> <role> | user revoke <id> <role> | "+
+			"user delete <id>", errUnknownCommand, action)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	dbCon, err := postgres.Connect(ctx, cfg.Database)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database %w", err)
+	}
+	defer aio.Close(dbCon)
+
+	userSvc := auth.NewUserService(postgres.NewUserRepository(dbCon), auth.NewTimeService())
+	roleRepo := postgres.NewRoleRepository(dbCon)
+	switch action {
+	case "show":
+		u, err := userSvc.Get(ctx, userID)
+		if err != nil {
+			return err
+		}
+		roles, err := roleRepo.Roles(ctx, userID)
+		if err != nil {
+			return err
+		}
+		names := make([]string, 0, len(roles))
+		for _, r := range roles {
+			names = append(names, string(r))
+		}
+		fmt.Printf("id: %s\nprovider: %s\nemail: %s\nname: %s\ncreated: %s\nroles: %s\n",
+			u.ID, u.Provider, u.Email, u.DisplayName, u.CreatedAt.Format(time.RFC3339), strings.Join(names, ","))
+	case "grant":
+		role, ok := auth.ParseRole(fs.Arg(2))
+		if !ok {
+			return fmt.Errorf("%w %q", errUnknownRole, fs.Arg(2))
+		}
+		if _, err := userSvc.Get(ctx, userID); err != nil {
+			return err
+		}
+		if err := roleRepo.Assign(ctx, userID, role); err != nil {
+			return err
+		}
+		fmt.Printf("granted %s to %s\n", role, userID)
+	case "revoke":
+		role, ok := auth.ParseRole(fs.Arg(2))
+		if !ok {
+			return fmt.Errorf("%w %q", errUnknownRole, fs.Arg(2))
+		}
+		if err := roleRepo.Revoke(ctx, userID, role); err != nil {
+			return err
+		}
+		fmt.Printf("revoked %s from %s\n", role, userID)
+	default:
+		if err := userSvc.Delete(ctx, userID); err != nil {
+			return err
+		}
+		fmt.Printf("deleted %s\n", userID)
+	}
+
+	return nil
+}
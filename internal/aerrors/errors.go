@@ -11,6 +11,9 @@ var (
 	ErrNotFound      = ErrorType{"not-found"}     //nolint:gochecknoglobals
 	ErrInvalidInput  = ErrorType{"invalid-input"} //nolint:gochecknoglobals
 	ErrAuthorization = ErrorType{"authorization"} //nolint:gochecknoglobals
+	ErrForbidden     = ErrorType{"forbidden"}     //nolint:gochecknoglobals
+	ErrTooLarge      = ErrorType{"too-large"}     //nolint:gochecknoglobals
+	ErrUnavailable   = ErrorType{"unavailable"}   //nolint:gochecknoglobals
 )
 
 type AppError struct {
@@ -28,6 +31,14 @@ func NewAuthorizationError(err error, key string) AppError {
 	}
 }
 
+func NewForbiddenError(err error, key string) AppError {
+	return AppError{
+		Cause:     errors.WithStack(err),
+		Key:       key,
+		ErrorType: ErrForbidden,
+	}
+}
+
 func NewInvalidInputError(err error, key string, msg string) AppError {
 	return AppError{
 		Cause:     errors.WithStack(err),
@@ -49,6 +60,24 @@ func NewNotFoundError(err error, key string) AppError {
 	}
 }
 
+func NewTooLargeError(err error, key string, msg string) AppError {
+	return AppError{
+		Cause:     errors.WithStack(err),
+		Key:       key,
+		Msg:       msg,
+		ErrorType: ErrTooLarge,
+	}
+}
+
+func NewUnavailableError(err error, key string, msg string) AppError {
+	return AppError{
+		Cause:     errors.WithStack(err),
+		Key:       key,
+		Msg:       msg,
+		ErrorType: ErrUnavailable,
+	}
+}
+
 func NewUnknownError(err error, key string) AppError {
 	return AppError{
 		Cause:     errors.WithStack(err),
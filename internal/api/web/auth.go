@@ -2,13 +2,18 @@ package web
 
 import (
 	"errors"
+	"fmt"
+	"slices"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/konstantinfoerster/card-service-go/internal/aerrors"
 	"github.com/konstantinfoerster/card-service-go/internal/auth"
 )
 
 var (
 	ErrNoUserInContext = errors.New("no user in context")
+	ErrMissingRole     = errors.New("missing role")
+	ErrSessionRequired = errors.New("session required")
 )
 
 const UserContextKey = "userid"
@@ -17,6 +22,13 @@ const UserContextKey = "userid"
 type User struct {
 	ID       string
 	Username string
+	// Email the email confirmed by the provider, empty for local accounts.
+	Email string
+	// Subject the id of the user at the provider of the current session.
+	Subject string
+	Roles   []auth.Role
+	// Bearer true if the user is authenticated by a personal access token instead of a session.
+	Bearer bool
 }
 
 // NewUser creates a new User.
@@ -24,6 +36,32 @@ func NewUser(id, username string) User {
 	return User{ID: id, Username: username}
 }
 
+// WithEmail returns a copy of the user with the given email.
+func (u User) WithEmail(email string) User {
+	u.Email = email
+
+	return u
+}
+
+// WithSubject returns a copy of the user with the given provider subject.
+func (u User) WithSubject(subject string) User {
+	u.Subject = subject
+
+	return u
+}
+
+// WithRoles returns a copy of the user with the given roles.
+func (u User) WithRoles(roles ...auth.Role) User {
+	u.Roles = roles
+
+	return u
+}
+
+// HasRole returns true if the user has the given role.
+func (u User) HasRole(role auth.Role) bool {
+	return slices.Contains(u.Roles, role)
+}
+
 // UserFromCtx returns an authenticated User or an ErrNoUserInContext if there is no user.
 func UserFromCtx(ctx *fiber.Ctx) (User, error) {
 	u, ok := ctx.Locals(UserContextKey).(User)
@@ -40,14 +78,30 @@ type AuthMiddleware struct {
 }
 
 // NewAuthMiddleware provides fiber.Handler that can be used to ensure an authentciated access.
-func NewAuthMiddleware(cfg auth.Config, svc auth.Service) AuthMiddleware {
+// Additional options like auth.WithBearer are applied to both handlers.
+func NewAuthMiddleware(cfg auth.Config, svc auth.Service, opts ...func(*auth.MiddlewareConfig)) AuthMiddleware {
 	authFn := func(ctx *fiber.Ctx, claims auth.Claims) {
-		u := NewUser(claims.ID, claims.Email)
+		subject := claims.Subject
+		if subject == "" {
+			subject = claims.ID
+		}
+		u := NewUser(claims.ID, claims.DisplayName()).
+			WithEmail(claims.Email).
+			WithSubject(subject).
+			WithRoles(claims.Roles...)
+		u.Bearer = claims.Bearer
 		ctx.Locals(UserContextKey, u)
 	}
 
-	relaxed := auth.NewOAuthMiddleware(svc, auth.WithAuthorized(authFn), auth.WithConfig(cfg), auth.AllowUnauthorized())
-	required := auth.NewOAuthMiddleware(svc, auth.WithAuthorized(authFn), auth.WithConfig(cfg))
+	relaxedOpts := append([]func(*auth.MiddlewareConfig){
+		auth.WithAuthorized(authFn), auth.WithConfig(cfg), auth.AllowUnauthorized(),
+	}, opts...)
+	requiredOpts := append([]func(*auth.MiddlewareConfig){
+		auth.WithAuthorized(authFn), auth.WithConfig(cfg),
+	}, opts...)
+
+	relaxed := auth.NewOAuthMiddleware(svc, relaxedOpts...)
+	required := auth.NewOAuthMiddleware(svc, requiredOpts...)
 
 	return AuthMiddleware{
 		relaxed:  relaxed,
@@ -66,9 +120,44 @@ func (r AuthMiddleware) Required() func(*fiber.Ctx) error {
 	return r.required
 }
 
+// RequireRole ensures that the authenticated user has the given role.
+// Must be used after Required or Relaxed.
+func (r AuthMiddleware) RequireRole(role auth.Role) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		u, err := UserFromCtx(c)
+		if err != nil {
+			return aerrors.NewAuthorizationError(err, "unauthorized")
+		}
+
+		if !u.HasRole(role) {
+			return aerrors.NewForbiddenError(fmt.Errorf("%s, %w", role, ErrMissingRole), "forbidden")
+		}
+
+		return c.Next()
+	}
+}
+
+// RequireSession ensures that the user is authenticated by a session, personal access tokens are rejected.
+// Must be used after Required.
+func (r AuthMiddleware) RequireSession() func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		u, err := UserFromCtx(c)
+		if err != nil {
+			return aerrors.NewAuthorizationError(err, "unauthorized")
+		}
+
+		if u.Bearer {
+			return aerrors.NewForbiddenError(ErrSessionRequired, "session-required")
+		}
+
+		return c.Next()
+	}
+}
+
 type ClientUser struct {
-	Username string `json:"username"`
-	Initials string `json:"initials"`
+	Username string      `json:"username"`
+	Initials string      `json:"initials"`
+	Roles    []auth.Role `json:"roles,omitempty"`
 }
 
 func NewClientUser(u User) *ClientUser {
@@ -81,10 +170,12 @@ func NewClientUser(u User) *ClientUser {
 		username = "Unknown"
 	}
 
-	initials := []rune(username)[0:2]
+	runes := []rune(username)
+	initials := runes[:min(2, len(runes))]
 
 	return &ClientUser{
 		Username: username,
 		Initials: string(initials),
+		Roles:    u.Roles,
 	}
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/konstantinfoerster/card-service-go/internal/api/web"
+	"github.com/konstantinfoerster/card-service-go/internal/auth"
 	"github.com/konstantinfoerster/card-service-go/internal/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -81,3 +82,137 @@ func TestUserFromCtxInvalidInput(t *testing.T) {
 		})
 	}
 }
+
+func TestRequireRole(t *testing.T) {
+	cases := []struct {
+		name           string
+		user           *web.User
+		expectedStatus int
+	}{
+		{
+			name:           "has role",
+			user:           &web.User{ID: "myuser", Roles: []auth.Role{auth.RoleUser, auth.RoleAdmin}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "missing role",
+			user:           &web.User{ID: "myuser", Roles: []auth.Role{auth.RoleUser}},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "no user",
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			authMiddleware := web.NewAuthMiddleware(auth.Config{}, auth.New(auth.Config{}, auth.NewProviders()))
+			app := fiber.New(fiber.Config{ErrorHandler: web.RespondWithProblemJSON})
+			app.Get("/test", func(c *fiber.Ctx) error {
+				if tc.user != nil {
+					c.Locals(web.UserContextKey, *tc.user)
+				}
+
+				return c.Next()
+			}, authMiddleware.RequireRole(auth.RoleAdmin), func(c *fiber.Ctx) error {
+				return c.SendString("OK")
+			})
+			req := test.NewRequest(
+				test.WithMethod(http.MethodGet),
+				test.WithURL("/test"),
+			)
+
+			resp, err := app.Test(req)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+		})
+	}
+}
+
+func TestRequireSession(t *testing.T) {
+	cases := []struct {
+		name           string
+		user           *web.User
+		expectedStatus int
+	}{
+		{
+			name:           "session",
+			user:           &web.User{ID: "myuser"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "personal access token",
+			user:           &web.User{ID: "myuser", Bearer: true},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "no user",
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			authMiddleware := web.NewAuthMiddleware(auth.Config{}, auth.New(auth.Config{}, auth.NewProviders()))
+			app := fiber.New(fiber.Config{ErrorHandler: web.RespondWithProblemJSON})
+			app.Get("/test", func(c *fiber.Ctx) error {
+				if tc.user != nil {
+					c.Locals(web.UserContextKey, *tc.user)
+				}
+
+				return c.Next()
+			}, authMiddleware.RequireSession(), func(c *fiber.Ctx) error {
+				return c.SendString("OK")
+			})
+			req := test.NewRequest(
+				test.WithMethod(http.MethodGet),
+				test.WithURL("/test"),
+			)
+
+			resp, err := app.Test(req)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+		})
+	}
+}
+
+func TestNewClientUser(t *testing.T) {
+	cases := []struct {
+		name     string
+		user     web.User
+		expected *web.ClientUser
+	}{
+		{
+			name:     "initials of username",
+			user:     web.User{ID: "myuser", Username: "myUser"},
+			expected: &web.ClientUser{Username: "myUser", Initials: "my"},
+		},
+		{
+			name:     "single character username",
+			user:     web.User{ID: "myuser", Username: "m"},
+			expected: &web.ClientUser{Username: "m", Initials: "m"},
+		},
+		{
+			name:     "multibyte username",
+			user:     web.User{ID: "myuser", Username: "Ö"},
+			expected: &web.ClientUser{Username: "Ö", Initials: "Ö"},
+		},
+		{
+			name:     "unknown username",
+			user:     web.User{ID: "myuser"},
+			expected: &web.ClientUser{Username: "Unknown", Initials: "Un"},
+		},
+		{
+			name: "no user",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, web.NewClientUser(tc.user))
+		})
+	}
+}
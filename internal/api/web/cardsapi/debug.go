@@ -0,0 +1,166 @@
+package cardsapi
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/konstantinfoerster/card-service-go/internal/aerrors"
+	"github.com/konstantinfoerster/card-service-go/internal/api/web"
+	"github.com/konstantinfoerster/card-service-go/internal/auth"
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+)
+
+// requireAdminForDebug the debug mode of the detection exposes internals and is only available for admins.
+func requireAdminForDebug(authMiddleware web.AuthMiddleware) fiber.Handler {
+	requireAdmin := authMiddleware.RequireRole(auth.RoleAdmin)
+
+	return func(c *fiber.Ctx) error {
+		if c.QueryBool("debug") {
+			return requireAdmin(c)
+		}
+
+		return c.Next()
+	}
+}
+
+// detectDebug returns the intermediate images and the hashes and scores of every candidate as json,
+// with the query parameter format=zip as zip archive with one png file per image.
+func detectDebug(c *fiber.Ctx, svc DetectService, collector cards.Collector, in io.Reader) error {
+	result, err := svc.Debug(c.Context(), collector, in)
+	if err != nil {
+		return err
+	}
+
+	if c.Query("format") == "zip" {
+		archive, err := newDebugArchive(result)
+		if err != nil {
+			return aerrors.NewUnknownError(err, "debug-archive-failed")
+		}
+		c.Set(fiber.HeaderContentType, "application/zip")
+		c.Attachment("detection-debug.zip")
+
+		return c.Send(archive)
+	}
+
+	return web.RenderJSON(c, newDebugResult(result, true))
+}
+
+// newDebugArchive writes every stage as stages/<index>-<name>.png and the candidates as candidates.json.
+func newDebugArchive(r cards.DebugResult) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for i, s := range r.Stages {
+		w, err := zw.Create(fmt.Sprintf("stages/%02d-%s.png", i, s.Name))
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(s.PNG); err != nil {
+			return nil, err
+		}
+	}
+
+	w, err := zw.Create("candidates.json")
+	if err != nil {
+		return nil, err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(newDebugResult(r, false).Candidates); err != nil {
+		return nil, err
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func newDebugResult(r cards.DebugResult, withStages bool) DebugResult {
+	stages := make([]DebugStage, 0, len(r.Stages))
+	if withStages {
+		for _, s := range r.Stages {
+			stages = append(stages, DebugStage{
+				Name:  s.Name,
+				Image: "data:image/png;base64," + base64.StdEncoding.EncodeToString(s.PNG),
+			})
+		}
+	}
+
+	candidates := make([]DebugCandidate, len(r.Candidates))
+	for i, dc := range r.Candidates {
+		hashes := make([]DebugHash, len(dc.Hashes))
+		for j, h := range dc.Hashes {
+			values := make([]string, len(h.Value))
+			for k, v := range h.Value {
+				values[k] = fmt.Sprintf("%016x", v)
+			}
+			hashes[j] = DebugHash{Algorithm: string(h.Algorithm), Value: strings.Join(values, "")}
+		}
+		scores := make([]DebugScore, len(dc.Scores))
+		for j, s := range dc.Scores {
+			scores[j] = DebugScore{ID: asClientID(s.ID), Score: s.Score}
+		}
+		matches := make([]Card, len(dc.Matches.Result))
+		for j, m := range dc.Matches.Result {
+			matches[j] = newCard(m.Card).WithConfidence(m.Confidence)
+		}
+
+		candidates[i] = DebugCandidate{
+			Box: Box{
+				X:      dc.Box.X,
+				Y:      dc.Box.Y,
+				Width:  dc.Box.Width,
+				Height: dc.Box.Height,
+			},
+			Hashes:      hashes,
+			Scores:      scores,
+			Orientation: int(dc.Orientation),
+			Matches:     matches,
+		}
+	}
+
+	return DebugResult{Stages: stages, Candidates: candidates}
+}
+
+// DebugResult the intermediate images of the detection and the details of every candidate.
+type DebugResult struct {
+	Stages     []DebugStage     `json:"stages"`
+	Candidates []DebugCandidate `json:"candidates"`
+}
+
+type DebugStage struct {
+	Name string `json:"name"`
+	// Image the png image as data URL.
+	Image string `json:"image"`
+}
+
+type DebugCandidate struct {
+	// Box is the position of the candidate inside the uploaded image.
+	Box Box `json:"box"`
+	// Hashes the hash of every orientation for every algorithm.
+	Hashes []DebugHash `json:"hashes"`
+	// Scores the best scores of all orientations, lower is better.
+	Scores []DebugScore `json:"scores"`
+	// Orientation the clockwise rotation in degree of the best score.
+	Orientation int `json:"orientation"`
+	// Matches are the best matching cards after the re-ranking, best match first.
+	Matches []Card `json:"matches"`
+}
+
+type DebugHash struct {
+	Algorithm string `json:"algorithm"`
+	// Value the hex encoded hash.
+	Value string `json:"value"`
+}
+
+type DebugScore struct {
+	ID    string `json:"id"`
+	Score int    `json:"score"`
+}
@@ -2,53 +2,239 @@ package cardsapi
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"html/template"
 	"io"
+	"mime/multipart"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/konstantinfoerster/card-service-go/internal/aerrors"
 	"github.com/konstantinfoerster/card-service-go/internal/aio"
 	"github.com/konstantinfoerster/card-service-go/internal/api/web"
 	"github.com/konstantinfoerster/card-service-go/internal/cards"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/imaging"
 )
 
 type DetectService interface {
-	Detect(ctx context.Context, collector cards.Collector, in io.Reader) (cards.Matches, error)
+	Detect(ctx context.Context, collector cards.Collector, in io.Reader) (cards.DetectionResult, error)
+	DetectAndCollect(ctx context.Context, collector cards.Collector, in io.Reader) (cards.CollectResult, error)
+	Debug(ctx context.Context, collector cards.Collector, in io.Reader) (cards.DebugResult, error)
 }
 
-func DetectRoutes(r fiber.Router, auth web.AuthMiddleware, detectSvc DetectService) {
-	r.Post("/detect", auth.Relaxed(), Detect(detectSvc))
+func DetectRoutes(r fiber.Router, auth web.AuthMiddleware, cfg web.Upload, detectSvc DetectService) {
+	r.Post("/detect", auth.Relaxed(), requireAdminForDebug(auth), Detect(cfg, detectSvc))
+	r.Get("/detect/stream", auth.Relaxed(), DetectStream(cfg, detectSvc))
+	r.Get("/scan", auth.Relaxed(), scanPage())
 }
 
-func Detect(svc DetectService) fiber.Handler {
+// scanPage shows the camera preview that streams frames to the detection.
+func scanPage() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		// when user is not set, the user specific collection data won't be loaded
-		user, _ := web.UserFromCtx(c)
+		if web.IsHTMX(c) {
+			return web.RenderPartial(c, "scan", nil)
+		}
 
-		fHeader, err := c.FormFile("file")
-		if err != nil {
-			return aerrors.NewInvalidInputError(err, "invalid-file", "failed to read file from form")
+		return web.RenderPage(c, "scan", nil)
+	}
+}
+
+// Detect searches the cards shown in the uploaded image. With the query parameter collect=true the best
+// matches are added to the collection of the user, that requires an authenticated user. With debug=true
+// the intermediate images of the detection are returned, that requires an admin.
+func Detect(cfg web.Upload, svc DetectService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// when user is not set, the user specific collection data won't be loaded
+		user, uErr := web.UserFromCtx(c)
+		collect := c.QueryBool("collect")
+		if collect && uErr != nil {
+			return aerrors.NewAuthorizationError(uErr, "unauthorized")
 		}
 
-		file, err := fHeader.Open()
+		file, err := openUpload(c, cfg)
 		if err != nil {
-			return aerrors.NewInvalidInputError(err, "invalid-file", "failed to open file")
+			return err
 		}
 		defer aio.Close(file)
 
+		if c.QueryBool("debug") {
+			return detectDebug(c, svc, asCollector(user), file)
+		}
+
+		if collect {
+			return detectAndCollect(c, svc, asCollector(user), file)
+		}
+
 		result, err := svc.Detect(c.Context(), asCollector(user), file)
 		if err != nil {
 			return err
 		}
 
-		pagedResult := newPagedResponse(result.PagedResult)
+		detection := newDetectionResult(result)
 		if web.AcceptsHTML(c) || web.IsHTMX(c) {
 			data := fiber.Map{
-				"Page": pagedResult,
+				"Detection": detection,
 			}
 
-			return web.RenderPartial(c, "search", data)
+			return web.RenderPartial(c, "detect", data)
+		}
+
+		return web.RenderJSON(c, detection)
+	}
+}
+
+// openUpload opens the uploaded image of the form field file, after its size and dimensions are checked.
+func openUpload(c *fiber.Ctx, cfg web.Upload) (multipart.File, error) {
+	fHeader, err := c.FormFile("file")
+	if err != nil {
+		return nil, aerrors.NewInvalidInputError(err, "invalid-file", "failed to read file from form")
+	}
+
+	if fHeader.Size > cfg.MaxFileSize() {
+		return nil, aerrors.NewTooLargeError(ErrFileTooLarge, "file-too-large",
+			fmt.Sprintf("file must not be larger than %d bytes", cfg.MaxFileSize()))
+	}
+
+	file, err := fHeader.Open()
+	if err != nil {
+		return nil, aerrors.NewInvalidInputError(err, "invalid-file", "failed to open file")
+	}
+
+	if err := checkDimensions(cfg, file); err != nil {
+		aio.Close(file)
+
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// checkDimensions reads only the header of the image, that prevents decoding images that would need
+// too much memory. The reader is reset to the start afterwards.
+func checkDimensions(cfg web.Upload, file io.ReadSeeker) error {
+	imgCfg, err := imaging.DecodeConfig(file)
+	if err != nil {
+		if errors.Is(err, cards.ErrInvalidImage) {
+			return aerrors.NewInvalidInputError(err, "invalid-file",
+				"unsupported image, supported formats are JPEG, PNG, GIF and WebP")
 		}
 
-		return web.RenderJSON(c, pagedResult)
+		return aerrors.NewInvalidInputError(err, "invalid-file", "failed to read file")
+	}
+
+	maxWidth, maxHeight := cfg.MaxDimensions()
+	if imgCfg.Width > maxWidth || imgCfg.Height > maxHeight {
+		return aerrors.NewTooLargeError(ErrImageTooLarge, "image-too-large",
+			fmt.Sprintf("image must not be larger than %dx%d pixel", maxWidth, maxHeight))
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return aerrors.NewUnknownError(err, "unable-to-read-file")
 	}
+
+	return nil
+}
+
+func detectAndCollect(c *fiber.Ctx, svc DetectService, collector cards.Collector, in io.Reader) error {
+	result, err := svc.DetectAndCollect(c.Context(), collector, in)
+	if err != nil {
+		return err
+	}
+
+	collected := newCollectResult(result)
+	if web.AcceptsHTML(c) || web.IsHTMX(c) {
+		data := fiber.Map{
+			"Detection": DetectionResult{
+				Regions: append(collected.Added, collected.Pending...),
+			},
+			"Collect": true,
+		}
+
+		return web.RenderPartial(c, "detect", data)
+	}
+
+	return web.RenderJSON(c, collected)
+}
+
+func newDetectionResult(r cards.DetectionResult) DetectionResult {
+	regions := make([]DetectedRegion, len(r.Regions))
+	for i, region := range r.Regions {
+		regions[i] = newDetectedRegion(region, false)
+	}
+
+	return DetectionResult{Regions: regions}
+}
+
+func newCollectResult(r cards.CollectResult) CollectResult {
+	added := make([]DetectedRegion, len(r.Added))
+	for i, region := range r.Added {
+		added[i] = newDetectedRegion(region, true)
+	}
+	pending := make([]DetectedRegion, len(r.Pending))
+	for i, region := range r.Pending {
+		pending[i] = newDetectedRegion(region, false)
+	}
+
+	return CollectResult{
+		Added:   added,
+		Pending: pending,
+	}
+}
+
+func newDetectedRegion(region cards.DetectedRegion, collected bool) DetectedRegion {
+	matches := make([]Card, len(region.Matches.Result))
+	for i, m := range region.Matches.Result {
+		matches[i] = newCard(m.Card).WithConfidence(m.Confidence)
+	}
+
+	return DetectedRegion{
+		Box: Box{
+			X:      region.Box.X,
+			Y:      region.Box.Y,
+			Width:  region.Box.Width,
+			Height: region.Box.Height,
+		},
+		Matches:     matches,
+		Orientation: int(region.Orientation),
+		Collected:   collected,
+	}
+}
+
+// DetectionResult contains one entry per card found in the uploaded image.
+type DetectionResult struct {
+	Regions []DetectedRegion `json:"regions"`
+}
+
+type DetectedRegion struct {
+	// Box is the position of the card inside the uploaded image.
+	Box Box `json:"box"`
+	// Matches are the best matching cards, best match first.
+	Matches []Card `json:"matches"`
+	// Orientation the clockwise rotation in degree that turns the card upright, 0, 90, 180 or 270.
+	Orientation int `json:"orientation"`
+	// Collected is true if the first match was added to the collection.
+	Collected bool `json:"collected,omitempty"`
+}
+
+// CollectResult the detected regions split by whether the best match was added to the collection.
+type CollectResult struct {
+	// Added contains the regions whose best match was added, the amount of the match is the new amount.
+	Added []DetectedRegion `json:"added"`
+	// Pending contains the regions that need a manual confirmation.
+	Pending []DetectedRegion `json:"pending"`
+}
+
+// Box all values are relative to the image size, between 0 and 1.
+type Box struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// Style positions an element with the box on top of the image.
+func (b Box) Style() template.CSS {
+	//nolint:gosec // only contains formatted numbers
+	return template.CSS(fmt.Sprintf("left: %.2f%%; top: %.2f%%; width: %.2f%%; height: %.2f%%;",
+		b.X*100, b.Y*100, b.Width*100, b.Height*100))
 }
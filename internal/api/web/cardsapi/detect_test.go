@@ -1,9 +1,13 @@
 package cardsapi_test
 
 import (
+	"archive/zip"
+	"bytes"
+	"io"
 	"os"
 	"path"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/gofiber/fiber/v2"
@@ -14,7 +18,7 @@ import (
 	"github.com/konstantinfoerster/card-service-go/internal/cards"
 	"github.com/konstantinfoerster/card-service-go/internal/cards/imaging"
 	"github.com/konstantinfoerster/card-service-go/internal/cards/memory"
-	"github.com/konstantinfoerster/card-service-go/internal/cards/postgres"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/storage"
 	"github.com/konstantinfoerster/card-service-go/internal/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -89,21 +93,345 @@ func TestDetect(t *testing.T) {
 
 			require.NoError(t, err)
 			require.Equal(t, web.StatusOK, resp.StatusCode)
-			body := test.FromJSON[cardsapi.PagedResponse[cardsapi.Card]](t, resp.Body)
-			assert.False(t, body.HasMore)
-			assert.Equal(t, 1, body.Page)
-			assert.ElementsMatch(t, tc.expected, body.Data)
+			body := test.FromJSON[cardsapi.DetectionResult](t, resp.Body)
+			require.Len(t, body.Regions, 1)
+			assert.Equal(t, cardsapi.Box{Width: 1, Height: 1}, body.Regions[0].Box)
+			assert.ElementsMatch(t, tc.expected, body.Regions[0].Matches)
+		})
+	}
+}
+
+func TestDetectAsHTMX(t *testing.T) {
+	srv, _ := detectTestServer(t)
+	fImg, err := os.Open(path.Join(currentDir(), "testdata", "cardImageModified.jpg"))
+	defer aio.Close(fImg)
+	require.NoError(t, err)
+	req := test.NewRequest(
+		test.WithMethod(web.MethodPost),
+		test.WithURL("http://localhost/detect"),
+		test.WithHeader(map[string]string{
+			web.HeaderHTMXRequest: "true",
+		}),
+		test.WithMultipartFile(t, fImg, fImg.Name()),
+	)
+
+	resp, err := srv.Test(req)
+	defer test.Close(t, resp)
+
+	require.NoError(t, err)
+	require.Equal(t, web.StatusOK, resp.StatusCode)
+	assert.Equal(t, fiber.MIMETextHTMLCharsetUTF8, resp.Header.Get(fiber.HeaderContentType))
+	body := test.ToString(t, resp.Body)
+	test.AssertContainsPartialHTML(t, body)
+	assert.Contains(t, body, "data-testid=\"detect-result-txt\"")
+	assert.Contains(t, body, "data-testid=\"detect-region-0\"")
+	assert.Contains(t, body, "left: 0.00%; top: 0.00%; width: 100.00%; height: 100.00%;")
+	assert.Contains(t, body, "Ancestor&#39;s Chosen")
+}
+
+func TestDetectUnsupportedFile(t *testing.T) {
+	srv, _ := detectTestServer(t)
+	req := test.NewRequest(
+		test.WithMethod(web.MethodPost),
+		test.WithURL("http://localhost/detect"),
+		test.WithMultipartFile(t, strings.NewReader("not an image"), "card.txt"),
+	)
+
+	resp, err := srv.Test(req)
+	defer test.Close(t, resp)
+
+	require.NoError(t, err)
+	assert.Equal(t, web.StatusBadRequest, resp.StatusCode)
+	body := test.FromJSON[web.ProblemJSON](t, resp.Body)
+	assert.Equal(t, "invalid-file", body.Key)
+}
+
+func TestDetectUploadLimits(t *testing.T) {
+	cases := []struct {
+		name        string
+		upload      web.Upload
+		expectedKey string
+	}{
+		{
+			name:        "file too large",
+			upload:      web.Upload{MaxBytes: 1000},
+			expectedKey: "file-too-large",
+		},
+		{
+			name:        "image too wide",
+			upload:      web.Upload{MaxWidth: 100},
+			expectedKey: "image-too-large",
+		},
+		{
+			name:        "image too high",
+			upload:      web.Upload{MaxHeight: 100},
+			expectedKey: "image-too-large",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv, _ := detectTestServerWithLimits(t, tc.upload)
+			fImg, err := os.Open(path.Join(currentDir(), "testdata", "cardImageModified.jpg"))
+			defer aio.Close(fImg)
+			require.NoError(t, err)
+			req := test.NewRequest(
+				test.WithMethod(web.MethodPost),
+				test.WithURL("http://localhost/detect"),
+				test.WithMultipartFile(t, fImg, fImg.Name()),
+			)
+
+			resp, err := srv.Test(req)
+			defer test.Close(t, resp)
+
+			require.NoError(t, err)
+			assert.Equal(t, web.StatusRequestEntityTooLarge, resp.StatusCode)
+			body := test.FromJSON[web.ProblemJSON](t, resp.Body)
+			assert.Equal(t, tc.expectedKey, body.Key)
+		})
+	}
+}
+
+func TestDetectAndCollect(t *testing.T) {
+	four := 4
+	cases := []struct {
+		name            string
+		img             string
+		expectedAdded   []cardsapi.Card
+		expectedPending int
+	}{
+		{
+			name: "add best match",
+			img:  "cardImageModified.jpg",
+			expectedAdded: []cardsapi.Card{
+				{
+					ID:     "Y2FyZD0xJmZhY2U9MQ==",
+					Amount: 2,
+					Name:   "Ancestor's Chosen",
+					Image:  "cardImage.jpg",
+					Set: cardsapi.Set{
+						Code: "10E",
+						Name: "Tenth Edition",
+					},
+					Number:     "1",
+					Confidence: &four,
+				},
+			},
+		},
+		{
+			name:            "no match needs confirmation",
+			img:             "noscore.jpg",
+			expectedAdded:   []cardsapi.Card{},
+			expectedPending: 1,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv, provider := detectTestServer(t)
+			fImg, err := os.Open(path.Join(currentDir(), "testdata", tc.img))
+			defer aio.Close(fImg)
+			require.NoError(t, err)
+			req := test.NewRequest(
+				test.WithMethod(web.MethodPost),
+				test.WithURL("http://localhost/detect?collect=true"),
+				test.WithEncryptedCookie(t, "SESSION", test.Base64Encoded(t, provider.Token("myuser"))),
+				test.WithMultipartFile(t, fImg, fImg.Name()),
+			)
+
+			resp, err := srv.Test(req)
+			defer test.Close(t, resp)
+
+			require.NoError(t, err)
+			require.Equal(t, web.StatusOK, resp.StatusCode)
+			body := test.FromJSON[cardsapi.CollectResult](t, resp.Body)
+			added := make([]cardsapi.Card, 0)
+			for _, r := range body.Added {
+				assert.True(t, r.Collected)
+				added = append(added, r.Matches[0])
+			}
+			assert.Equal(t, tc.expectedAdded, added)
+			assert.Len(t, body.Pending, tc.expectedPending)
+		})
+	}
+}
+
+func TestDetectAndCollectAsHTMX(t *testing.T) {
+	srv, provider := detectTestServer(t)
+	fImg, err := os.Open(path.Join(currentDir(), "testdata", "cardImageModified.jpg"))
+	defer aio.Close(fImg)
+	require.NoError(t, err)
+	req := test.NewRequest(
+		test.WithMethod(web.MethodPost),
+		test.WithURL("http://localhost/detect?collect=true"),
+		test.WithHeader(map[string]string{
+			web.HeaderHTMXRequest: "true",
+		}),
+		test.WithEncryptedCookie(t, "SESSION", test.Base64Encoded(t, provider.Token("myuser"))),
+		test.WithMultipartFile(t, fImg, fImg.Name()),
+	)
+
+	resp, err := srv.Test(req)
+	defer test.Close(t, resp)
+
+	require.NoError(t, err)
+	require.Equal(t, web.StatusOK, resp.StatusCode)
+	body := test.ToString(t, resp.Body)
+	test.AssertContainsPartialHTML(t, body)
+	assert.Contains(t, body, "data-testid=\"detect-collected-0\"")
+	assert.Contains(t, body, "detect-region-collected")
+}
+
+func TestDetectAndCollectRequiresUser(t *testing.T) {
+	srv, _ := detectTestServer(t)
+	fImg, err := os.Open(path.Join(currentDir(), "testdata", "cardImageModified.jpg"))
+	defer aio.Close(fImg)
+	require.NoError(t, err)
+	req := test.NewRequest(
+		test.WithMethod(web.MethodPost),
+		test.WithURL("http://localhost/detect?collect=true"),
+		test.WithMultipartFile(t, fImg, fImg.Name()),
+	)
+
+	resp, err := srv.Test(req)
+	defer test.Close(t, resp)
+
+	require.NoError(t, err)
+	assert.Equal(t, web.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestDetectDebug(t *testing.T) {
+	srv, provider := detectTestServer(t)
+	fImg, err := os.Open(path.Join(currentDir(), "testdata", "cardImageModified.jpg"))
+	defer aio.Close(fImg)
+	require.NoError(t, err)
+	req := test.NewRequest(
+		test.WithMethod(web.MethodPost),
+		test.WithURL("http://localhost/detect?debug=true"),
+		test.WithEncryptedCookie(t, "SESSION", test.Base64Encoded(t, provider.Token("myadmin"))),
+		test.WithMultipartFile(t, fImg, fImg.Name()),
+	)
+
+	resp, err := srv.Test(req)
+	defer test.Close(t, resp)
+
+	require.NoError(t, err)
+	require.Equal(t, web.StatusOK, resp.StatusCode)
+	body := test.FromJSON[cardsapi.DebugResult](t, resp.Body)
+	require.Len(t, body.Stages, 1)
+	assert.Equal(t, "input", body.Stages[0].Name)
+	assert.True(t, strings.HasPrefix(body.Stages[0].Image, "data:image/png;base64,"))
+	require.Len(t, body.Candidates, 1)
+	candidate := body.Candidates[0]
+	// one hash per orientation
+	require.Len(t, candidate.Hashes, 4)
+	assert.Equal(t, "phash", candidate.Hashes[0].Algorithm)
+	assert.Len(t, candidate.Hashes[0].Value, 64)
+	assert.NotEmpty(t, candidate.Scores)
+	require.NotEmpty(t, candidate.Matches)
+	assert.Equal(t, "Ancestor's Chosen", candidate.Matches[0].Name)
+}
+
+func TestDetectDebugAsZip(t *testing.T) {
+	srv, provider := detectTestServer(t)
+	fImg, err := os.Open(path.Join(currentDir(), "testdata", "cardImageModified.jpg"))
+	defer aio.Close(fImg)
+	require.NoError(t, err)
+	req := test.NewRequest(
+		test.WithMethod(web.MethodPost),
+		test.WithURL("http://localhost/detect?debug=true&format=zip"),
+		test.WithEncryptedCookie(t, "SESSION", test.Base64Encoded(t, provider.Token("myadmin"))),
+		test.WithMultipartFile(t, fImg, fImg.Name()),
+	)
+
+	resp, err := srv.Test(req)
+	defer test.Close(t, resp)
+
+	require.NoError(t, err)
+	require.Equal(t, web.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/zip", resp.Header.Get(fiber.HeaderContentType))
+	content, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	require.NoError(t, err)
+	names := make([]string, 0, len(archive.File))
+	for _, f := range archive.File {
+		names = append(names, f.Name)
+	}
+	assert.Equal(t, []string{"stages/00-input.png", "candidates.json"}, names)
+}
+
+func TestDetectDebugRequiresAdmin(t *testing.T) {
+	cases := []struct {
+		name           string
+		user           string
+		expectedStatus int
+	}{
+		{
+			name:           "no user",
+			expectedStatus: web.StatusUnauthorized,
+		},
+		{
+			name:           "no admin",
+			user:           "myuser",
+			expectedStatus: web.StatusForbidden,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv, provider := detectTestServer(t)
+			fImg, err := os.Open(path.Join(currentDir(), "testdata", "cardImageModified.jpg"))
+			defer aio.Close(fImg)
+			require.NoError(t, err)
+			opts := []test.RequestOpt{
+				test.WithMethod(web.MethodPost),
+				test.WithURL("http://localhost/detect?debug=true"),
+				test.WithMultipartFile(t, fImg, fImg.Name()),
+			}
+			if tc.user != "" {
+				opts = append(opts,
+					test.WithEncryptedCookie(t, "SESSION", test.Base64Encoded(t, provider.Token(tc.user))))
+			}
+			req := test.NewRequest(opts...)
+
+			resp, err := srv.Test(req)
+			defer test.Close(t, resp)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
 		})
 	}
 }
 
 func detectTestServer(t *testing.T) (*web.Server, *auth.FakeProvider) {
+	return detectTestServerWithLimits(t, web.Upload{})
+}
+
+func detectTestServerWithLimits(t *testing.T, upload web.Upload) (*web.Server, *auth.FakeProvider) {
 	srv := web.NewTestServer()
+	authMiddleware, provider := detectTestAuth()
+	svc := detectTestService(t)
+	srv.RegisterRoutes(func(r fiber.Router) {
+		cardsapi.DetectRoutes(r.Group("/"), authMiddleware, upload, svc)
+	})
+
+	return srv, provider
+}
+
+func detectTestAuth() (web.AuthMiddleware, *auth.FakeProvider) {
+	oCfg := auth.Config{}
+	validClaim := auth.NewClaims("myuser", "myUser")
+	adminClaim := auth.NewClaims("myadmin", "myAdmin")
+	adminClaim.Roles = []auth.Role{auth.RoleUser, auth.RoleAdmin}
+	provider := auth.NewFakeProvider(auth.WithClaims(validClaim), auth.WithClaims(adminClaim))
+	authSvc := auth.New(oCfg, auth.NewProviders(provider))
+
+	return web.NewAuthMiddleware(oCfg, authSvc), provider
+}
 
-	cfg := postgres.Images{Host: "testdata"}
+func detectTestService(t *testing.T) *cards.DetectService {
+	dCfg := cards.DetectConfig{CollectMaxScore: 5}
 	seed, err := test.CardSeed()
 	require.NoError(t, err)
-	dRepo, err := memory.NewDetectRepository(seed, cfg)
+	dRepo, err := memory.NewDetectRepository(seed, storage.NewLocalStore("testdata"), dCfg)
 	require.NoError(t, err)
 	item, err := cards.NewCollectable(cards.NewID(1), 1)
 	require.NoError(t, err)
@@ -113,17 +441,9 @@ func detectTestServer(t *testing.T) (*web.Server, *auth.FakeProvider) {
 	cRepo, err := memory.NewCardRepository(seed, collected)
 	require.NoError(t, err)
 
-	oCfg := auth.Config{}
-	validClaim := auth.NewClaims("myuser", "myUser")
-	provider := auth.NewFakeProvider(auth.WithClaims(validClaim))
-	authSvc := auth.New(oCfg, auth.NewProviders(provider))
 	detector := imaging.NewFakeDetector()
-	svc := cards.NewDetectService(cRepo, dRepo, detector)
-	srv.RegisterRoutes(func(r fiber.Router) {
-		cardsapi.DetectRoutes(r.Group("/"), web.NewAuthMiddleware(oCfg, authSvc), svc)
-	})
 
-	return srv, provider
+	return cards.NewDetectService(dCfg, cRepo, dRepo, cRepo, detector)
 }
 
 func currentDir() string {
@@ -0,0 +1,81 @@
+package cardsapi
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/konstantinfoerster/card-service-go/internal/aerrors"
+	"github.com/konstantinfoerster/card-service-go/internal/api/web"
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+)
+
+const (
+	// imageCacheControl card images never change under the same ETag, clients keep them for a year.
+	imageCacheControl = "public, max-age=31536000"
+	// imageShortCacheControl images without an ETag cannot be revalidated, clients keep them for an hour only.
+	imageShortCacheControl = "public, max-age=3600"
+)
+
+type ImageService interface {
+	Image(ctx context.Context, id int, size cards.ImageSize) (cards.ImageFile, error)
+	Stat(ctx context.Context, id int, size cards.ImageSize) (cards.ImageFile, error)
+}
+
+func ImageRoutes(r fiber.Router, svc ImageService) {
+	r.Get("/images/:id", Image(svc))
+}
+
+// Image serves the card image with the given id. The query parameter size=list or size=detail returns
+// a thumbnail instead of the original image. The image is only opened if the ETag of the client does
+// not match.
+func Image(svc ImageService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil || id <= 0 {
+			return aerrors.NewInvalidInputMsg("invalid-id", "invalid id")
+		}
+		size, err := cards.ParseImageSize(c.Query("size"))
+		if err != nil {
+			return aerrors.NewInvalidInputError(err, "invalid-size", "size must be list or detail")
+		}
+
+		info, err := svc.Stat(c.Context(), id, size)
+		if err != nil {
+			return err
+		}
+		if etag := info.ETag(); etag != "" && matchesETag(c.Get(fiber.HeaderIfNoneMatch), etag) {
+			c.Set(fiber.HeaderETag, etag)
+			c.Set(fiber.HeaderCacheControl, imageCacheControl)
+
+			return c.SendStatus(web.StatusNotModified)
+		}
+
+		file, err := svc.Image(c.Context(), id, size)
+		if err != nil {
+			return err
+		}
+
+		c.Set(fiber.HeaderCacheControl, imageShortCacheControl)
+		if etag := file.ETag(); etag != "" {
+			c.Set(fiber.HeaderETag, etag)
+			c.Set(fiber.HeaderCacheControl, imageCacheControl)
+		}
+		c.Set(fiber.HeaderContentType, file.ContentType)
+
+		// the stream is closed after the response is written
+		return c.SendStream(file.Content, int(file.Size))
+	}
+}
+
+// matchesETag returns true if the If-None-Match header contains the ETag or is a wildcard.
+func matchesETag(header string, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,216 @@
+package cardsapi_test
+
+import (
+	"context"
+	"image/jpeg"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/konstantinfoerster/card-service-go/internal/api/web"
+	"github.com/konstantinfoerster/card-service-go/internal/api/web/cardsapi"
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/imaging"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/memory"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/storage"
+	"github.com/konstantinfoerster/card-service-go/internal/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImage(t *testing.T) {
+	cases := []struct {
+		name          string
+		query         string
+		expectedWidth int
+	}{
+		{
+			name:          "original",
+			query:         "",
+			expectedWidth: 360,
+		},
+		{
+			name:          "list thumbnail",
+			query:         "?size=list",
+			expectedWidth: cards.ListSize.Width(),
+		},
+		{
+			name:          "detail thumbnail is never wider than the original",
+			query:         "?size=detail",
+			expectedWidth: 360,
+		},
+	}
+	srv := imageTestServer(t)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := test.NewRequest(
+				test.WithMethod(web.MethodGet),
+				test.WithURL("http://localhost/images/1"+tc.query),
+			)
+
+			resp, err := srv.Test(req)
+			defer test.Close(t, resp)
+
+			require.NoError(t, err)
+			assert.Equal(t, web.StatusOK, resp.StatusCode)
+			assert.Equal(t, "image/jpeg", resp.Header.Get(fiber.HeaderContentType))
+			assert.NotEmpty(t, resp.Header.Get(fiber.HeaderETag))
+			assert.Contains(t, resp.Header.Get(fiber.HeaderCacheControl), "max-age=31536000")
+			cfg, err := jpeg.DecodeConfig(resp.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedWidth, cfg.Width)
+		})
+	}
+}
+
+func TestImageNotModified(t *testing.T) {
+	srv := imageTestServer(t)
+	req := test.NewRequest(
+		test.WithMethod(web.MethodGet),
+		test.WithURL("http://localhost/images/1?size=list"),
+	)
+	resp, err := srv.Test(req)
+	require.NoError(t, err)
+	test.Close(t, resp)
+	etag := resp.Header.Get(fiber.HeaderETag)
+	require.NotEmpty(t, etag)
+
+	req = test.NewRequest(
+		test.WithMethod(web.MethodGet),
+		test.WithURL("http://localhost/images/1?size=list"),
+		test.WithHeader(map[string]string{fiber.HeaderIfNoneMatch: etag}),
+	)
+	resp, err = srv.Test(req)
+	defer test.Close(t, resp)
+
+	require.NoError(t, err)
+	assert.Equal(t, web.StatusNotModified, resp.StatusCode)
+	assert.Equal(t, etag, resp.Header.Get(fiber.HeaderETag))
+}
+
+func TestImageNotModifiedDoesNotOpenImage(t *testing.T) {
+	svc := &fakeImageService{file: cards.ImageFile{Size: 4, ModTime: time.Unix(1700000000, 0)}}
+	srv := web.NewTestServer()
+	srv.RegisterRoutes(func(r fiber.Router) {
+		cardsapi.ImageRoutes(r.Group("/"), svc)
+	})
+	req := test.NewRequest(
+		test.WithMethod(web.MethodGet),
+		test.WithURL("http://localhost/images/1"),
+		test.WithHeader(map[string]string{fiber.HeaderIfNoneMatch: svc.file.ETag()}),
+	)
+
+	resp, err := srv.Test(req)
+	defer test.Close(t, resp)
+
+	require.NoError(t, err)
+	assert.Equal(t, web.StatusNotModified, resp.StatusCode)
+	assert.Equal(t, 0, svc.opened)
+}
+
+func TestImageWithoutETag(t *testing.T) {
+	svc := &fakeImageService{file: cards.ImageFile{ContentType: "image/jpeg", Size: -1}}
+	srv := web.NewTestServer()
+	srv.RegisterRoutes(func(r fiber.Router) {
+		cardsapi.ImageRoutes(r.Group("/"), svc)
+	})
+	req := test.NewRequest(
+		test.WithMethod(web.MethodGet),
+		test.WithURL("http://localhost/images/1"),
+		test.WithHeader(map[string]string{fiber.HeaderIfNoneMatch: "*"}),
+	)
+
+	resp, err := srv.Test(req)
+	defer test.Close(t, resp)
+
+	require.NoError(t, err)
+	assert.Equal(t, web.StatusOK, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get(fiber.HeaderETag))
+	assert.Equal(t, "public, max-age=3600", resp.Header.Get(fiber.HeaderCacheControl))
+	assert.Equal(t, 1, svc.opened)
+}
+
+func TestImageInvalidRequest(t *testing.T) {
+	cases := []struct {
+		name           string
+		url            string
+		expectedStatus int
+		expectedKey    string
+	}{
+		{
+			name:           "invalid id",
+			url:            "http://localhost/images/abc",
+			expectedStatus: web.StatusBadRequest,
+			expectedKey:    "invalid-id",
+		},
+		{
+			name:           "unknown size",
+			url:            "http://localhost/images/1?size=huge",
+			expectedStatus: web.StatusBadRequest,
+			expectedKey:    "invalid-size",
+		},
+		{
+			name:           "unknown image",
+			url:            "http://localhost/images/99",
+			expectedStatus: web.StatusNotFound,
+			expectedKey:    "image-not-found",
+		},
+		{
+			name:           "missing file",
+			url:            "http://localhost/images/2",
+			expectedStatus: web.StatusNotFound,
+			expectedKey:    "image-not-found",
+		},
+	}
+	srv := imageTestServer(t)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := test.NewRequest(
+				test.WithMethod(web.MethodGet),
+				test.WithURL(tc.url),
+			)
+
+			resp, err := srv.Test(req)
+			defer test.Close(t, resp)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+			body := test.FromJSON[web.ProblemJSON](t, resp.Body)
+			assert.Equal(t, tc.expectedKey, body.Key)
+		})
+	}
+}
+
+func imageTestServer(t *testing.T) *web.Server {
+	srv := web.NewTestServer()
+	repo := memory.NewImageRepository([]cards.CardImage{
+		{ID: 1, Path: "cardImage.jpg"},
+		{ID: 2, Path: "missing.jpg"},
+	})
+	svc := cards.NewImageService(repo, imaging.NewThumbnails(storage.NewLocalStore("testdata"), storage.NewLocalStore(t.TempDir())))
+	srv.RegisterRoutes(func(r fiber.Router) {
+		cardsapi.ImageRoutes(r.Group("/"), svc)
+	})
+
+	return srv
+}
+
+// fakeImageService returns the file for every image and counts the opened images.
+type fakeImageService struct {
+	file   cards.ImageFile
+	opened int
+}
+
+func (s *fakeImageService) Image(_ context.Context, _ int, _ cards.ImageSize) (cards.ImageFile, error) {
+	s.opened++
+	f := s.file
+	f.Content = io.NopCloser(strings.NewReader("card"))
+
+	return f, nil
+}
+
+func (s *fakeImageService) Stat(_ context.Context, _ int, _ cards.ImageSize) (cards.ImageFile, error) {
+	return s.file, nil
+}
@@ -0,0 +1,133 @@
+package cardsapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/konstantinfoerster/card-service-go/internal/aerrors"
+	"github.com/konstantinfoerster/card-service-go/internal/aio"
+	"github.com/konstantinfoerster/card-service-go/internal/api/web"
+	"github.com/konstantinfoerster/card-service-go/internal/jobs"
+)
+
+type JobQueue interface {
+	Submit(ctx context.Context, owner string, fn jobs.Func) (jobs.Job, error)
+	Get(ctx context.Context, owner string, id string) (jobs.Job, error)
+	Cancel(ctx context.Context, owner string, id string) (jobs.Job, error)
+}
+
+// DetectJobRoutes the detection as background job, the result is polled with the id of the job.
+func DetectJobRoutes(r fiber.Router, auth web.AuthMiddleware, cfg web.Upload, svc DetectService, queue JobQueue) {
+	r.Post("/detect/jobs", auth.Relaxed(), SubmitDetectJob(cfg, svc, queue))
+	r.Get("/detect/jobs/:id", auth.Relaxed(), GetDetectJob(queue))
+	r.Delete("/detect/jobs/:id", auth.Relaxed(), CancelDetectJob(queue))
+}
+
+// SubmitDetectJob queues the detection of the uploaded image and responds with 202 and the pending job.
+// With the query parameter collect=true the best matches are added to the collection of the user.
+func SubmitDetectJob(cfg web.Upload, svc DetectService, queue JobQueue) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// when user is not set, the user specific collection data won't be loaded
+		user, uErr := web.UserFromCtx(c)
+		collect := c.QueryBool("collect")
+		if collect && uErr != nil {
+			return aerrors.NewAuthorizationError(uErr, "unauthorized")
+		}
+
+		file, err := openUpload(c, cfg)
+		if err != nil {
+			return err
+		}
+		defer aio.Close(file)
+
+		// the request body is released after the response, the job needs its own copy
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return aerrors.NewInvalidInputError(err, "invalid-file", "failed to read file")
+		}
+
+		collector := asCollector(user)
+		job, err := queue.Submit(c.Context(), user.ID, func(ctx context.Context) (any, error) {
+			if collect {
+				result, err := svc.DetectAndCollect(ctx, collector, bytes.NewReader(data))
+				if err != nil {
+					return nil, err
+				}
+
+				return newCollectResult(result), nil
+			}
+
+			result, err := svc.Detect(ctx, collector, bytes.NewReader(data))
+			if err != nil {
+				return nil, err
+			}
+
+			return newDetectionResult(result), nil
+		})
+		if err != nil {
+			return err
+		}
+
+		c.Location(c.Path() + "/" + job.ID)
+		c.Status(web.StatusAccepted)
+
+		return web.RenderJSON(c, newJob(job))
+	}
+}
+
+// GetDetectJob returns the status of the job and its result once it is done.
+func GetDetectJob(queue JobQueue) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, _ := web.UserFromCtx(c)
+
+		job, err := queue.Get(c.Context(), user.ID, c.Params("id"))
+		if err != nil {
+			return err
+		}
+
+		return web.RenderJSON(c, newJob(job))
+	}
+}
+
+// CancelDetectJob stops the job if it is not finished yet.
+func CancelDetectJob(queue JobQueue) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, _ := web.UserFromCtx(c)
+
+		job, err := queue.Cancel(c.Context(), user.ID, c.Params("id"))
+		if err != nil {
+			return err
+		}
+
+		return web.RenderJSON(c, newJob(job))
+	}
+}
+
+func newJob(j jobs.Job) Job {
+	return Job{
+		ID:        j.ID,
+		Status:    string(j.Status),
+		Result:    json.RawMessage(j.Result),
+		Error:     j.ErrorKey,
+		Message:   j.ErrorMsg,
+		CreatedAt: j.CreatedAt,
+		UpdatedAt: j.UpdatedAt,
+	}
+}
+
+type Job struct {
+	ID string `json:"id"`
+	// Status is one of pending, running, done, failed or canceled.
+	Status string `json:"status"`
+	// Result the DetectionResult or with collect=true the CollectResult, only set for done jobs.
+	Result json.RawMessage `json:"result,omitempty"`
+	// Error the key of the error and a message that can be shown to the user, only set for failed jobs.
+	Error     string    `json:"error,omitempty"`
+	Message   string    `json:"message,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,177 @@
+package cardsapi_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/konstantinfoerster/card-service-go/internal/aio"
+	"github.com/konstantinfoerster/card-service-go/internal/api/web"
+	"github.com/konstantinfoerster/card-service-go/internal/api/web/cardsapi"
+	"github.com/konstantinfoerster/card-service-go/internal/auth"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/memory"
+	"github.com/konstantinfoerster/card-service-go/internal/jobs"
+	"github.com/konstantinfoerster/card-service-go/internal/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectJob(t *testing.T) {
+	srv, provider := detectJobTestServer(t)
+	cookie := test.WithEncryptedCookie(t, "SESSION", test.Base64Encoded(t, provider.Token("myuser")))
+	fImg, err := os.Open(path.Join(currentDir(), "testdata", "cardImageModified.jpg"))
+	defer aio.Close(fImg)
+	require.NoError(t, err)
+	req := test.NewRequest(
+		test.WithMethod(web.MethodPost),
+		test.WithURL("http://localhost/detect/jobs"),
+		cookie,
+		test.WithMultipartFile(t, fImg, fImg.Name()),
+	)
+
+	resp, err := srv.Test(req)
+	defer test.Close(t, resp)
+
+	require.NoError(t, err)
+	require.Equal(t, web.StatusAccepted, resp.StatusCode)
+	submitted := test.FromJSON[cardsapi.Job](t, resp.Body)
+	assert.Equal(t, "pending", submitted.Status)
+	assert.Equal(t, "/detect/jobs/"+submitted.ID, resp.Header.Get(fiber.HeaderLocation))
+
+	var job cardsapi.Job
+	require.Eventually(t, func() bool {
+		job = getDetectJob(t, srv, submitted.ID, cookie)
+
+		return job.Status == "done"
+	}, 5*time.Second, 20*time.Millisecond)
+	result := test.FromJSON[cardsapi.DetectionResult](t, bytes.NewReader(job.Result))
+	require.Len(t, result.Regions, 1)
+	require.NotEmpty(t, result.Regions[0].Matches)
+	assert.Equal(t, "Ancestor's Chosen", result.Regions[0].Matches[0].Name)
+}
+
+func TestDetectJobOfOtherUser(t *testing.T) {
+	srv, provider := detectJobTestServer(t)
+	fImg, err := os.Open(path.Join(currentDir(), "testdata", "cardImageModified.jpg"))
+	defer aio.Close(fImg)
+	require.NoError(t, err)
+	req := test.NewRequest(
+		test.WithMethod(web.MethodPost),
+		test.WithURL("http://localhost/detect/jobs"),
+		test.WithEncryptedCookie(t, "SESSION", test.Base64Encoded(t, provider.Token("myadmin"))),
+		test.WithMultipartFile(t, fImg, fImg.Name()),
+	)
+	resp, err := srv.Test(req)
+	defer test.Close(t, resp)
+	require.NoError(t, err)
+	require.Equal(t, web.StatusAccepted, resp.StatusCode)
+	submitted := test.FromJSON[cardsapi.Job](t, resp.Body)
+
+	for _, method := range []string{web.MethodGet, web.MethodDelete} {
+		t.Run(method, func(t *testing.T) {
+			req := test.NewRequest(
+				test.WithMethod(method),
+				test.WithURL("http://localhost/detect/jobs/"+submitted.ID),
+				test.WithEncryptedCookie(t, "SESSION", test.Base64Encoded(t, provider.Token("myuser"))),
+			)
+
+			resp, err := srv.Test(req)
+			defer test.Close(t, resp)
+
+			require.NoError(t, err)
+			assert.Equal(t, web.StatusNotFound, resp.StatusCode)
+			body := test.FromJSON[web.ProblemJSON](t, resp.Body)
+			assert.Equal(t, "job-not-found", body.Key)
+		})
+	}
+}
+
+func TestCancelDetectJob(t *testing.T) {
+	srv, _ := detectJobTestServer(t)
+	fImg, err := os.Open(path.Join(currentDir(), "testdata", "cardImageModified.jpg"))
+	defer aio.Close(fImg)
+	require.NoError(t, err)
+	req := test.NewRequest(
+		test.WithMethod(web.MethodPost),
+		test.WithURL("http://localhost/detect/jobs"),
+		test.WithMultipartFile(t, fImg, fImg.Name()),
+	)
+	resp, err := srv.Test(req)
+	defer test.Close(t, resp)
+	require.NoError(t, err)
+	require.Equal(t, web.StatusAccepted, resp.StatusCode)
+	submitted := test.FromJSON[cardsapi.Job](t, resp.Body)
+
+	req = test.NewRequest(
+		test.WithMethod(web.MethodDelete),
+		test.WithURL("http://localhost/detect/jobs/"+submitted.ID),
+	)
+	cResp, err := srv.Test(req)
+	defer test.Close(t, cResp)
+
+	require.NoError(t, err)
+	require.Equal(t, web.StatusOK, cResp.StatusCode)
+	job := test.FromJSON[cardsapi.Job](t, cResp.Body)
+	// the job may already be finished, finished jobs are not changed
+	assert.Contains(t, []string{"canceled", "done"}, job.Status)
+}
+
+func TestDetectJobRequiresUserToCollect(t *testing.T) {
+	srv, _ := detectJobTestServer(t)
+	fImg, err := os.Open(path.Join(currentDir(), "testdata", "cardImageModified.jpg"))
+	defer aio.Close(fImg)
+	require.NoError(t, err)
+	req := test.NewRequest(
+		test.WithMethod(web.MethodPost),
+		test.WithURL("http://localhost/detect/jobs?collect=true"),
+		test.WithMultipartFile(t, fImg, fImg.Name()),
+	)
+
+	resp, err := srv.Test(req)
+	defer test.Close(t, resp)
+
+	require.NoError(t, err)
+	assert.Equal(t, web.StatusUnauthorized, resp.StatusCode)
+}
+
+func detectJobTestServer(t *testing.T) (*web.Server, *auth.FakeProvider) {
+	srv := web.NewTestServer()
+	authMiddleware, provider := detectTestAuth()
+	svc := detectTestService(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	queue := jobs.NewQueue(jobs.Config{Workers: 1, QueueSize: 5}, memory.NewJobRepository())
+	done := make(chan error)
+	go func() {
+		done <- queue.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-done)
+	})
+
+	srv.RegisterRoutes(func(r fiber.Router) {
+		cardsapi.DetectJobRoutes(r.Group("/"), authMiddleware, web.Upload{}, svc, queue)
+	})
+
+	return srv, provider
+}
+
+func getDetectJob(t *testing.T, srv *web.Server, id string, opts ...test.RequestOpt) cardsapi.Job {
+	t.Helper()
+
+	req := test.NewRequest(append([]test.RequestOpt{
+		test.WithMethod(web.MethodGet),
+		test.WithURL("http://localhost/detect/jobs/" + id),
+	}, opts...)...)
+	resp, err := srv.Test(req)
+	defer test.Close(t, resp)
+	require.NoError(t, err)
+	require.Equal(t, web.StatusOK, resp.StatusCode)
+
+	return *test.FromJSON[cardsapi.Job](t, resp.Body)
+}
@@ -18,7 +18,11 @@ const (
 	faceIDKey = "face"
 )
 
-var ErrInvalidInput = errors.New("invalid unput")
+var (
+	ErrInvalidInput  = errors.New("invalid unput")
+	ErrFileTooLarge  = errors.New("file too large")
+	ErrImageTooLarge = errors.New("image too large")
+)
 
 func newPage(c *fiber.Ctx) cards.Page {
 	size, _ := strconv.Atoi(c.Query("size", ""))
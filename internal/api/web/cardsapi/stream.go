@@ -0,0 +1,246 @@
+package cardsapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/konstantinfoerster/card-service-go/internal/aerrors"
+	"github.com/konstantinfoerster/card-service-go/internal/api/web"
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/imaging"
+)
+
+// duplicateDistance frames with a smaller distance to the last processed frame show the same scene.
+const duplicateDistance = 4
+
+// closeTimeout how long to wait for the close message to be written.
+const closeTimeout = time.Second
+
+const (
+	EventMatch     = "match"
+	EventProcessed = "processed"
+	EventSkipped   = "skipped"
+	EventError     = "error"
+)
+
+const (
+	SkippedDuplicate = "duplicate"
+	SkippedBusy      = "busy"
+)
+
+// StreamEvent is pushed to the client for every received frame.
+type StreamEvent struct {
+	// Type is one of match, processed, skipped or error.
+	Type string `json:"type"`
+	// Frame the number of the frame, starting with 1.
+	Frame int `json:"frame"`
+	// Region a card whose best match was not part of an earlier frame, only set for match events.
+	Region *DetectedRegion `json:"region,omitempty"`
+	// Regions the number of cards found in the frame, only set for processed events.
+	Regions int `json:"regions,omitempty"`
+	// Reason why the frame was skipped, duplicate or busy.
+	Reason string `json:"reason,omitempty"`
+	// Error the key of the error and a message that can be shown to the user.
+	Error   string `json:"error,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// DetectStream accepts a stream of camera frames as binary messages. Only the newest frame is kept while
+// a frame is processed, frames that show the same scene as the last processed frame are skipped.
+// A match event is pushed for every card that was not found in an earlier frame.
+func DetectStream(cfg web.Upload, svc DetectService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !websocket.IsWebSocketUpgrade(c) {
+			return fiber.ErrUpgradeRequired
+		}
+
+		// when user is not set, the user specific collection data won't be loaded
+		user, _ := web.UserFromCtx(c)
+		collector := asCollector(user)
+
+		return websocket.New(func(conn *websocket.Conn) {
+			newScanSession(cfg, svc, collector, conn).run()
+		})(c)
+	}
+}
+
+type frame struct {
+	number int
+	data   []byte
+}
+
+type scanSession struct {
+	cfg       web.Upload
+	svc       DetectService
+	collector cards.Collector
+	conn      *websocket.Conn
+	writeMu   sync.Mutex
+	last      *imaging.FrameHash
+	reported  map[cards.ID]struct{}
+}
+
+func newScanSession(
+	cfg web.Upload, svc DetectService, collector cards.Collector, conn *websocket.Conn,
+) *scanSession {
+	return &scanSession{
+		cfg:       cfg,
+		svc:       svc,
+		collector: collector,
+		conn:      conn,
+		reported:  make(map[cards.ID]struct{}),
+	}
+}
+
+func (s *scanSession) run() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s.conn.SetReadLimit(s.cfg.MaxFileSize())
+
+	pending := make(chan frame, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		current := 0
+		// a panic closes the session with an error instead of stopping the process
+		defer func() {
+			if r := recover(); r != nil {
+				s.fail(current, r)
+			}
+		}()
+		for f := range pending {
+			current = f.number
+			s.process(ctx, f)
+		}
+	}()
+
+	number := 0
+	for {
+		msgType, data, err := s.conn.ReadMessage()
+		if err != nil {
+			break
+		}
+		number++
+		if msgType != websocket.BinaryMessage {
+			s.send(StreamEvent{Type: EventError, Frame: number, Error: "invalid-frame",
+				Message: "frames must be sent as binary messages"})
+
+			continue
+		}
+
+		f := frame{number: number, data: data}
+		select {
+		case pending <- f:
+		default:
+			// the previous frame is still processed, the waiting frame is outdated and replaced
+			select {
+			case old := <-pending:
+				s.send(StreamEvent{Type: EventSkipped, Frame: old.number, Reason: SkippedBusy})
+			default:
+			}
+			pending <- f
+		}
+	}
+
+	close(pending)
+	cancel()
+	<-done
+}
+
+func (s *scanSession) process(ctx context.Context, f frame) {
+	if err := checkDimensions(s.cfg, bytes.NewReader(f.data)); err != nil {
+		s.sendError(f.number, err)
+
+		return
+	}
+
+	hash, err := imaging.NewFrameHash(bytes.NewReader(f.data))
+	if err != nil {
+		s.sendError(f.number, aerrors.NewInvalidInputError(err, "invalid-file",
+			"unsupported image, supported formats are JPEG, PNG, GIF and WebP"))
+
+		return
+	}
+	if s.last != nil && s.last.Distance(hash) < duplicateDistance {
+		s.send(StreamEvent{Type: EventSkipped, Frame: f.number, Reason: SkippedDuplicate})
+
+		return
+	}
+	s.last = &hash
+
+	result, err := s.svc.Detect(ctx, s.collector, bytes.NewReader(f.data))
+	if err != nil {
+		s.sendError(f.number, err)
+
+		return
+	}
+
+	for _, r := range result.Regions {
+		best, ok := r.Best()
+		if !ok {
+			continue
+		}
+		if _, ok := s.reported[best.ID]; ok {
+			continue
+		}
+		s.reported[best.ID] = struct{}{}
+
+		region := newDetectedRegion(r, false)
+		s.send(StreamEvent{Type: EventMatch, Frame: f.number, Region: &region})
+	}
+
+	s.send(StreamEvent{Type: EventProcessed, Frame: f.number, Regions: len(result.Regions)})
+}
+
+func (s *scanSession) sendError(number int, err error) {
+	event := StreamEvent{Type: EventError, Frame: number, Error: "internal-error"}
+
+	var appErr aerrors.AppError
+	if errors.As(err, &appErr) {
+		event.Error = appErr.Key
+		event.Message = appErr.Msg
+	}
+	slog.Warn("stream frame error", slog.Int("frame", number), slog.Any("error", err))
+
+	s.send(event)
+}
+
+// send writes the event, the reader and the worker both send events and a connection allows only one writer.
+func (s *scanSession) send(event StreamEvent) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if err := s.conn.WriteJSON(event); err != nil {
+		slog.Debug("failed to send stream event", slog.Any("error", err))
+	}
+}
+
+// fail logs the panic of the frame, reports it to the client and closes the session.
+func (s *scanSession) fail(number int, r any) {
+	slog.Error("stream frame panicked", slog.Int("frame", number), slog.Any("panic", r),
+		slog.String("stack", string(debug.Stack())))
+	s.send(StreamEvent{Type: EventError, Frame: number, Error: "internal-error",
+		Message: "the frame could not be processed"})
+	s.close(websocket.CloseInternalServerErr, "frame processing failed")
+}
+
+// close sends the close message and closes the connection, that also stops the reader.
+func (s *scanSession) close(code int, reason string) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	msg := websocket.FormatCloseMessage(code, reason)
+	if err := s.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(closeTimeout)); err != nil {
+		slog.Debug("failed to send close message", slog.Any("error", err))
+	}
+	if err := s.conn.Close(); err != nil {
+		slog.Debug("failed to close stream", slog.Any("error", err))
+	}
+}
@@ -0,0 +1,183 @@
+package cardsapi_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/fasthttp/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/konstantinfoerster/card-service-go/internal/api/web"
+	"github.com/konstantinfoerster/card-service-go/internal/api/web/cardsapi"
+	"github.com/konstantinfoerster/card-service-go/internal/auth"
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/imaging"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/memory"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/storage"
+	"github.com/konstantinfoerster/card-service-go/internal/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectStream(t *testing.T) {
+	seed, err := test.CardSeed()
+	require.NoError(t, err)
+	dCfg := cards.DetectConfig{}
+	dRepo, err := memory.NewDetectRepository(seed, storage.NewLocalStore("testdata"), dCfg)
+	require.NoError(t, err)
+	cRepo, err := memory.NewCardRepository(seed, nil)
+	require.NoError(t, err)
+	svc := cards.NewDetectService(dCfg, cRepo, dRepo, cRepo, imaging.NewFakeDetector())
+	conn := streamTestConnection(t, svc)
+
+	sendFrame(t, conn, "cardImageModified.jpg")
+	match := readEvent(t, conn)
+	processed := readEvent(t, conn)
+	sendFrame(t, conn, "cardImageModified.jpg")
+	duplicate := readEvent(t, conn)
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("hello")))
+	text := readEvent(t, conn)
+	require.NoError(t, conn.WriteMessage(websocket.BinaryMessage, []byte("no image")))
+	invalid := readEvent(t, conn)
+	sendFrame(t, conn, "noscore.jpg")
+	noMatch := readEvent(t, conn)
+
+	assert.Equal(t, cardsapi.EventMatch, match.Type)
+	assert.Equal(t, 1, match.Frame)
+	require.NotNil(t, match.Region)
+	require.Len(t, match.Region.Matches, 1)
+	assert.Equal(t, "Ancestor's Chosen", match.Region.Matches[0].Name)
+	assert.Equal(t, cardsapi.StreamEvent{Type: cardsapi.EventProcessed, Frame: 1, Regions: 1}, processed)
+	assert.Equal(t, cardsapi.StreamEvent{
+		Type: cardsapi.EventSkipped, Frame: 2, Reason: cardsapi.SkippedDuplicate,
+	}, duplicate)
+	assert.Equal(t, cardsapi.EventError, text.Type)
+	assert.Equal(t, "invalid-frame", text.Error)
+	assert.Equal(t, cardsapi.EventError, invalid.Type)
+	assert.Equal(t, "invalid-file", invalid.Error)
+	assert.Equal(t, cardsapi.StreamEvent{Type: cardsapi.EventProcessed, Frame: 5, Regions: 1}, noMatch)
+}
+
+func TestDetectStreamSkipsOutdatedFrames(t *testing.T) {
+	svc := &blockingDetectService{started: make(chan struct{}, 3), release: make(chan struct{})}
+	conn := streamTestConnection(t, svc)
+
+	sendFrame(t, conn, "cardImageModified.jpg")
+	<-svc.started
+	sendFrame(t, conn, "cardImage.jpg")
+	sendFrame(t, conn, "noscore.jpg")
+	busy := readEvent(t, conn)
+	close(svc.release)
+	first := readEvent(t, conn)
+	last := readEvent(t, conn)
+
+	assert.Equal(t, cardsapi.StreamEvent{Type: cardsapi.EventSkipped, Frame: 2, Reason: cardsapi.SkippedBusy}, busy)
+	assert.Equal(t, cardsapi.StreamEvent{Type: cardsapi.EventProcessed, Frame: 1}, first)
+	assert.Equal(t, cardsapi.StreamEvent{Type: cardsapi.EventProcessed, Frame: 3}, last)
+}
+
+func TestDetectStreamClosesSessionOnPanic(t *testing.T) {
+	conn := streamTestConnection(t, &panickingDetectService{})
+
+	sendFrame(t, conn, "cardImage.jpg")
+	event := readEvent(t, conn)
+	_, _, err := conn.ReadMessage()
+
+	assert.Equal(t, cardsapi.EventError, event.Type)
+	assert.Equal(t, 1, event.Frame)
+	assert.Equal(t, "internal-error", event.Error)
+	require.True(t, websocket.IsCloseError(err, websocket.CloseInternalServerErr), "unexpected error %v", err)
+}
+
+func TestDetectStreamRequiresUpgrade(t *testing.T) {
+	srv := web.NewTestServer()
+	srv.RegisterRoutes(func(r fiber.Router) {
+		cardsapi.DetectRoutes(r.Group("/"), web.NewAuthMiddleware(auth.Config{}, auth.New(auth.Config{}, auth.NewProviders())),
+			web.Upload{}, &blockingDetectService{})
+	})
+
+	req := test.NewRequest(test.WithMethod(fiber.MethodGet), test.WithURL("/detect/stream"))
+	resp, err := srv.Test(req)
+
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusUpgradeRequired, resp.StatusCode)
+}
+
+func streamTestConnection(t *testing.T, svc cardsapi.DetectService) *websocket.Conn {
+	t.Helper()
+
+	srv := web.NewTestServer()
+	oCfg := auth.Config{}
+	authSvc := auth.New(oCfg, auth.NewProviders(auth.NewFakeProvider()))
+	srv.RegisterRoutes(func(r fiber.Router) {
+		cardsapi.DetectRoutes(r.Group("/"), web.NewAuthMiddleware(oCfg, authSvc), web.Upload{}, svc)
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		_ = srv.Serve(ln)
+	}()
+
+	conn, resp, err := websocket.DefaultDialer.Dial("ws://"+ln.Addr().String()+"/detect/stream", nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	t.Cleanup(func() {
+		_ = conn.Close()
+		_ = ln.Close()
+	})
+
+	return conn
+}
+
+func sendFrame(t *testing.T, conn *websocket.Conn, img string) {
+	t.Helper()
+
+	data, err := os.ReadFile(path.Join(currentDir(), "testdata", img))
+	require.NoError(t, err)
+	require.NoError(t, conn.WriteMessage(websocket.BinaryMessage, data))
+}
+
+func readEvent(t *testing.T, conn *websocket.Conn) cardsapi.StreamEvent {
+	t.Helper()
+
+	var event cardsapi.StreamEvent
+	require.NoError(t, conn.ReadJSON(&event))
+
+	return event
+}
+
+// blockingDetectService finds nothing, every detection waits until release is closed.
+type blockingDetectService struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingDetectService) Detect(_ context.Context, _ cards.Collector, _ io.Reader) (cards.DetectionResult, error) {
+	s.started <- struct{}{}
+	<-s.release
+
+	return cards.DetectionResult{}, nil
+}
+
+func (s *blockingDetectService) DetectAndCollect(
+	_ context.Context, _ cards.Collector, _ io.Reader,
+) (cards.CollectResult, error) {
+	return cards.CollectResult{}, nil
+}
+
+func (s *blockingDetectService) Debug(_ context.Context, _ cards.Collector, _ io.Reader) (cards.DebugResult, error) {
+	return cards.DebugResult{}, nil
+}
+
+// panickingDetectService fails on every frame like a decoder that cannot handle a malformed image.
+type panickingDetectService struct {
+	blockingDetectService
+}
+
+func (s *panickingDetectService) Detect(_ context.Context, _ cards.Collector, _ io.Reader) (cards.DetectionResult, error) {
+	panic("malformed frame")
+}
@@ -7,6 +7,7 @@ type Config struct {
 	Cookie      Cookie `yaml:"cookie"`
 	TemplateDir string `yaml:"template_path"`
 	TLS         TLS    `yaml:"tls"`
+	Upload      Upload `yaml:"upload"`
 	Port        int    `yaml:"port"`
 }
 
@@ -29,3 +30,35 @@ type Cookie struct {
 	// EncryptionKey a 32 character string
 	EncryptionKey string `yaml:"encryption_key"`
 }
+
+// Upload limits for uploaded images, they are checked before an image is decoded.
+type Upload struct {
+	// MaxBytes the maximal size of an uploaded file.
+	MaxBytes int64 `yaml:"max_bytes"`
+	// MaxWidth the maximal width of an uploaded image in pixel.
+	MaxWidth int `yaml:"max_width"`
+	// MaxHeight the maximal height of an uploaded image in pixel.
+	MaxHeight int `yaml:"max_height"`
+}
+
+func (u Upload) MaxFileSize() int64 {
+	defaultMaxBytes := int64(10 * 1024 * 1024)
+	if u.MaxBytes < 1 {
+		return defaultMaxBytes
+	}
+
+	return u.MaxBytes
+}
+
+func (u Upload) MaxDimensions() (int, int) {
+	defaultMaxSize := 8192
+	width, height := u.MaxWidth, u.MaxHeight
+	if width < 1 {
+		width = defaultMaxSize
+	}
+	if height < 1 {
+		height = defaultMaxSize
+	}
+
+	return width, height
+}
@@ -56,6 +56,14 @@ func RespondWithProblemJSON(c *fiber.Ctx, err error) error {
 		code = StatusBadRequest
 	case aerrors.ErrAuthorization:
 		code = StatusUnauthorized
+	case aerrors.ErrForbidden:
+		code = StatusForbidden
+	case aerrors.ErrNotFound:
+		code = StatusNotFound
+	case aerrors.ErrTooLarge:
+		code = StatusRequestEntityTooLarge
+	case aerrors.ErrUnavailable:
+		code = StatusServiceUnavailable
 	default:
 		code = StatusInternalServerError
 	}
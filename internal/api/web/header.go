@@ -9,12 +9,22 @@ import (
 
 const MethodGet = http.MethodGet
 const MethodPost = http.MethodPost
+const MethodDelete = http.MethodDelete
+const MethodPatch = http.MethodPatch
 
 const StatusOK = http.StatusOK
 const StatusFound = http.StatusFound
 const StatusUnauthorized = http.StatusUnauthorized
+const StatusForbidden = http.StatusForbidden
 const StatusBadRequest = http.StatusBadRequest
+const StatusNotFound = http.StatusNotFound
+const StatusRequestEntityTooLarge = http.StatusRequestEntityTooLarge
+const StatusCreated = http.StatusCreated
+const StatusNoContent = http.StatusNoContent
 const StatusInternalServerError = http.StatusInternalServerError
+const StatusServiceUnavailable = http.StatusServiceUnavailable
+const StatusAccepted = http.StatusAccepted
+const StatusNotModified = http.StatusNotModified
 
 const HeaderHTMXRequest = "HX-Request"
 
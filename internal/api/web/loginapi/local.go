@@ -0,0 +1,152 @@
+package loginapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/konstantinfoerster/card-service-go/internal/aerrors"
+	"github.com/konstantinfoerster/card-service-go/internal/api/web"
+	"github.com/konstantinfoerster/card-service-go/internal/auth"
+)
+
+type LocalService interface {
+	Login(ctx context.Context, username, password, state string) (auth.RedirectURL, error)
+	Register(ctx context.Context, username, password string) (auth.LocalAccount, error)
+	ChangePassword(ctx context.Context, id, current, password string) error
+	RegistrationEnabled() bool
+}
+
+// LocalRoutes All routes of the local login provider. The login itself ends in the
+// regular callback route of Routes.
+func LocalRoutes(app fiber.Router, auth web.AuthMiddleware, svc LocalService) {
+	app.Get("/login/local/form", loginForm(svc))
+	app.Post("/login/local", localLogin(svc))
+	app.Post("/login/local/register", register(svc))
+	app.Post("/user/password", auth.Required(), changePassword(svc))
+}
+
+func loginForm(svc LocalService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		state, err := requiredQuery(c, "state")
+		if err != nil {
+			return err
+		}
+
+		return renderLoginForm(c, svc, Credentials{State: state}, "")
+	}
+}
+
+func localLogin(svc LocalService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var body Credentials
+		if err := c.BodyParser(&body); err != nil {
+			return aerrors.NewInvalidInputError(err, "invalid-body", "invalid body format")
+		}
+
+		if _, err := required(body.State, "state"); err != nil {
+			return err
+		}
+
+		url, err := svc.Login(c.Context(), body.Username, body.Password, body.State)
+		if err != nil {
+			return loginFormError(c, svc, body, err)
+		}
+
+		return c.Redirect(url.URL, http.StatusSeeOther)
+	}
+}
+
+func register(svc LocalService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var body Credentials
+		if err := c.BodyParser(&body); err != nil {
+			return aerrors.NewInvalidInputError(err, "invalid-body", "invalid body format")
+		}
+
+		if _, err := required(body.State, "state"); err != nil {
+			return err
+		}
+
+		if _, err := svc.Register(c.Context(), body.Username, body.Password); err != nil {
+			return loginFormError(c, svc, body, err)
+		}
+
+		url, err := svc.Login(c.Context(), body.Username, body.Password, body.State)
+		if err != nil {
+			return err
+		}
+
+		return c.Redirect(url.URL, http.StatusSeeOther)
+	}
+}
+
+// changePassword changes the password of the current user, this ends all sessions of the user.
+// Requires a session of the local provider, the subject of the session is the account id.
+func changePassword(svc LocalService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		u, err := web.UserFromCtx(c)
+		if err != nil {
+			return aerrors.NewAuthorizationError(err, "unauthorized")
+		}
+
+		var body PasswordChange
+		if err = c.BodyParser(&body); err != nil {
+			return aerrors.NewInvalidInputError(err, "invalid-body", "invalid body format")
+		}
+
+		if err := svc.ChangePassword(c.Context(), u.Subject, body.CurrentPassword, body.NewPassword); err != nil {
+			return err
+		}
+
+		return c.SendStatus(web.StatusNoContent)
+	}
+}
+
+// loginFormError shows the login form again with a message for expected errors, if HTML is accepted.
+func loginFormError(c *fiber.Ctx, svc LocalService, body Credentials, err error) error {
+	var appErr aerrors.AppError
+	if !web.AcceptsHTML(c) || !errors.As(err, &appErr) {
+		return err
+	}
+
+	var status int
+	msg := appErr.Msg
+	switch appErr.ErrorType {
+	case aerrors.ErrInvalidInput:
+		status = web.StatusBadRequest
+	case aerrors.ErrAuthorization:
+		status = web.StatusUnauthorized
+		msg = "Invalid username or password"
+	case aerrors.ErrForbidden:
+		status = web.StatusForbidden
+		msg = "Registration is disabled"
+	default:
+		return err
+	}
+
+	c.Status(status)
+
+	return renderLoginForm(c, svc, body, msg)
+}
+
+func renderLoginForm(c *fiber.Ctx, svc LocalService, body Credentials, msg string) error {
+	return web.RenderPage(c, "login_local", fiber.Map{
+		"State":        body.State,
+		"Username":     body.Username,
+		"Error":        msg,
+		"Registration": svc.RegistrationEnabled(),
+	})
+}
+
+type Credentials struct {
+	Username string `json:"username" form:"username"`
+	Password string `json:"password" form:"password"`
+	State    string `json:"state" form:"state"`
+}
+
+type PasswordChange struct {
+	CurrentPassword string `json:"currentPassword" form:"currentPassword"`
+	NewPassword     string `json:"newPassword" form:"newPassword"`
+}
@@ -0,0 +1,208 @@
+package loginapi_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/konstantinfoerster/card-service-go/internal/api/web"
+	"github.com/konstantinfoerster/card-service-go/internal/api/web/loginapi"
+	"github.com/konstantinfoerster/card-service-go/internal/auth"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/memory"
+	"github.com/konstantinfoerster/card-service-go/internal/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalLoginForm(t *testing.T) {
+	srv, _ := localServer(t)
+	req := test.NewRequest(
+		test.WithMethod(http.MethodGet),
+		test.WithURL("http://localhost/login/local/form?state=state-0"),
+		test.WithAccept(fiber.MIMETextHTML),
+	)
+
+	resp, err := srv.Test(req)
+	defer test.Close(t, resp)
+
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body := test.ToString(t, resp.Body)
+	assert.Contains(t, body, `name="state" value="state-0"`)
+	assert.Contains(t, body, `data-testid="register-submit-btn"`)
+}
+
+func TestLocalLogin(t *testing.T) {
+	srv, provider := localServer(t)
+	_, err := provider.Register(context.Background(), "myuser", "secret-password")
+	require.NoError(t, err)
+
+	req := formRequest(t, "http://localhost/login/local", url.Values{
+		"username": {"myuser"},
+		"password": {"secret-password"},
+		"state":    {"state-0"},
+	})
+	resp, err := srv.Test(req)
+	defer test.Close(t, resp)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusSeeOther, resp.StatusCode)
+	callback := resp.Header.Get(fiber.HeaderLocation)
+	require.True(t, strings.HasPrefix(callback, "/api/v1/login/local/callback?code="))
+
+	// callback of the regular login flow
+	req = test.NewRequest(
+		test.WithMethod(http.MethodGet),
+		test.WithURL("http://localhost"+strings.TrimPrefix(callback, "/api/v1")),
+		test.WithCookie("TOKEN_STATE", encryptCookieValue(t, "state-0")),
+	)
+	resp, err = srv.Test(req)
+	defer test.Close(t, resp)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	user := test.FromJSON[web.ClientUser](t, resp.Body)
+	assert.Equal(t, "myuser", user.Username)
+}
+
+func TestLocalLoginInvalidCredentials(t *testing.T) {
+	cases := []struct {
+		name           string
+		accept         string
+		expectedStatus int
+	}{
+		{
+			name:           "html response",
+			accept:         fiber.MIMETextHTML,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "json response",
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv, _ := localServer(t)
+			req := formRequest(t, "http://localhost/login/local", url.Values{
+				"username": {"unknown"},
+				"password": {"secret-password"},
+				"state":    {"state-0"},
+			})
+			if tc.accept != "" {
+				req.Header.Set(fiber.HeaderAccept, tc.accept)
+			}
+
+			resp, err := srv.Test(req)
+			defer test.Close(t, resp)
+
+			require.NoError(t, err)
+			if tc.accept == "" {
+				assertErrorResponse(t, resp, tc.expectedStatus)
+
+				return
+			}
+			require.Equal(t, tc.expectedStatus, resp.StatusCode)
+			assert.Contains(t, test.ToString(t, resp.Body), `data-testid="login-error-txt"`)
+		})
+	}
+}
+
+func TestLocalRegister(t *testing.T) {
+	srv, provider := localServer(t)
+
+	req := formRequest(t, "http://localhost/login/local/register", url.Values{
+		"username": {"newuser"},
+		"password": {"secret-password"},
+		"state":    {"state-0"},
+	})
+	resp, err := srv.Test(req)
+	defer test.Close(t, resp)
+
+	require.NoError(t, err)
+	require.Equal(t, http.StatusSeeOther, resp.StatusCode)
+	_, err = provider.Login(context.Background(), "newuser", "secret-password", "state-0")
+	require.NoError(t, err)
+}
+
+func TestChangePassword(t *testing.T) {
+	srv, provider := localServer(t)
+	_, err := provider.Register(context.Background(), "myuser", "secret-password")
+	require.NoError(t, err)
+	session := localSession(t, provider, "myuser", "secret-password")
+
+	req := test.NewRequest(
+		test.WithMethod(http.MethodPost),
+		test.WithURL("http://localhost/user/password"),
+		test.WithCookie("SESSION", encryptCookieValue(t, session)),
+		test.WithJSONBody(t, loginapi.PasswordChange{CurrentPassword: "wrong-password", NewPassword: "new-password"}),
+	)
+	resp, err := srv.Test(req)
+	defer test.Close(t, resp)
+	require.NoError(t, err)
+	assertErrorResponse(t, resp, http.StatusUnauthorized)
+
+	req = test.NewRequest(
+		test.WithMethod(http.MethodPost),
+		test.WithURL("http://localhost/user/password"),
+		test.WithCookie("SESSION", encryptCookieValue(t, session)),
+		test.WithJSONBody(t, loginapi.PasswordChange{CurrentPassword: "secret-password", NewPassword: "new-password"}),
+	)
+	resp, err = srv.Test(req)
+	defer test.Close(t, resp)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	_, err = provider.Login(context.Background(), "myuser", "new-password", "state-0")
+	require.NoError(t, err)
+}
+
+func localServer(t *testing.T) (*web.Server, *auth.LocalProvider) {
+	t.Helper()
+
+	oCfg := auth.Config{
+		StateCookieAge:    5 * time.Second,
+		SessionCookieName: "SESSION",
+	}
+	cfg := auth.ProviderCfg{Secret: "0123456789abcdef0123456789abcdef", Registration: true}
+	provider, err := auth.NewLocalProvider(cfg, memory.NewAccountRepository(), staticTimeSvc)
+	require.NoError(t, err)
+	svc := auth.New(oCfg, auth.NewProviders(provider))
+	users := auth.NewUserService(memory.NewUserRepository(), staticTimeSvc)
+	srv := web.NewTestServer()
+	cookieEncryptionKey = srv.Cfg.Cookie.EncryptionKey
+	srv.RegisterRoutes(func(r fiber.Router) {
+		authMiddleware := web.NewAuthMiddleware(oCfg, svc, auth.WithProfile(users))
+		loginapi.Routes(r.Group("/"), authMiddleware, oCfg, svc, users, staticTimeSvc)
+		loginapi.LocalRoutes(r.Group("/"), authMiddleware, provider)
+	})
+
+	return srv, provider
+}
+
+func localSession(t *testing.T, p *auth.LocalProvider, username, password string) string {
+	t.Helper()
+
+	redirect, err := p.Login(context.Background(), username, password, "state-0")
+	require.NoError(t, err)
+	callback, err := url.Parse(redirect.URL)
+	require.NoError(t, err)
+	_, token, err := p.ExchangeCode(context.Background(), callback.Query().Get("code"))
+	require.NoError(t, err)
+
+	return test.Base64Encoded(t, token)
+}
+
+func formRequest(t *testing.T, target string, values url.Values) *http.Request {
+	t.Helper()
+
+	return test.NewRequest(
+		test.WithMethod(http.MethodPost),
+		test.WithURL(target),
+		test.WithBody([]byte(values.Encode())),
+		test.WithHeader(map[string]string{fiber.HeaderContentType: fiber.MIMEApplicationForm}),
+	)
+}
@@ -14,26 +14,49 @@ import (
 	"github.com/konstantinfoerster/card-service-go/internal/auth"
 )
 
-const stateCookie = "TOKEN_STATE"
+const (
+	stateCookie = "TOKEN_STATE"
+	// linkCookie contains the id of the user that links another provider.
+	linkCookie = "LINK_USER"
+)
 
 type TimeService interface {
 	Now() time.Time
 }
 
 type Service interface {
+	ProviderNames() []string
 	AuthURL(provider string) (auth.RedirectURL, error)
 	Authenticate(ctx context.Context, provider string, code string) (auth.Claims, *auth.JWT, error)
 	Logout(ctx context.Context, token *auth.JWT) error
 }
 
+type IdentityLinker interface {
+	Link(ctx context.Context, userID string, c auth.Claims) error
+}
+
 // Routes All login and user related routes.
-func Routes(app fiber.Router, auth web.AuthMiddleware, cfg auth.Config, svc Service, tSvc TimeService) {
+func Routes(app fiber.Router, auth web.AuthMiddleware, cfg auth.Config, svc Service, linker IdentityLinker,
+	tSvc TimeService) {
 	log := slog.Default()
 
-	app.Get("/login/:provider/callback", exchangeCode(cfg, svc, tSvc))
+	app.Get("/login", providers(svc))
+	app.Get("/login/:provider/callback", exchangeCode(cfg, svc, linker, tSvc))
 	app.Get("/login/:provider", login(cfg, svc, tSvc, log))
 	app.Get("/logout", logout(cfg, svc, tSvc))
 	app.Get("/user", auth.Required(), getCurrentUser())
+	app.Get("/user/link/:provider", auth.Required(), link(cfg, svc, tSvc))
+}
+
+func providers(svc Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		names := svc.ProviderNames()
+		if web.AcceptsHTML(c) {
+			return web.RenderPage(c, "login", fiber.Map{"Providers": names})
+		}
+
+		return web.RenderJSON(c, names)
+	}
 }
 
 func login(cfg auth.Config, svc Service, timeSvc TimeService, log *slog.Logger) fiber.Handler {
@@ -57,7 +80,33 @@ func login(cfg auth.Config, svc Service, timeSvc TimeService, log *slog.Logger)
 	}
 }
 
-func exchangeCode(cfg auth.Config, svc Service, timeSvc TimeService) fiber.Handler {
+// link starts the login with another provider, the callback links that identity to the current user.
+func link(cfg auth.Config, svc Service, timeSvc TimeService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		u, err := web.UserFromCtx(c)
+		if err != nil {
+			return aerrors.NewAuthorizationError(err, "unauthorized")
+		}
+
+		provider, err := requiredParam(c, "provider")
+		if err != nil {
+			return err
+		}
+
+		url, err := svc.AuthURL(provider)
+		if err != nil {
+			return err
+		}
+
+		expires := timeSvc.Now().Add(cfg.StateCookieAge)
+		setStateCookie(c, url.State, expires)
+		setLinkCookie(c, u.ID, expires)
+
+		return c.Redirect(url.URL, http.StatusFound)
+	}
+}
+
+func exchangeCode(cfg auth.Config, svc Service, linker IdentityLinker, timeSvc TimeService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		provider, err := requiredParam(c, "provider")
 		if err != nil {
@@ -95,6 +144,12 @@ func exchangeCode(cfg auth.Config, svc Service, timeSvc TimeService) fiber.Handl
 			return err
 		}
 
+		if linkUserID := strings.TrimSpace(c.Cookies(linkCookie)); linkUserID != "" {
+			clearCookie(c, linkCookie, timeSvc.Now())
+
+			return linkIdentity(c, linker, linkUserID, claims)
+		}
+
 		token64, err := token.Encode()
 		if err != nil {
 			return err
@@ -107,27 +162,21 @@ func exchangeCode(cfg auth.Config, svc Service, timeSvc TimeService) fiber.Handl
 			return c.Render("finish_login", nil)
 		}
 
-		return web.RenderJSON(c, web.NewClientUser(web.NewUser(claims.ID, claims.Email)))
+		return web.RenderJSON(c, web.NewClientUser(web.NewUser(claims.ID, claims.DisplayName())))
 	}
 }
 
 func logout(cfg auth.Config, svc Service, timeSvc TimeService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		clearCookie(c, stateCookie, timeSvc.Now())
+		clearCookie(c, linkCookie, timeSvc.Now())
 
 		cookieValue := strings.TrimSpace(c.Cookies(cfg.SessionCookieName))
 		if cookieValue == "" {
 			return c.SendStatus(http.StatusOK)
 		}
-		clearCookie(c, cfg.SessionCookieName, timeSvc.Now())
 
-		token, err := auth.DecodeSession(cookieValue)
-		if err != nil {
-			return err
-		}
-
-		err = svc.Logout(c.Context(), token)
-		if err != nil {
+		if err := endSession(c, cfg, svc, timeSvc); err != nil {
 			return err
 		}
 
@@ -139,6 +188,35 @@ func logout(cfg auth.Config, svc Service, timeSvc TimeService) fiber.Handler {
 	}
 }
 
+// linkIdentity links the identity to the user, the session of the user stays unchanged.
+func linkIdentity(c *fiber.Ctx, linker IdentityLinker, userID string, claims auth.Claims) error {
+	if err := linker.Link(c.Context(), userID, claims); err != nil {
+		return err
+	}
+
+	if web.AcceptsHTML(c) {
+		return c.Render("finish_login", nil)
+	}
+
+	return c.SendStatus(web.StatusNoContent)
+}
+
+// endSession removes the session cookie and revokes the session token, if there is one.
+func endSession(c *fiber.Ctx, cfg auth.Config, svc Service, timeSvc TimeService) error {
+	cookieValue := strings.TrimSpace(c.Cookies(cfg.SessionCookieName))
+	if cookieValue == "" {
+		return nil
+	}
+	clearCookie(c, cfg.SessionCookieName, timeSvc.Now())
+
+	token, err := auth.DecodeSession(cookieValue)
+	if err != nil {
+		return err
+	}
+
+	return svc.Logout(c.Context(), token)
+}
+
 func getCurrentUser() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		u, err := web.UserFromCtx(c)
@@ -193,6 +271,18 @@ func setStateCookie(c *fiber.Ctx, value string, expires time.Time) {
 	})
 }
 
+func setLinkCookie(c *fiber.Ctx, userID string, expires time.Time) {
+	c.Cookie(&fiber.Cookie{
+		Name:     linkCookie,
+		Value:    userID,
+		Path:     "/",
+		HTTPOnly: true,
+		Secure:   true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+		Expires:  expires,
+	})
+}
+
 func clearCookie(c *fiber.Ctx, name string, now time.Time) {
 	cookie := c.Cookies(name)
 	if cookie == "" {
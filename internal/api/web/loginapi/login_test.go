@@ -14,6 +14,7 @@ import (
 	"github.com/konstantinfoerster/card-service-go/internal/api/web"
 	"github.com/konstantinfoerster/card-service-go/internal/api/web/loginapi"
 	"github.com/konstantinfoerster/card-service-go/internal/auth"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/memory"
 	"github.com/konstantinfoerster/card-service-go/internal/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -60,6 +61,21 @@ func TestLogin(t *testing.T) {
 	assertEqualCookie(t, expectedCookie, resp.Cookies()[0])
 }
 
+func TestListProviders(t *testing.T) {
+	srv := loginServer(staticTimeSvc, auth.NewFakeProvider())
+	req := test.NewRequest(
+		test.WithMethod(http.MethodGet),
+		test.WithURL("http://localhost/login"),
+	)
+
+	resp, err := srv.Test(req)
+	defer test.Close(t, resp)
+
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{"testProvider"}, *test.FromJSON[[]string](t, resp.Body))
+}
+
 func TestLoginUnknownProvider(t *testing.T) {
 	srv := loginServer(staticTimeSvc, nil)
 	req := test.NewRequest(
@@ -423,10 +439,11 @@ func loginServer(timeSvc loginapi.TimeService, provider auth.Provider) *web.Serv
 		SessionCookieName: "SESSION",
 	}
 	svc := auth.New(oCfg, auth.NewProviders(provider))
+	users := auth.NewUserService(memory.NewUserRepository(), timeSvc)
 	srv := web.NewTestServer()
 	cookieEncryptionKey = srv.Cfg.Cookie.EncryptionKey
 	srv.RegisterRoutes(func(r fiber.Router) {
-		loginapi.Routes(r.Group("/"), web.NewAuthMiddleware(oCfg, svc), oCfg, svc, timeSvc)
+		loginapi.Routes(r.Group("/"), web.NewAuthMiddleware(oCfg, svc), oCfg, svc, users, timeSvc)
 	})
 
 	return srv
@@ -0,0 +1,125 @@
+package loginapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/konstantinfoerster/card-service-go/internal/aerrors"
+	"github.com/konstantinfoerster/card-service-go/internal/api/web"
+	"github.com/konstantinfoerster/card-service-go/internal/auth"
+)
+
+type TokenService interface {
+	Create(ctx context.Context, c auth.Claims, name string, scopes []auth.Scope,
+		ttl time.Duration) (auth.PersonalToken, string, error)
+	List(ctx context.Context, userID string) ([]auth.PersonalToken, error)
+	Revoke(ctx context.Context, userID string, id string) error
+}
+
+// TokenRoutes All personal access token related routes. Tokens can only be created and revoked with a
+// session, a token must not be able to create tokens with more rights or a longer expiry.
+func TokenRoutes(app fiber.Router, auth web.AuthMiddleware, svc TokenService) {
+	app.Get("/user/tokens", auth.Required(), listTokens(svc))
+	app.Post("/user/tokens", auth.Required(), auth.RequireSession(), createToken(svc))
+	app.Delete("/user/tokens/:id", auth.Required(), auth.RequireSession(), revokeToken(svc))
+}
+
+func listTokens(svc TokenService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		u, err := web.UserFromCtx(c)
+		if err != nil {
+			return aerrors.NewAuthorizationError(err, "unauthorized")
+		}
+
+		tokens, err := svc.List(c.Context(), u.ID)
+		if err != nil {
+			return err
+		}
+
+		result := make([]Token, 0, len(tokens))
+		for _, t := range tokens {
+			result = append(result, newToken(t))
+		}
+
+		return web.RenderJSON(c, result)
+	}
+}
+
+func createToken(svc TokenService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		u, err := web.UserFromCtx(c)
+		if err != nil {
+			return aerrors.NewAuthorizationError(err, "unauthorized")
+		}
+
+		var body NewToken
+		if err = c.BodyParser(&body); err != nil {
+			return aerrors.NewInvalidInputError(err, "invalid-body", "invalid body format")
+		}
+
+		scopes, err := auth.ParseScopes(body.Scopes)
+		if err != nil {
+			return aerrors.NewInvalidInputError(err, "invalid-token-scope", "unknown scope")
+		}
+
+		ttl := time.Duration(body.ExpiresInDays) * 24 * time.Hour
+		token, plain, err := svc.Create(c.Context(), auth.NewClaims(u.ID, u.Email), body.Name, scopes, ttl)
+		if err != nil {
+			return err
+		}
+
+		result := newToken(token)
+		result.Token = plain
+
+		c.Status(web.StatusCreated)
+
+		return web.RenderJSON(c, result)
+	}
+}
+
+func revokeToken(svc TokenService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		u, err := web.UserFromCtx(c)
+		if err != nil {
+			return aerrors.NewAuthorizationError(err, "unauthorized")
+		}
+
+		id, err := requiredParam(c, "id")
+		if err != nil {
+			return err
+		}
+
+		if err := svc.Revoke(c.Context(), u.ID, id); err != nil {
+			return err
+		}
+
+		return c.SendStatus(web.StatusNoContent)
+	}
+}
+
+type NewToken struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expiresInDays"`
+}
+
+type Token struct {
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	// Token the plain text token, only returned once after creation.
+	Token  string       `json:"token,omitempty"`
+	Scopes []auth.Scope `json:"scopes"`
+}
+
+func newToken(t auth.PersonalToken) Token {
+	return Token{
+		ID:        t.ID,
+		Name:      t.Name,
+		Scopes:    t.Scopes,
+		CreatedAt: t.CreatedAt,
+		ExpiresAt: t.ExpiresAt,
+	}
+}
@@ -0,0 +1,195 @@
+package loginapi_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/konstantinfoerster/card-service-go/internal/api/web"
+	"github.com/konstantinfoerster/card-service-go/internal/api/web/loginapi"
+	"github.com/konstantinfoerster/card-service-go/internal/auth"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/memory"
+	"github.com/konstantinfoerster/card-service-go/internal/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenLifecycle(t *testing.T) {
+	provider := auth.NewFakeProvider(auth.WithClaims(auth.NewClaims("myuser", "myUser")))
+	srv := tokenServer(provider)
+	session := test.WithCookie("SESSION", encryptCookieValue(t, test.Base64Encoded(t, provider.Token("myuser"))))
+
+	// create
+	req := test.NewRequest(
+		test.WithMethod(web.MethodPost),
+		test.WithURL("http://localhost/user/tokens"),
+		session,
+		test.WithJSONBody(t, loginapi.NewToken{Name: "script", Scopes: []string{"read"}, ExpiresInDays: 7}),
+	)
+	resp, err := srv.Test(req)
+	defer test.Close(t, resp)
+	require.NoError(t, err)
+	require.Equal(t, web.StatusCreated, resp.StatusCode)
+	created := test.FromJSON[loginapi.Token](t, resp.Body)
+	assert.Equal(t, "script", created.Name)
+	assert.Equal(t, []auth.Scope{auth.ScopeRead}, created.Scopes)
+	require.NotEmpty(t, created.Token)
+
+	// list with the new token
+	req = test.NewRequest(
+		test.WithMethod(web.MethodGet),
+		test.WithURL("http://localhost/user/tokens"),
+		test.WithHeader(map[string]string{fiber.HeaderAuthorization: "Bearer " + created.Token}),
+	)
+	resp, err = srv.Test(req)
+	defer test.Close(t, resp)
+	require.NoError(t, err)
+	require.Equal(t, web.StatusOK, resp.StatusCode)
+	tokens := test.FromJSON[[]loginapi.Token](t, resp.Body)
+	require.Len(t, *tokens, 1)
+	assert.Equal(t, created.ID, (*tokens)[0].ID)
+	assert.Empty(t, (*tokens)[0].Token)
+
+	// a read-only token can't revoke
+	req = test.NewRequest(
+		test.WithMethod(web.MethodDelete),
+		test.WithURLf("http://localhost/user/tokens/%s", created.ID),
+		test.WithHeader(map[string]string{fiber.HeaderAuthorization: "Bearer " + created.Token}),
+	)
+	resp, err = srv.Test(req)
+	defer test.Close(t, resp)
+	require.NoError(t, err)
+	assertErrorResponse(t, resp, http.StatusForbidden)
+
+	// revoke
+	req = test.NewRequest(
+		test.WithMethod(web.MethodDelete),
+		test.WithURLf("http://localhost/user/tokens/%s", created.ID),
+		session,
+	)
+	resp, err = srv.Test(req)
+	defer test.Close(t, resp)
+	require.NoError(t, err)
+	require.Equal(t, web.StatusNoContent, resp.StatusCode)
+
+	// revoked token is rejected
+	req = test.NewRequest(
+		test.WithMethod(web.MethodGet),
+		test.WithURL("http://localhost/user/tokens"),
+		test.WithHeader(map[string]string{fiber.HeaderAuthorization: "Bearer " + created.Token}),
+	)
+	resp, err = srv.Test(req)
+	defer test.Close(t, resp)
+	require.NoError(t, err)
+	assertErrorResponse(t, resp, http.StatusUnauthorized)
+}
+
+func TestManageTokensRequiresSession(t *testing.T) {
+	provider := auth.NewFakeProvider(auth.WithClaims(auth.NewClaims("myuser", "myUser")))
+	srv := tokenServer(provider)
+	req := test.NewRequest(
+		test.WithMethod(web.MethodPost),
+		test.WithURL("http://localhost/user/tokens"),
+		test.WithCookie("SESSION", encryptCookieValue(t, test.Base64Encoded(t, provider.Token("myuser")))),
+		test.WithJSONBody(t, loginapi.NewToken{Name: "script", Scopes: []string{"write"}, ExpiresInDays: 7}),
+	)
+	resp, err := srv.Test(req)
+	defer test.Close(t, resp)
+	require.NoError(t, err)
+	require.Equal(t, web.StatusCreated, resp.StatusCode)
+	created := test.FromJSON[loginapi.Token](t, resp.Body)
+	bearer := test.WithHeader(map[string]string{fiber.HeaderAuthorization: "Bearer " + created.Token})
+
+	// a write token can't create another token
+	req = test.NewRequest(
+		test.WithMethod(web.MethodPost),
+		test.WithURL("http://localhost/user/tokens"),
+		bearer,
+		test.WithJSONBody(t, loginapi.NewToken{Name: "other", Scopes: []string{"write"}, ExpiresInDays: 365}),
+	)
+	resp, err = srv.Test(req)
+	defer test.Close(t, resp)
+	require.NoError(t, err)
+	assertErrorResponse(t, resp, http.StatusForbidden)
+
+	// a write token can't revoke a token
+	req = test.NewRequest(
+		test.WithMethod(web.MethodDelete),
+		test.WithURLf("http://localhost/user/tokens/%s", created.ID),
+		bearer,
+	)
+	resp, err = srv.Test(req)
+	defer test.Close(t, resp)
+	require.NoError(t, err)
+	assertErrorResponse(t, resp, http.StatusForbidden)
+}
+
+func TestCreateTokenInvalidInput(t *testing.T) {
+	cases := []struct {
+		name string
+		body loginapi.NewToken
+	}{
+		{
+			name: "unknown scope",
+			body: loginapi.NewToken{Name: "script", Scopes: []string{"admin"}},
+		},
+		{
+			name: "missing name",
+			body: loginapi.NewToken{Scopes: []string{"read"}},
+		},
+		{
+			name: "expiry too long",
+			body: loginapi.NewToken{Name: "script", Scopes: []string{"read"}, ExpiresInDays: 1000},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := auth.NewFakeProvider(auth.WithClaims(auth.NewClaims("myuser", "myUser")))
+			srv := tokenServer(provider)
+			req := test.NewRequest(
+				test.WithMethod(web.MethodPost),
+				test.WithURL("http://localhost/user/tokens"),
+				test.WithCookie("SESSION", encryptCookieValue(t, test.Base64Encoded(t, provider.Token("myuser")))),
+				test.WithJSONBody(t, tc.body),
+			)
+
+			resp, err := srv.Test(req)
+			defer test.Close(t, resp)
+
+			require.NoError(t, err)
+			assertErrorResponse(t, resp, http.StatusBadRequest)
+		})
+	}
+}
+
+func TestRevokeUnknownToken(t *testing.T) {
+	provider := auth.NewFakeProvider(auth.WithClaims(auth.NewClaims("myuser", "myUser")))
+	srv := tokenServer(provider)
+	req := test.NewRequest(
+		test.WithMethod(web.MethodDelete),
+		test.WithURL("http://localhost/user/tokens/unknown"),
+		test.WithCookie("SESSION", encryptCookieValue(t, test.Base64Encoded(t, provider.Token("myuser")))),
+	)
+
+	resp, err := srv.Test(req)
+	defer test.Close(t, resp)
+
+	require.NoError(t, err)
+	assertErrorResponse(t, resp, http.StatusNotFound)
+}
+
+func tokenServer(provider auth.Provider) *web.Server {
+	oCfg := auth.Config{
+		SessionCookieName: "SESSION",
+	}
+	svc := auth.New(oCfg, auth.NewProviders(provider))
+	tokenSvc := auth.NewTokenService(memory.NewTokenRepository(), staticTimeSvc)
+	srv := web.NewTestServer()
+	cookieEncryptionKey = srv.Cfg.Cookie.EncryptionKey
+	srv.RegisterRoutes(func(r fiber.Router) {
+		loginapi.TokenRoutes(r.Group("/"), web.NewAuthMiddleware(oCfg, svc, auth.WithBearer(tokenSvc)), tokenSvc)
+	})
+
+	return srv
+}
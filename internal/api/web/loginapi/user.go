@@ -0,0 +1,84 @@
+package loginapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/konstantinfoerster/card-service-go/internal/aerrors"
+	"github.com/konstantinfoerster/card-service-go/internal/api/web"
+	"github.com/konstantinfoerster/card-service-go/internal/auth"
+)
+
+type UserService interface {
+	Update(ctx context.Context, id string, update auth.ProfileUpdate) (auth.User, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// UserRoutes All routes to manage the profile and account of the current user, they require a session.
+func UserRoutes(app fiber.Router, auth web.AuthMiddleware, cfg auth.Config, users UserService, svc Service,
+	tSvc TimeService) {
+	app.Patch("/user", auth.Required(), auth.RequireSession(), updateProfile(users))
+	app.Delete("/user", auth.Required(), auth.RequireSession(), deleteAccount(cfg, users, svc, tSvc))
+}
+
+func updateProfile(users UserService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		u, err := web.UserFromCtx(c)
+		if err != nil {
+			return aerrors.NewAuthorizationError(err, "unauthorized")
+		}
+
+		var body ProfileUpdate
+		if err = c.BodyParser(&body); err != nil {
+			return aerrors.NewInvalidInputError(err, "invalid-body", "invalid body format")
+		}
+
+		updated, err := users.Update(c.Context(), u.ID, auth.ProfileUpdate{DisplayName: body.DisplayName})
+		if err != nil {
+			return err
+		}
+
+		return web.RenderJSON(c, newProfile(updated))
+	}
+}
+
+// deleteAccount removes the user with all data and ends the current session.
+func deleteAccount(cfg auth.Config, users UserService, svc Service, timeSvc TimeService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		u, err := web.UserFromCtx(c)
+		if err != nil {
+			return aerrors.NewAuthorizationError(err, "unauthorized")
+		}
+
+		if err := users.Delete(c.Context(), u.ID); err != nil {
+			return err
+		}
+
+		if err := endSession(c, cfg, svc, timeSvc); err != nil {
+			return err
+		}
+
+		return c.SendStatus(web.StatusNoContent)
+	}
+}
+
+type ProfileUpdate struct {
+	DisplayName string `json:"displayName" form:"displayName"`
+}
+
+type Profile struct {
+	CreatedAt   time.Time `json:"createdAt"`
+	Provider    string    `json:"provider"`
+	Email       string    `json:"email,omitempty"`
+	DisplayName string    `json:"displayName"`
+}
+
+func newProfile(u auth.User) Profile {
+	return Profile{
+		CreatedAt:   u.CreatedAt,
+		Provider:    u.Provider,
+		Email:       u.Email,
+		DisplayName: u.DisplayName,
+	}
+}
@@ -0,0 +1,188 @@
+package loginapi_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/konstantinfoerster/card-service-go/internal/api/web"
+	"github.com/konstantinfoerster/card-service-go/internal/api/web/loginapi"
+	"github.com/konstantinfoerster/card-service-go/internal/auth"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/memory"
+	"github.com/konstantinfoerster/card-service-go/internal/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateProfile(t *testing.T) {
+	provider := auth.NewFakeProvider(auth.WithClaims(auth.NewClaims("myuser", "myuser@localhost")))
+	srv, _, _ := userServer(provider)
+	session := test.WithCookie("SESSION", encryptCookieValue(t, test.Base64Encoded(t, provider.Token("myuser"))))
+
+	req := test.NewRequest(
+		test.WithMethod(web.MethodPatch),
+		test.WithURL("http://localhost/user"),
+		session,
+		test.WithJSONBody(t, loginapi.ProfileUpdate{DisplayName: "My Name"}),
+	)
+	resp, err := srv.Test(req)
+	defer test.Close(t, resp)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	profile := test.FromJSON[loginapi.Profile](t, resp.Body)
+	assert.Equal(t, "My Name", profile.DisplayName)
+	assert.Equal(t, "myuser@localhost", profile.Email)
+	assert.Equal(t, "testProvider", profile.Provider)
+
+	req = test.NewRequest(
+		test.WithMethod(web.MethodGet),
+		test.WithURL("http://localhost/user"),
+		session,
+	)
+	resp, err = srv.Test(req)
+	defer test.Close(t, resp)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "My Name", test.FromJSON[web.ClientUser](t, resp.Body).Username)
+}
+
+func TestUpdateProfileInvalidName(t *testing.T) {
+	provider := auth.NewFakeProvider(auth.WithClaims(auth.NewClaims("myuser", "myuser@localhost")))
+	srv, _, _ := userServer(provider)
+	req := test.NewRequest(
+		test.WithMethod(web.MethodPatch),
+		test.WithURL("http://localhost/user"),
+		test.WithCookie("SESSION", encryptCookieValue(t, test.Base64Encoded(t, provider.Token("myuser")))),
+		test.WithJSONBody(t, loginapi.ProfileUpdate{DisplayName: " "}),
+	)
+
+	resp, err := srv.Test(req)
+	defer test.Close(t, resp)
+
+	require.NoError(t, err)
+	assertErrorResponse(t, resp, http.StatusBadRequest)
+}
+
+func TestDeleteAccount(t *testing.T) {
+	provider := auth.NewFakeProvider(auth.WithClaims(auth.NewClaims("myuser", "myuser@localhost")))
+	srv, users, _ := userServer(provider)
+	_, _, err := provider.ExchangeCode(context.Background(), "myuser")
+	require.NoError(t, err)
+	req := test.NewRequest(
+		test.WithMethod(web.MethodDelete),
+		test.WithURL("http://localhost/user"),
+		test.WithCookie("SESSION", encryptCookieValue(t, test.Base64Encoded(t, provider.Token("myuser")))),
+	)
+
+	resp, err := srv.Test(req)
+	defer test.Close(t, resp)
+
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	_, err = users.Get(context.Background(), "myuser")
+	require.Error(t, err)
+	require.Len(t, resp.Cookies(), 1)
+	assert.Equal(t, "SESSION", resp.Cookies()[0].Name)
+	assert.Equal(t, "invalid", decryptCookieValue(t, resp.Cookies()[0].Value))
+}
+
+func TestManageAccountRequiresSession(t *testing.T) {
+	cases := []struct {
+		name   string
+		method string
+		body   any
+	}{
+		{
+			name:   "update profile",
+			method: web.MethodPatch,
+			body:   loginapi.ProfileUpdate{DisplayName: "Token"},
+		},
+		{
+			name:   "delete account",
+			method: web.MethodDelete,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := auth.NewFakeProvider(auth.WithClaims(auth.NewClaims("myuser", "myuser@localhost")))
+			srv, _, tokens := userServer(provider)
+			claims := auth.NewClaims("myuser", "myuser@localhost")
+			_, plain, err := tokens.Create(context.Background(), claims, "script", []auth.Scope{auth.ScopeWrite}, 0)
+			require.NoError(t, err)
+			req := test.NewRequest(
+				test.WithMethod(tc.method),
+				test.WithURL("http://localhost/user"),
+				test.WithHeader(map[string]string{fiber.HeaderAuthorization: "Bearer " + plain}),
+				test.WithJSONBody(t, tc.body),
+			)
+
+			resp, err := srv.Test(req)
+			defer test.Close(t, resp)
+
+			require.NoError(t, err)
+			assertErrorResponse(t, resp, http.StatusForbidden)
+		})
+	}
+}
+
+func TestLinkProvider(t *testing.T) {
+	provider := auth.NewFakeProvider(
+		auth.WithClaims(auth.NewClaims("myuser", "myuser@localhost")),
+		auth.WithClaims(auth.NewClaims("otheruser", "other@localhost")),
+	)
+	srv, users, _ := userServer(provider)
+
+	req := test.NewRequest(
+		test.WithMethod(web.MethodGet),
+		test.WithURL("http://localhost/user/link/testProvider"),
+		test.WithCookie("SESSION", encryptCookieValue(t, test.Base64Encoded(t, provider.Token("myuser")))),
+	)
+	resp, err := srv.Test(req)
+	defer test.Close(t, resp)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+	cookies := map[string]string{}
+	for _, c := range resp.Cookies() {
+		cookies[c.Name] = decryptCookieValue(t, c.Value)
+	}
+	require.Equal(t, "myuser", cookies["LINK_USER"])
+
+	req = test.NewRequest(
+		test.WithMethod(web.MethodGet),
+		test.WithURLf("http://localhost/login/testProvider/callback?code=otheruser&state=%s", cookies["TOKEN_STATE"]),
+		test.WithCookie("TOKEN_STATE", encryptCookieValue(t, cookies["TOKEN_STATE"])),
+		test.WithCookie("LINK_USER", encryptCookieValue(t, cookies["LINK_USER"])),
+	)
+	resp, err = srv.Test(req)
+	defer test.Close(t, resp)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	for _, c := range resp.Cookies() {
+		assert.NotEqual(t, "SESSION", c.Name, "expected the session to stay unchanged")
+	}
+
+	resolved, err := users.Resolve(context.Background(),
+		auth.Claims{ID: "otheruser", Provider: "testProvider"})
+	require.NoError(t, err)
+	assert.Equal(t, "myuser", resolved.ID)
+}
+
+func userServer(provider auth.Provider) (*web.Server, *auth.UserService, *auth.TokenService) {
+	oCfg := auth.Config{
+		SessionCookieName: "SESSION",
+	}
+	svc := auth.New(oCfg, auth.NewProviders(provider))
+	users := auth.NewUserService(memory.NewUserRepository(), staticTimeSvc)
+	tokenSvc := auth.NewTokenService(memory.NewTokenRepository(), staticTimeSvc)
+	srv := web.NewTestServer()
+	cookieEncryptionKey = srv.Cfg.Cookie.EncryptionKey
+	srv.RegisterRoutes(func(r fiber.Router) {
+		authMiddleware := web.NewAuthMiddleware(oCfg, svc, auth.WithBearer(tokenSvc), auth.WithProfile(users))
+		loginapi.Routes(r.Group("/"), authMiddleware, oCfg, svc, users, staticTimeSvc)
+		loginapi.UserRoutes(r.Group("/"), authMiddleware, oCfg, users, svc, staticTimeSvc)
+	})
+
+	return srv, users, tokenSvc
+}
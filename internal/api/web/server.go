@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os/signal"
 	"path"
@@ -79,9 +80,11 @@ func NewServer(cfg Config) *Server {
 		},
 	})
 
-	// FIXME: make body size configurable
+	// leave some room for the multipart overhead and other form fields of an upload
+	bodyOverhead := int64(1024 * 1024)
 	app := fiber.New(fiber.Config{
-		Views: engine,
+		Views:     engine,
+		BodyLimit: int(cfg.Upload.MaxFileSize() + bodyOverhead),
 		// FIXME: error handler does not handle text/html requests
 		ErrorHandler: RespondWithProblemJSON,
 	})
@@ -126,6 +129,12 @@ func (s *Server) Test(req *http.Request) (*http.Response, error) {
 	return s.app.Test(req, noTimeout)
 }
 
+// Serve handles the connections of the listener until it is closed, used by tests that need a real
+// connection, e.g. for websockets.
+func (s *Server) Serve(ln net.Listener) error {
+	return s.app.Listener(ln)
+}
+
 func (s *Server) Run(ctx context.Context) error {
 	addr := s.Cfg.Addr()
 
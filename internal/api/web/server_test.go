@@ -32,6 +32,21 @@ func TestNewServerErrorHandler(t *testing.T) {
 			appErr:     aerrors.NewAuthorizationError(assert.AnError, "myKey"),
 			statusCode: web.StatusUnauthorized,
 		},
+		{
+			name:       "Forbidden",
+			appErr:     aerrors.NewForbiddenError(assert.AnError, "myKey"),
+			statusCode: web.StatusForbidden,
+		},
+		{
+			name:       "Not found",
+			appErr:     aerrors.NewNotFoundError(assert.AnError, "myKey"),
+			statusCode: web.StatusNotFound,
+		},
+		{
+			name:       "Unavailable",
+			appErr:     aerrors.NewUnavailableError(assert.AnError, "myKey", "myMsg"),
+			statusCode: web.StatusServiceUnavailable,
+		},
 		{
 			name:       "Unknown error",
 			appErr:     aerrors.NewUnknownError(assert.AnError, "myKey"),
@@ -5,8 +5,10 @@ import "time"
 type Config struct {
 	Provider          map[string]ProviderCfg `yaml:"provider"`
 	SessionCookieName string                 `yaml:"session_cookie_name"`
-	StateCookieAge    time.Duration          `yaml:"state_cookie_age"`
-	ClientTimeout     time.Duration          `yaml:"client_timeout"`
+	// Admins emails of users that get the admin role
+	Admins         []string      `yaml:"admins"`
+	StateCookieAge time.Duration `yaml:"state_cookie_age"`
+	ClientTimeout  time.Duration `yaml:"client_timeout"`
 }
 
 type ProviderCfg struct {
@@ -17,4 +19,8 @@ type ProviderCfg struct {
 	ClientID    string `yaml:"client_id"`
 	Secret      string `yaml:"secret"`
 	Scope       string `yaml:"scope"`
+	// SessionAge how long a session of the local provider is valid.
+	SessionAge time.Duration `yaml:"session_age"`
+	// Registration allows to register new accounts, only supported by the local provider.
+	Registration bool `yaml:"registration"`
 }
@@ -0,0 +1,367 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/konstantinfoerster/card-service-go/internal/aerrors"
+)
+
+var (
+	ErrAccountNotFound      = errors.New("account not found")
+	ErrAccountExists        = errors.New("account already exists")
+	ErrInvalidCredentials   = errors.New("invalid credentials")
+	ErrInvalidUsername      = errors.New("invalid username")
+	ErrInvalidPassword      = errors.New("invalid password")
+	ErrRegistrationDisabled = errors.New("registration disabled")
+	errInvalidSignature     = errors.New("invalid signature")
+)
+
+// LocalProviderName the name of the provider that authenticates against local accounts.
+const LocalProviderName = "local"
+
+const (
+	minLocalSecretLen = 32
+	minPasswordLen    = 8
+	maxPasswordLen    = 128
+	localCodeAge      = time.Minute
+	// DefaultLocalSessionAge is used when the local provider has no session age configured.
+	DefaultLocalSessionAge = 24 * time.Hour
+	purposeCode            = "code"
+	purposeSession         = "session"
+)
+
+var usernamePattern = regexp.MustCompile(`^[a-z0-9._-]{3,50}$`) //nolint:gochecknoglobals
+
+// LocalAccount a user that logs in with username and password instead of an external provider.
+type LocalAccount struct {
+	CreatedAt    time.Time
+	ID           string
+	Username     string
+	PasswordHash string
+}
+
+type AccountRepository interface {
+	// Create persists a new account, returns ErrAccountExists if the username is already taken.
+	Create(ctx context.Context, account LocalAccount) error
+	// FindByUsername returns ErrAccountNotFound if no account with that username exists.
+	FindByUsername(ctx context.Context, username string) (LocalAccount, error)
+	// FindByID returns ErrAccountNotFound if no account with that id exists.
+	FindByID(ctx context.Context, id string) (LocalAccount, error)
+	// UpdatePassword replaces the password hash, returns ErrAccountNotFound if no account with that id exists.
+	UpdatePassword(ctx context.Context, id string, hash string) error
+}
+
+// LocalProvider authenticates local accounts. It works without any external service, codes and
+// sessions are stateless values signed with the configured secret.
+type LocalProvider struct {
+	accounts     AccountRepository
+	timeSvc      TimeService
+	secret       []byte
+	authURL      string
+	redirectURI  string
+	sessionAge   time.Duration
+	registration bool
+}
+
+func NewLocalProvider(cfg ProviderCfg, accounts AccountRepository, timeSvc TimeService) (*LocalProvider, error) {
+	if accounts == nil {
+		return nil, fmt.Errorf("provider %s, missing account repository, %w", LocalProviderName, ErrProviderInvalidConfig)
+	}
+
+	if len(cfg.Secret) < minLocalSecretLen {
+		return nil, fmt.Errorf("provider %s, secret must have at least %d characters, %w",
+			LocalProviderName, minLocalSecretLen, ErrProviderInvalidConfig)
+	}
+
+	p := &LocalProvider{
+		accounts:     accounts,
+		timeSvc:      timeSvc,
+		secret:       []byte(cfg.Secret),
+		authURL:      "/api/v1/login/local/form",
+		redirectURI:  "/api/v1/login/local/callback",
+		sessionAge:   DefaultLocalSessionAge,
+		registration: cfg.Registration,
+	}
+	if cfg.AuthURL != "" {
+		p.authURL = cfg.AuthURL
+	}
+	if cfg.RedirectURI != "" {
+		p.redirectURI = cfg.RedirectURI
+	}
+	if cfg.SessionAge > 0 {
+		p.sessionAge = cfg.SessionAge
+	}
+
+	return p, nil
+}
+
+func (p *LocalProvider) GetName() string {
+	return LocalProviderName
+}
+
+// GetAuthURL returns the url of the login form.
+func (p *LocalProvider) GetAuthURL(state string) string {
+	return fmt.Sprintf("%s?state=%s", p.authURL, url.QueryEscape(state))
+}
+
+func (p *LocalProvider) GenerateState() State {
+	return State{ID: uuid.New().String()}
+}
+
+// RegistrationEnabled returns true if new accounts can be registered.
+func (p *LocalProvider) RegistrationEnabled() bool {
+	return p.registration
+}
+
+func (p *LocalProvider) ExchangeCode(ctx context.Context, authCode string) (Claims, *JWT, error) {
+	account, err := p.verify(ctx, authCode, purposeCode)
+	if err != nil {
+		return Claims{}, nil, errors.Join(err, ErrProviderCodeExchange)
+	}
+
+	session, err := p.sign(account, purposeSession, p.sessionAge)
+	if err != nil {
+		return Claims{}, nil, errors.Join(err, ErrProviderCodeExchange)
+	}
+
+	token := &JWT{
+		AccessToken: session,
+		Type:        "Bearer",
+		Provider:    LocalProviderName,
+		ExpiresIn:   int64(p.sessionAge.Seconds()),
+	}
+
+	return localClaims(account), token, nil
+}
+
+func (p *LocalProvider) ValidateToken(ctx context.Context, token *JWT) (Claims, error) {
+	if token == nil {
+		return Claims{}, errors.Join(errEmptyToken, ErrProviderValidateToken)
+	}
+
+	account, err := p.verify(ctx, token.AccessToken, purposeSession)
+	if err != nil {
+		return Claims{}, errors.Join(err, ErrProviderValidateToken)
+	}
+
+	return localClaims(account), nil
+}
+
+// RevokeToken does nothing, sessions are stateless and end when the session cookie is removed.
+// Changing the password invalidates all existing sessions.
+func (p *LocalProvider) RevokeToken(_ context.Context, _ *JWT) error {
+	return nil
+}
+
+// Login checks the credentials and returns the callback url with a short-lived code
+// that can be exchanged for a session.
+func (p *LocalProvider) Login(ctx context.Context, username, password, state string) (RedirectURL, error) {
+	account, err := p.accounts.FindByUsername(ctx, normalizeUsername(username))
+	if err != nil {
+		if !errors.Is(err, ErrAccountNotFound) {
+			return RedirectURL{}, aerrors.NewUnknownError(err, "unable-to-load-account")
+		}
+
+		// prevent guessing usernames by the response time
+		_, _ = HashPassword(password)
+
+		return RedirectURL{}, aerrors.NewAuthorizationError(ErrInvalidCredentials, "invalid-credentials")
+	}
+
+	ok, err := VerifyPassword(password, account.PasswordHash)
+	if err != nil {
+		return RedirectURL{}, aerrors.NewUnknownError(err, "unable-to-verify-password")
+	}
+	if !ok {
+		return RedirectURL{}, aerrors.NewAuthorizationError(ErrInvalidCredentials, "invalid-credentials")
+	}
+
+	code, err := p.sign(account, purposeCode, localCodeAge)
+	if err != nil {
+		return RedirectURL{}, aerrors.NewUnknownError(err, "unable-to-sign-code")
+	}
+
+	return RedirectURL{
+		URL:   fmt.Sprintf("%s?code=%s&state=%s", p.redirectURI, url.QueryEscape(code), url.QueryEscape(state)),
+		State: state,
+	}, nil
+}
+
+// Register creates a new local account if the registration is enabled.
+func (p *LocalProvider) Register(ctx context.Context, username, password string) (LocalAccount, error) {
+	if !p.registration {
+		return LocalAccount{}, aerrors.NewForbiddenError(ErrRegistrationDisabled, "registration-disabled")
+	}
+
+	username = normalizeUsername(username)
+	if !usernamePattern.MatchString(username) {
+		return LocalAccount{}, aerrors.NewInvalidInputError(ErrInvalidUsername, "invalid-username",
+			"username must have 3 to 50 characters and only contain letters, digits, '.', '_' or '-'")
+	}
+
+	hash, err := p.hashPassword(password)
+	if err != nil {
+		return LocalAccount{}, err
+	}
+
+	account := LocalAccount{
+		ID:           uuid.New().String(),
+		Username:     username,
+		PasswordHash: hash,
+		CreatedAt:    p.timeSvc.Now().UTC(),
+	}
+	if err := p.accounts.Create(ctx, account); err != nil {
+		if errors.Is(err, ErrAccountExists) {
+			return LocalAccount{}, aerrors.NewInvalidInputError(err, "account-exists", "username already taken")
+		}
+
+		return LocalAccount{}, aerrors.NewUnknownError(err, "unable-to-create-account")
+	}
+
+	return account, nil
+}
+
+// ChangePassword replaces the password of the account if the current password matches.
+func (p *LocalProvider) ChangePassword(ctx context.Context, id, current, password string) error {
+	account, err := p.accounts.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrAccountNotFound) {
+			return aerrors.NewNotFoundError(err, "account-not-found")
+		}
+
+		return aerrors.NewUnknownError(err, "unable-to-load-account")
+	}
+
+	ok, err := VerifyPassword(current, account.PasswordHash)
+	if err != nil {
+		return aerrors.NewUnknownError(err, "unable-to-verify-password")
+	}
+	if !ok {
+		return aerrors.NewAuthorizationError(ErrInvalidCredentials, "invalid-credentials")
+	}
+
+	hash, err := p.hashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	if err := p.accounts.UpdatePassword(ctx, account.ID, hash); err != nil {
+		return aerrors.NewUnknownError(err, "unable-to-update-password")
+	}
+
+	return nil
+}
+
+func (p *LocalProvider) hashPassword(password string) (string, error) {
+	if l := len(password); l < minPasswordLen || l > maxPasswordLen {
+		return "", aerrors.NewInvalidInputError(ErrInvalidPassword, "invalid-password",
+			fmt.Sprintf("password must have %d to %d characters", minPasswordLen, maxPasswordLen))
+	}
+
+	hash, err := HashPassword(password)
+	if err != nil {
+		return "", aerrors.NewUnknownError(err, "unable-to-hash-password")
+	}
+
+	return hash, nil
+}
+
+// signedValue the content of a code or session. The version is derived from the password hash,
+// which invalidates all values once the password changes.
+type signedValue struct {
+	Subject   string `json:"sub"`
+	Purpose   string `json:"purpose"`
+	Version   string `json:"ver"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+func (p *LocalProvider) sign(account LocalAccount, purpose string, age time.Duration) (string, error) {
+	raw, err := json.Marshal(signedValue{
+		Subject:   account.ID,
+		Purpose:   purpose,
+		Version:   passwordVersion(account.PasswordHash),
+		ExpiresAt: p.timeSvc.Now().Add(age).Unix(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode signed value, %w", err)
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(raw)
+
+	return payload + "." + base64.RawURLEncoding.EncodeToString(p.mac(payload)), nil
+}
+
+func (p *LocalProvider) verify(ctx context.Context, value, purpose string) (LocalAccount, error) {
+	payload, signature, ok := strings.Cut(value, ".")
+	if !ok {
+		return LocalAccount{}, errInvalidSignature
+	}
+
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, p.mac(payload)) {
+		return LocalAccount{}, errInvalidSignature
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return LocalAccount{}, errors.Join(err, errInvalidEncoding)
+	}
+
+	var v signedValue
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return LocalAccount{}, errors.Join(err, errInvalidValueContent)
+	}
+
+	if v.Purpose != purpose {
+		return LocalAccount{}, fmt.Errorf("unexpected purpose %s, %w", v.Purpose, errInvalidValue)
+	}
+
+	if p.timeSvc.Now().Unix() >= v.ExpiresAt {
+		return LocalAccount{}, ErrTokenExpired
+	}
+
+	account, err := p.accounts.FindByID(ctx, v.Subject)
+	if err != nil {
+		return LocalAccount{}, err
+	}
+
+	if !hmac.Equal([]byte(v.Version), []byte(passwordVersion(account.PasswordHash))) {
+		return LocalAccount{}, fmt.Errorf("password changed, %w", ErrTokenExpired)
+	}
+
+	return account, nil
+}
+
+func (p *LocalProvider) mac(payload string) []byte {
+	h := hmac.New(sha256.New, p.secret)
+	h.Write([]byte(payload))
+
+	return h.Sum(nil)
+}
+
+func passwordVersion(hash string) string {
+	sum := sha256.Sum256([]byte(hash))
+
+	return hex.EncodeToString(sum[:8])
+}
+
+func normalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// localClaims local accounts have no verified email, the username is only used for display.
+func localClaims(a LocalAccount) Claims {
+	return Claims{ID: a.ID, Name: a.Username}
+}
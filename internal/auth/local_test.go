@@ -0,0 +1,235 @@
+package auth_test
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/konstantinfoerster/card-service-go/internal/aerrors"
+	"github.com/konstantinfoerster/card-service-go/internal/auth"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const localSecret = "0123456789abcdef0123456789abcdef"
+
+func TestHashAndVerifyPassword(t *testing.T) {
+	hash, err := auth.HashPassword("secret-password")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$"))
+
+	other, err := auth.HashPassword("secret-password")
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, other, "expected a random salt")
+
+	ok, err := auth.VerifyPassword("secret-password", hash)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = auth.VerifyPassword("wrong-password", hash)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = auth.VerifyPassword("secret-password", "$2a$10$invalid")
+	require.ErrorIs(t, err, auth.ErrInvalidPasswordHash)
+}
+
+func TestLocalProviderLogin(t *testing.T) {
+	ctx := context.Background()
+	p := localProvider(t, auth.NewFakeTimeService(time.Now()))
+	account, err := p.Register(ctx, " MyUser ", "secret-password")
+	require.NoError(t, err)
+	assert.Equal(t, "myuser", account.Username)
+
+	redirect, err := p.Login(ctx, "myuser", "secret-password", "state-0")
+	require.NoError(t, err)
+	callback, err := url.Parse(redirect.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "/api/v1/login/local/callback", callback.Path)
+	assert.Equal(t, "state-0", callback.Query().Get("state"))
+
+	claims, token, err := p.ExchangeCode(ctx, callback.Query().Get("code"))
+	require.NoError(t, err)
+	assert.Equal(t, auth.Claims{ID: account.ID, Name: "myuser"}, claims)
+	assert.Equal(t, auth.LocalProviderName, token.Provider)
+	assert.Equal(t, int64(auth.DefaultLocalSessionAge.Seconds()), token.ExpiresIn)
+
+	validated, err := p.ValidateToken(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, claims, validated)
+
+	_, _, err = p.ExchangeCode(ctx, token.AccessToken)
+	require.ErrorIs(t, err, auth.ErrProviderCodeExchange, "session must not be usable as code")
+}
+
+func TestLocalProviderInvalidCredentials(t *testing.T) {
+	ctx := context.Background()
+	p := localProvider(t, auth.NewTimeService())
+	_, err := p.Register(ctx, "myuser", "secret-password")
+	require.NoError(t, err)
+
+	cases := []struct {
+		name     string
+		username string
+		password string
+	}{
+		{
+			name:     "wrong password",
+			username: "myuser",
+			password: "wrong-password",
+		},
+		{
+			name:     "unknown user",
+			username: "unknown",
+			password: "secret-password",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := p.Login(ctx, tc.username, tc.password, "state-0")
+
+			var appErr aerrors.AppError
+			require.ErrorAs(t, err, &appErr)
+			assert.Equal(t, aerrors.ErrAuthorization, appErr.ErrorType)
+			require.ErrorIs(t, err, auth.ErrInvalidCredentials)
+		})
+	}
+}
+
+func TestLocalProviderRegisterInvalidInput(t *testing.T) {
+	ctx := context.Background()
+	p := localProvider(t, auth.NewTimeService())
+	_, err := p.Register(ctx, "taken", "secret-password")
+	require.NoError(t, err)
+
+	cases := []struct {
+		name     string
+		username string
+		password string
+	}{
+		{
+			name:     "username too short",
+			username: "ab",
+			password: "secret-password",
+		},
+		{
+			name:     "username with invalid characters",
+			username: "my user",
+			password: "secret-password",
+		},
+		{
+			name:     "password too short",
+			username: "myuser",
+			password: "short",
+		},
+		{
+			name:     "username taken",
+			username: "Taken",
+			password: "secret-password",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := p.Register(ctx, tc.username, tc.password)
+
+			var appErr aerrors.AppError
+			require.ErrorAs(t, err, &appErr)
+			assert.Equal(t, aerrors.ErrInvalidInput, appErr.ErrorType)
+		})
+	}
+}
+
+func TestLocalProviderRegistrationDisabled(t *testing.T) {
+	p, err := auth.NewLocalProvider(auth.ProviderCfg{Secret: localSecret}, memory.NewAccountRepository(),
+		auth.NewTimeService())
+	require.NoError(t, err)
+
+	_, err = p.Register(context.Background(), "myuser", "secret-password")
+
+	var appErr aerrors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, aerrors.ErrForbidden, appErr.ErrorType)
+}
+
+func TestLocalProviderChangePassword(t *testing.T) {
+	ctx := context.Background()
+	p := localProvider(t, auth.NewTimeService())
+	account, err := p.Register(ctx, "myuser", "secret-password")
+	require.NoError(t, err)
+	token := login(t, p, "myuser", "secret-password")
+
+	err = p.ChangePassword(ctx, account.ID, "wrong-password", "new-password")
+	require.ErrorIs(t, err, auth.ErrInvalidCredentials)
+
+	require.NoError(t, p.ChangePassword(ctx, account.ID, "secret-password", "new-password"))
+
+	_, err = p.ValidateToken(ctx, token)
+	require.ErrorIs(t, err, auth.ErrTokenExpired, "expected old sessions to end")
+	_, err = p.Login(ctx, "myuser", "secret-password", "state-0")
+	require.ErrorIs(t, err, auth.ErrInvalidCredentials)
+	login(t, p, "myuser", "new-password")
+}
+
+func TestLocalProviderSessionExpired(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	accounts := memory.NewAccountRepository()
+	cfg := auth.ProviderCfg{Secret: localSecret, Registration: true, SessionAge: time.Hour}
+	p, err := auth.NewLocalProvider(cfg, accounts, auth.NewFakeTimeService(now))
+	require.NoError(t, err)
+	_, err = p.Register(ctx, "myuser", "secret-password")
+	require.NoError(t, err)
+	token := login(t, p, "myuser", "secret-password")
+
+	later, err := auth.NewLocalProvider(cfg, accounts, auth.NewFakeTimeService(now.Add(time.Hour)))
+	require.NoError(t, err)
+	_, err = later.ValidateToken(ctx, token)
+
+	require.ErrorIs(t, err, auth.ErrTokenExpired)
+}
+
+func TestLocalProviderRejectsForeignSignature(t *testing.T) {
+	ctx := context.Background()
+	accounts := memory.NewAccountRepository()
+	p, err := auth.NewLocalProvider(auth.ProviderCfg{Secret: localSecret, Registration: true}, accounts,
+		auth.NewTimeService())
+	require.NoError(t, err)
+	_, err = p.Register(ctx, "myuser", "secret-password")
+	require.NoError(t, err)
+	token := login(t, p, "myuser", "secret-password")
+
+	other, err := auth.NewLocalProvider(auth.ProviderCfg{Secret: strings.Repeat("x", 32)}, accounts,
+		auth.NewTimeService())
+	require.NoError(t, err)
+	_, err = other.ValidateToken(ctx, token)
+
+	require.ErrorIs(t, err, auth.ErrProviderValidateToken)
+}
+
+func localProvider(t *testing.T, timeSvc auth.TimeService) *auth.LocalProvider {
+	t.Helper()
+
+	cfg := auth.ProviderCfg{Secret: localSecret, Registration: true}
+	p, err := auth.NewLocalProvider(cfg, memory.NewAccountRepository(), timeSvc)
+	require.NoError(t, err)
+
+	return p
+}
+
+func login(t *testing.T, p *auth.LocalProvider, username, password string) *auth.JWT {
+	t.Helper()
+
+	redirect, err := p.Login(context.Background(), username, password, "state-0")
+	require.NoError(t, err)
+	callback, err := url.Parse(redirect.URL)
+	require.NoError(t, err)
+	_, token, err := p.ExchangeCode(context.Background(), callback.Query().Get("code"))
+	require.NoError(t, err)
+
+	return token
+}
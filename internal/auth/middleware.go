@@ -3,6 +3,7 @@ package auth
 import (
 	"context"
 	"errors"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/konstantinfoerster/card-service-go/internal/aerrors"
@@ -12,12 +13,28 @@ var (
 	ErrNoClaimsInContext = errors.New("no claims in context")
 	ErrUnauthorized      = errors.New("unauthorized")
 	ErrInvalidSession    = errors.New("invalid or expired session")
+	ErrInsufficientScope = errors.New("insufficient scope")
 )
 
 type Service interface {
 	AuthInfo(ctx context.Context, provider string, token *JWT) (Claims, error)
 }
 
+// RoleResolver adds the user roles to the claims.
+type RoleResolver interface {
+	Resolve(ctx context.Context, c Claims) (Claims, error)
+}
+
+// ProfileResolver adds the stored user profile to the claims.
+type ProfileResolver interface {
+	Resolve(ctx context.Context, c Claims) (Claims, error)
+}
+
+// TokenVerifier verifies personal access tokens.
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (Claims, error)
+}
+
 const ClaimsContextKey = "claims"
 
 func ClaimsFromCtx(ctx *fiber.Ctx) (Claims, error) {
@@ -32,6 +49,11 @@ func ClaimsFromCtx(ctx *fiber.Ctx) (Claims, error) {
 type MiddlewareConfig struct {
 	// Extractor defines how the token claims are extracted from the request
 	Extractor func(*fiber.Ctx, string) (Claims, error)
+	// BearerExtractor defines how the claims are extracted from an authorization bearer token,
+	// bearer tokens are ignored if not set
+	BearerExtractor func(*fiber.Ctx, string) (Claims, error)
+	// Enrich runs after valid claims are found and before Authorized, can be used to add additional data
+	Enrich func(*fiber.Ctx, Claims) (Claims, error)
 	// Authorized runs after valid claims are found
 	Authorized func(*fiber.Ctx, Claims)
 	// Key name of the session cookie
@@ -82,6 +104,45 @@ func WithAuthorized(fn func(*fiber.Ctx, Claims)) func(*MiddlewareConfig) {
 		c.Authorized = fn
 	}
 }
+
+// WithBearer accepts personal access tokens provided via Authorization: Bearer header.
+func WithBearer(verifier TokenVerifier) func(*MiddlewareConfig) {
+	return func(c *MiddlewareConfig) {
+		c.BearerExtractor = func(ctx *fiber.Ctx, token string) (Claims, error) {
+			return verifier.Verify(ctx.Context(), token)
+		}
+	}
+}
+
+// WithRoles resolves the roles of the authenticated user.
+func WithRoles(resolver RoleResolver) func(*MiddlewareConfig) {
+	return func(c *MiddlewareConfig) {
+		c.Enrich = chainEnrich(c.Enrich, resolver.Resolve)
+	}
+}
+
+// WithProfile resolves the stored profile of the authenticated user.
+func WithProfile(resolver ProfileResolver) func(*MiddlewareConfig) {
+	return func(c *MiddlewareConfig) {
+		c.Enrich = chainEnrich(c.Enrich, resolver.Resolve)
+	}
+}
+
+// chainEnrich runs next after the already configured enrich function.
+func chainEnrich(current func(*fiber.Ctx, Claims) (Claims, error),
+	next func(context.Context, Claims) (Claims, error)) func(*fiber.Ctx, Claims) (Claims, error) {
+	return func(ctx *fiber.Ctx, claims Claims) (Claims, error) {
+		if current != nil {
+			var err error
+			if claims, err = current(ctx, claims); err != nil {
+				return Claims{}, err
+			}
+		}
+
+		return next(ctx.Context(), claims)
+	}
+}
+
 func AllowUnauthorized() func(*MiddlewareConfig) {
 	return func(c *MiddlewareConfig) {
 		c.AllowEmptyCookie = true
@@ -103,17 +164,24 @@ func newTokenExtractHandler(config ...MiddlewareConfig) fiber.Handler {
 	}
 
 	return func(c *fiber.Ctx) error {
-		// Extract and verify key
-		cookieValue := c.Cookies(cfg.Key)
-		if cookieValue == "" {
-			if cfg.AllowEmptyCookie {
-				return c.Next()
+		var value Claims
+		var err error
+		if bearer := bearerToken(c); bearer != "" && cfg.BearerExtractor != nil {
+			value, err = cfg.BearerExtractor(c, bearer)
+			value.Bearer = true
+		} else {
+			// Extract and verify key
+			cookieValue := c.Cookies(cfg.Key)
+			if cookieValue == "" {
+				if cfg.AllowEmptyCookie {
+					return c.Next()
+				}
+
+				return aerrors.NewAuthorizationError(ErrUnauthorized, "unauthorized")
 			}
 
-			return aerrors.NewAuthorizationError(ErrUnauthorized, "unauthorized")
+			value, err = cfg.Extractor(c, cookieValue)
 		}
-
-		value, err := cfg.Extractor(c, cookieValue)
 		if err != nil {
 			return aerrors.NewAuthorizationError(err, "unauthorized")
 		}
@@ -122,8 +190,28 @@ func newTokenExtractHandler(config ...MiddlewareConfig) fiber.Handler {
 			return aerrors.NewAuthorizationError(ErrInvalidSession, "unauthorized")
 		}
 
+		if !value.Allows(ScopeFor(c.Method())) {
+			return aerrors.NewForbiddenError(ErrInsufficientScope, "insufficient-scope")
+		}
+
+		if cfg.Enrich != nil {
+			if value, err = cfg.Enrich(c, value); err != nil {
+				return err
+			}
+		}
+
 		cfg.Authorized(c, value)
 
 		return c.Next()
 	}
 }
+
+func bearerToken(c *fiber.Ctx) string {
+	prefix := "bearer "
+	header := strings.TrimSpace(c.Get(fiber.HeaderAuthorization))
+	if len(header) <= len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return ""
+	}
+
+	return strings.TrimSpace(header[len(prefix):])
+}
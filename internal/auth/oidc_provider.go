@@ -8,6 +8,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
 
 	"github.com/google/uuid"
@@ -67,6 +68,24 @@ func (pp Providers) Find(key string) (Provider, error) {
 	return nil, fmt.Errorf("%s not found, %w", key, ErrProviderUnsupported)
 }
 
+// Names returns the sorted names of all provider.
+func (pp Providers) Names() []string {
+	names := make([]string, 0, len(pp.provider))
+	for _, p := range pp.provider {
+		names = append(names, p.GetName())
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// Local returns the local provider if configured.
+func (pp Providers) Local() (*LocalProvider, bool) {
+	p, ok := pp.provider[LocalProviderName].(*LocalProvider)
+
+	return p, ok
+}
+
 func TestProvider(cfg ProviderCfg, client *http.Client) OIDCProvider {
 	return OIDCProvider{
 		name:        "test",
@@ -84,7 +103,9 @@ func TestProvider(cfg ProviderCfg, client *http.Client) OIDCProvider {
 	}
 }
 
-func FromConfiguration(cfg Config) (Providers, error) {
+// FromConfiguration creates all configured provider. The accounts are only required
+// if the local provider is configured.
+func FromConfiguration(cfg Config, accounts AccountRepository) (Providers, error) {
 	client := &http.Client{
 		Timeout: cfg.ClientTimeout,
 	}
@@ -107,6 +128,13 @@ func FromConfiguration(cfg Config) (Providers, error) {
 				return Providers{}, err
 			}
 
+			pp = append(pp, p)
+		case LocalProviderName:
+			p, err := NewLocalProvider(v, accounts, NewTimeService())
+			if err != nil {
+				return Providers{}, err
+			}
+
 			pp = append(pp, p)
 		default:
 			return Providers{}, fmt.Errorf("unsupported provder %s, %w", k, ErrProviderInvalidConfig)
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/konstantinfoerster/card-service-go/internal/auth"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/memory"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -18,7 +19,7 @@ func TestFromConfiguration(t *testing.T) {
 		},
 	}
 
-	provider, err := auth.FromConfiguration(cfg)
+	provider, err := auth.FromConfiguration(cfg, nil)
 	require.NoError(t, err)
 	p, err := provider.Find("google")
 
@@ -26,6 +27,26 @@ func TestFromConfiguration(t *testing.T) {
 	assert.Equal(t, "google", p.GetName())
 }
 
+func TestFromConfigurationLocal(t *testing.T) {
+	cfg := auth.Config{
+		Provider: map[string]auth.ProviderCfg{
+			"local": {
+				Secret: "0123456789abcdef0123456789abcdef",
+			},
+		},
+	}
+
+	provider, err := auth.FromConfiguration(cfg, memory.NewAccountRepository())
+	require.NoError(t, err)
+	p, err := provider.Find("local")
+	require.NoError(t, err)
+	local, ok := provider.Local()
+
+	require.True(t, ok)
+	assert.Equal(t, p, local)
+	assert.Equal(t, []string{"local"}, provider.Names())
+}
+
 func TestFromConfigurationMisconfigured(t *testing.T) {
 	cases := []struct {
 		name string
@@ -47,6 +68,26 @@ func TestFromConfigurationMisconfigured(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "local without accounts",
+			cfg: auth.Config{
+				Provider: map[string]auth.ProviderCfg{
+					"local": {
+						Secret: "0123456789abcdef0123456789abcdef",
+					},
+				},
+			},
+		},
+		{
+			name: "local secret too short",
+			cfg: auth.Config{
+				Provider: map[string]auth.ProviderCfg{
+					"local": {
+						Secret: "short",
+					},
+				},
+			},
+		},
 		{
 			name: "no secret",
 			cfg: auth.Config{
@@ -61,7 +102,7 @@ func TestFromConfigurationMisconfigured(t *testing.T) {
 
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			_, err := auth.FromConfiguration(tc.cfg)
+			_, err := auth.FromConfiguration(tc.cfg, nil)
 
 			require.Error(t, err)
 		})
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/konstantinfoerster/card-service-go/internal/aerrors"
+)
+
+// Role grants access to a group of actions.
+type Role string
+
+const (
+	// RoleUser every authenticated user has that role.
+	RoleUser Role = "user"
+	// RoleAdmin allows administrative actions.
+	RoleAdmin Role = "admin"
+)
+
+// ParseRole converts the given value into a role, returns false if the value is not a known role.
+func ParseRole(value string) (Role, bool) {
+	r := Role(strings.ToLower(strings.TrimSpace(value)))
+	switch r {
+	case RoleUser, RoleAdmin:
+		return r, true
+	default:
+		return "", false
+	}
+}
+
+type RoleRepository interface {
+	// Roles returns all persisted roles of the given user.
+	Roles(ctx context.Context, userID string) ([]Role, error)
+	// Assign persists the role for the given user, does nothing if the user already has the role.
+	Assign(ctx context.Context, userID string, role Role) error
+	// Revoke removes the persisted role of the given user, does nothing if the user does not have the role.
+	Revoke(ctx context.Context, userID string, role Role) error
+}
+
+// RoleService resolves the roles of authenticated users.
+type RoleService struct {
+	repo   RoleRepository
+	admins []string
+}
+
+func NewRoleService(cfg Config, repo RoleRepository) *RoleService {
+	admins := make([]string, 0, len(cfg.Admins))
+	for _, a := range cfg.Admins {
+		if a = strings.ToLower(strings.TrimSpace(a)); a != "" {
+			admins = append(admins, a)
+		}
+	}
+
+	return &RoleService{
+		repo:   repo,
+		admins: admins,
+	}
+}
+
+// Resolve adds the roles of the user to the claims. Users configured as admin get the admin role as long as
+// they are configured, the role is not persisted.
+func (s *RoleService) Resolve(ctx context.Context, c Claims) (Claims, error) {
+	roles, err := s.repo.Roles(ctx, c.ID)
+	if err != nil {
+		return Claims{}, aerrors.NewUnknownError(err, "unable-to-load-roles")
+	}
+
+	if s.isConfiguredAdmin(c.Email) && !slices.Contains(roles, RoleAdmin) {
+		roles = append(roles, RoleAdmin)
+	}
+
+	if !slices.Contains(roles, RoleUser) {
+		roles = append([]Role{RoleUser}, roles...)
+	}
+
+	c.Roles = roles
+
+	return c, nil
+}
+
+func (s *RoleService) isConfiguredAdmin(email string) bool {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return false
+	}
+
+	return slices.Contains(s.admins, email)
+}
@@ -0,0 +1,93 @@
+package auth_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/konstantinfoerster/card-service-go/internal/auth"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveRoles(t *testing.T) {
+	cfg := auth.Config{Admins: []string{" Admin@localhost "}}
+
+	cases := []struct {
+		name     string
+		claims   auth.Claims
+		expected []auth.Role
+	}{
+		{
+			name:     "configured admin",
+			claims:   auth.NewClaims("admin", "admin@LOCALHOST"),
+			expected: []auth.Role{auth.RoleUser, auth.RoleAdmin},
+		},
+		{
+			name:     "regular user",
+			claims:   auth.NewClaims("user", "user@localhost"),
+			expected: []auth.Role{auth.RoleUser},
+		},
+		{
+			name:     "no email",
+			claims:   auth.NewClaims("user", ""),
+			expected: []auth.Role{auth.RoleUser},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := auth.NewRoleService(cfg, memory.NewRoleRepository())
+
+			claims, err := svc.Resolve(context.Background(), tc.claims)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, claims.Roles)
+		})
+	}
+}
+
+func TestResolveRolesDoesNotPersistConfiguredAdmin(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewRoleRepository()
+	svc := auth.NewRoleService(auth.Config{Admins: []string{"admin@localhost"}}, repo)
+
+	claims, err := svc.Resolve(ctx, auth.NewClaims("admin", "admin@localhost"))
+	require.NoError(t, err)
+	assert.True(t, claims.HasRole(auth.RoleAdmin))
+
+	roles, err := repo.Roles(ctx, "admin")
+	require.NoError(t, err)
+	assert.Empty(t, roles)
+
+	// admin role is gone after the email was removed from the configuration
+	svc = auth.NewRoleService(auth.Config{}, repo)
+	claims, err = svc.Resolve(ctx, auth.NewClaims("admin", "admin@localhost"))
+	require.NoError(t, err)
+	assert.False(t, claims.HasRole(auth.RoleAdmin))
+}
+
+func TestResolveRolesKeepsGrantedAdminUntilRevoked(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewRoleRepository()
+	svc := auth.NewRoleService(auth.Config{}, repo)
+	require.NoError(t, repo.Assign(ctx, "admin", auth.RoleAdmin))
+
+	claims, err := svc.Resolve(ctx, auth.NewClaims("admin", "admin@localhost"))
+	require.NoError(t, err)
+	assert.True(t, claims.HasRole(auth.RoleAdmin))
+
+	require.NoError(t, repo.Revoke(ctx, "admin", auth.RoleAdmin))
+	claims, err = svc.Resolve(ctx, auth.NewClaims("admin", "admin@localhost"))
+	require.NoError(t, err)
+	assert.False(t, claims.HasRole(auth.RoleAdmin))
+}
+
+func TestParseRole(t *testing.T) {
+	r, ok := auth.ParseRole(" ADMIN ")
+	assert.True(t, ok)
+	assert.Equal(t, auth.RoleAdmin, r)
+
+	_, ok = auth.ParseRole("root")
+	assert.False(t, ok)
+}
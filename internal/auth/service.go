@@ -2,6 +2,7 @@ package auth
 
 import (
 	"context"
+	"slices"
 
 	"github.com/konstantinfoerster/card-service-go/internal/aerrors"
 )
@@ -41,14 +42,50 @@ func (t *JWT) Encode() (string, error) {
 }
 
 type Claims struct {
-	ID    string
-	Email string
+	// ID identifies the user, the provider subject until it is resolved to the internal user id.
+	ID string
+	// Subject the provider subject, only set once the ID is resolved.
+	Subject string
+	Email   string
+	// Name a display name, used instead of the email if set.
+	Name string
+	// Provider the provider that authenticated the user, empty for personal access tokens.
+	Provider string
+	// Scopes restricts the access, no scopes means unrestricted access.
+	Scopes []Scope
+	// Bearer true if the claims belong to a personal access token instead of a session.
+	Bearer bool
+	// Roles the roles of the user, only set if roles are resolved.
+	Roles []Role
 }
 
 func NewClaims(id, email string) Claims {
 	return Claims{ID: id, Email: email}
 }
 
+// DisplayName returns the name of the user, falls back to the email.
+func (c Claims) DisplayName() string {
+	if c.Name != "" {
+		return c.Name
+	}
+
+	return c.Email
+}
+
+// Allows returns true if the claims grant the given scope.
+func (c Claims) Allows(s Scope) bool {
+	if len(c.Scopes) == 0 {
+		return true
+	}
+
+	return slices.Contains(c.Scopes, s) || (s == ScopeRead && slices.Contains(c.Scopes, ScopeWrite))
+}
+
+// HasRole returns true if the claims contain the given role.
+func (c Claims) HasRole(r Role) bool {
+	return slices.Contains(c.Roles, r)
+}
+
 func New(cfg Config, providers Providers) *AuthFlowService {
 	return &AuthFlowService{
 		provider: providers,
@@ -61,6 +98,11 @@ type AuthFlowService struct {
 	cfg      Config
 }
 
+// ProviderNames returns the names of all configured provider.
+func (s *AuthFlowService) ProviderNames() []string {
+	return s.provider.Names()
+}
+
 func (s *AuthFlowService) AuthURL(provider string) (RedirectURL, error) {
 	p, err := s.provider.Find(provider)
 	if err != nil {
@@ -88,6 +130,7 @@ func (s *AuthFlowService) Authenticate(ctx context.Context, provider string, aut
 	if err != nil {
 		return Claims{}, nil, aerrors.NewUnknownError(err, "exchange-code-failed")
 	}
+	claims.Provider = p.GetName()
 
 	return claims, jwtToken, nil
 }
@@ -102,6 +145,7 @@ func (s *AuthFlowService) AuthInfo(ctx context.Context, provider string, token *
 	if err != nil {
 		return Claims{}, aerrors.NewUnknownError(err, "validate-token-failed")
 	}
+	claims.Provider = p.GetName()
 
 	return claims, nil
 }
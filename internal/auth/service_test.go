@@ -127,7 +127,7 @@ func TestAuthenticate(t *testing.T) {
 
 	require.NoError(t, err)
 	assert.Equal(t, "test", token.Provider)
-	assert.Equal(t, auth.NewClaims("1", "test@localhost"), user)
+	assert.Equal(t, auth.Claims{ID: "1", Email: "test@localhost", Provider: "test"}, user)
 }
 
 func TestAuthenticateOidcServerError(t *testing.T) {
@@ -154,7 +154,7 @@ func TestAuthInfo(t *testing.T) {
 	user, err := svc.AuthInfo(ctx, "test", &auth.JWT{})
 
 	require.NoError(t, err)
-	assert.Equal(t, auth.NewClaims("1", "test@localhost"), user)
+	assert.Equal(t, auth.Claims{ID: "1", Email: "test@localhost", Provider: "test"}, user)
 }
 
 func TestLogout(t *testing.T) {
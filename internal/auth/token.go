@@ -0,0 +1,223 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/konstantinfoerster/card-service-go/internal/aerrors"
+)
+
+var (
+	ErrTokenNotFound = errors.New("token not found")
+	ErrTokenExpired  = errors.New("token expired")
+	ErrTokenInvalid  = errors.New("invalid token")
+	ErrTokenExists   = errors.New("token with that name already exists")
+)
+
+// TokenPrefix marks a personal access token, it helps to identify leaked tokens.
+const TokenPrefix = "cst_"
+
+const (
+	tokenSecretBytes = 32
+	maxTokenNameLen  = 100
+	// DefaultTokenTTL is used when no expiry is requested.
+	DefaultTokenTTL = 30 * 24 * time.Hour
+	// MaxTokenTTL the maximum lifetime of a personal access token.
+	MaxTokenTTL = 365 * 24 * time.Hour
+)
+
+// Scope restricts what a personal access token can be used for.
+type Scope string
+
+const (
+	// ScopeRead allows read-only requests.
+	ScopeRead Scope = "read"
+	// ScopeWrite allows modifying requests, implies ScopeRead.
+	ScopeWrite Scope = "write"
+)
+
+// ParseScopes converts the given values into scopes, unknown values result in an error.
+func ParseScopes(values []string) ([]Scope, error) {
+	scopes := make([]Scope, 0, len(values))
+	for _, v := range values {
+		s := Scope(strings.ToLower(strings.TrimSpace(v)))
+		switch s {
+		case ScopeRead, ScopeWrite:
+			if !slices.Contains(scopes, s) {
+				scopes = append(scopes, s)
+			}
+		default:
+			return nil, fmt.Errorf("unknown scope %q, %w", v, ErrTokenInvalid)
+		}
+	}
+
+	return scopes, nil
+}
+
+// ScopeFor returns the scope that is required to perform a request with the given HTTP method.
+func ScopeFor(method string) Scope {
+	switch strings.ToUpper(method) {
+	case "GET", "HEAD", "OPTIONS":
+		return ScopeRead
+	default:
+		return ScopeWrite
+	}
+}
+
+// PersonalToken a named, scoped and expiring token that can be used instead of a session.
+// Only the hash of the token secret is stored.
+type PersonalToken struct {
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	ID        string
+	UserID    string
+	Email     string
+	Name      string
+	Hash      string
+	Scopes    []Scope
+}
+
+func (t PersonalToken) IsExpired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
+
+type TokenRepository interface {
+	// Save stores a new token, returns ErrTokenExists if the user already has a token with that name.
+	Save(ctx context.Context, token PersonalToken) error
+	// FindByHash returns the token with the given hash or ErrTokenNotFound.
+	FindByHash(ctx context.Context, hash string) (PersonalToken, error)
+	// List returns all tokens of the given user.
+	List(ctx context.Context, userID string) ([]PersonalToken, error)
+	// Delete removes the token of the given user, returns ErrTokenNotFound if the token does not exist.
+	Delete(ctx context.Context, userID string, id string) error
+}
+
+// TimeService provides the current time.
+type TimeService interface {
+	Now() time.Time
+}
+
+type TokenService struct {
+	repo    TokenRepository
+	timeSvc TimeService
+}
+
+func NewTokenService(repo TokenRepository, timeSvc TimeService) *TokenService {
+	return &TokenService{
+		repo:    repo,
+		timeSvc: timeSvc,
+	}
+}
+
+// Create creates a new personal access token for the given user. The returned plain text token is
+// not stored and can't be recovered later.
+func (s *TokenService) Create(
+	ctx context.Context, c Claims, name string, scopes []Scope, ttl time.Duration) (PersonalToken, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxTokenNameLen {
+		msg := fmt.Sprintf("name must be between 1 and %d characters", maxTokenNameLen)
+
+		return PersonalToken{}, "", aerrors.NewInvalidInputMsg("invalid-token-name", msg)
+	}
+
+	if len(scopes) == 0 {
+		return PersonalToken{}, "", aerrors.NewInvalidInputMsg("invalid-token-scope", "at least one scope is required")
+	}
+
+	if ttl == 0 {
+		ttl = DefaultTokenTTL
+	}
+	if ttl < 0 || ttl > MaxTokenTTL {
+		msg := fmt.Sprintf("expiry must be between 1 and %d days", int(MaxTokenTTL.Hours()/24))
+
+		return PersonalToken{}, "", aerrors.NewInvalidInputMsg("invalid-token-expiry", msg)
+	}
+
+	secret := make([]byte, tokenSecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return PersonalToken{}, "", aerrors.NewUnknownError(err, "unable-to-generate-token")
+	}
+	plain := TokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	now := s.timeSvc.Now()
+	token := PersonalToken{
+		ID:        uuid.New().String(),
+		UserID:    c.ID,
+		Email:     c.Email,
+		Name:      name,
+		Hash:      hashToken(plain),
+		Scopes:    scopes,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+
+	if err := s.repo.Save(ctx, token); err != nil {
+		if errors.Is(err, ErrTokenExists) {
+			return PersonalToken{}, "", aerrors.NewInvalidInputError(err, "token-name-exists", "token name already in use")
+		}
+
+		return PersonalToken{}, "", aerrors.NewUnknownError(err, "unable-to-save-token")
+	}
+
+	return token, plain, nil
+}
+
+// List returns all tokens of the given user.
+func (s *TokenService) List(ctx context.Context, userID string) ([]PersonalToken, error) {
+	tokens, err := s.repo.List(ctx, userID)
+	if err != nil {
+		return nil, aerrors.NewUnknownError(err, "unable-to-list-tokens")
+	}
+
+	return tokens, nil
+}
+
+// Revoke deletes the token with the given ID.
+func (s *TokenService) Revoke(ctx context.Context, userID string, id string) error {
+	if err := s.repo.Delete(ctx, userID, id); err != nil {
+		if errors.Is(err, ErrTokenNotFound) {
+			return aerrors.NewNotFoundError(err, "token-not-found")
+		}
+
+		return aerrors.NewUnknownError(err, "unable-to-revoke-token")
+	}
+
+	return nil
+}
+
+// Verify checks the given plain text token and returns the claims of the token owner.
+func (s *TokenService) Verify(ctx context.Context, plain string) (Claims, error) {
+	if !strings.HasPrefix(plain, TokenPrefix) {
+		return Claims{}, ErrTokenInvalid
+	}
+
+	token, err := s.repo.FindByHash(ctx, hashToken(plain))
+	if err != nil {
+		return Claims{}, err
+	}
+
+	if token.IsExpired(s.timeSvc.Now()) {
+		return Claims{}, ErrTokenExpired
+	}
+
+	return Claims{
+		ID:     token.UserID,
+		Email:  token.Email,
+		Scopes: token.Scopes,
+	}, nil
+}
+
+func hashToken(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,165 @@
+package auth_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/konstantinfoerster/card-service-go/internal/aerrors"
+	"github.com/konstantinfoerster/card-service-go/internal/auth"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAndVerifyToken(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	svc := auth.NewTokenService(memory.NewTokenRepository(), auth.NewFakeTimeService(now))
+	claims := auth.NewClaims("myuser", "myuser@localhost")
+
+	token, plain, err := svc.Create(ctx, claims, " script ", []auth.Scope{auth.ScopeRead}, 0)
+
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(plain, auth.TokenPrefix))
+	assert.NotContains(t, token.Hash, plain)
+	assert.Equal(t, "script", token.Name)
+	assert.Equal(t, now.Add(auth.DefaultTokenTTL), token.ExpiresAt)
+
+	verified, err := svc.Verify(ctx, plain)
+
+	require.NoError(t, err)
+	assert.Equal(t, "myuser", verified.ID)
+	assert.Equal(t, "myuser@localhost", verified.Email)
+	assert.Equal(t, []auth.Scope{auth.ScopeRead}, verified.Scopes)
+}
+
+func TestCreateTokenInvalidInput(t *testing.T) {
+	cases := []struct {
+		name      string
+		tokenName string
+		scopes    []auth.Scope
+		ttl       time.Duration
+	}{
+		{
+			name:      "empty name",
+			tokenName: " ",
+			scopes:    []auth.Scope{auth.ScopeRead},
+		},
+		{
+			name:      "no scopes",
+			tokenName: "script",
+		},
+		{
+			name:      "negative ttl",
+			tokenName: "script",
+			scopes:    []auth.Scope{auth.ScopeRead},
+			ttl:       -time.Hour,
+		},
+		{
+			name:      "ttl too long",
+			tokenName: "script",
+			scopes:    []auth.Scope{auth.ScopeRead},
+			ttl:       auth.MaxTokenTTL + time.Hour,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := auth.NewTokenService(memory.NewTokenRepository(), auth.NewTimeService())
+
+			_, _, err := svc.Create(context.Background(), auth.NewClaims("myuser", ""), tc.tokenName, tc.scopes, tc.ttl)
+
+			var appErr aerrors.AppError
+			require.ErrorAs(t, err, &appErr)
+			assert.Equal(t, aerrors.ErrInvalidInput, appErr.ErrorType)
+		})
+	}
+}
+
+func TestCreateTokenDuplicateName(t *testing.T) {
+	ctx := context.Background()
+	svc := auth.NewTokenService(memory.NewTokenRepository(), auth.NewTimeService())
+	claims := auth.NewClaims("myuser", "")
+	_, _, err := svc.Create(ctx, claims, "script", []auth.Scope{auth.ScopeRead}, 0)
+	require.NoError(t, err)
+
+	_, _, err = svc.Create(ctx, claims, "script", []auth.Scope{auth.ScopeWrite}, 0)
+
+	var appErr aerrors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, aerrors.ErrInvalidInput, appErr.ErrorType)
+}
+
+func TestVerifyTokenError(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	repo := memory.NewTokenRepository()
+	creator := auth.NewTokenService(repo, auth.NewFakeTimeService(now))
+	_, plain, err := creator.Create(ctx, auth.NewClaims("myuser", ""), "script", []auth.Scope{auth.ScopeRead}, time.Hour)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name     string
+		token    string
+		now      time.Time
+		expected error
+	}{
+		{
+			name:     "expired",
+			token:    plain,
+			now:      now.Add(time.Hour),
+			expected: auth.ErrTokenExpired,
+		},
+		{
+			name:     "unknown token",
+			token:    auth.TokenPrefix + "unknown",
+			now:      now,
+			expected: auth.ErrTokenNotFound,
+		},
+		{
+			name:     "missing prefix",
+			token:    strings.TrimPrefix(plain, auth.TokenPrefix),
+			now:      now,
+			expected: auth.ErrTokenInvalid,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := auth.NewTokenService(repo, auth.NewFakeTimeService(tc.now))
+
+			_, err := svc.Verify(ctx, tc.token)
+
+			require.ErrorIs(t, err, tc.expected)
+		})
+	}
+}
+
+func TestRevokeToken(t *testing.T) {
+	ctx := context.Background()
+	svc := auth.NewTokenService(memory.NewTokenRepository(), auth.NewTimeService())
+	token, plain, err := svc.Create(ctx, auth.NewClaims("myuser", ""), "script", []auth.Scope{auth.ScopeRead}, 0)
+	require.NoError(t, err)
+
+	err = svc.Revoke(ctx, "otheruser", token.ID)
+	var appErr aerrors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, aerrors.ErrNotFound, appErr.ErrorType)
+
+	require.NoError(t, svc.Revoke(ctx, "myuser", token.ID))
+
+	_, err = svc.Verify(ctx, plain)
+	require.ErrorIs(t, err, auth.ErrTokenNotFound)
+	tokens, err := svc.List(ctx, "myuser")
+	require.NoError(t, err)
+	assert.Empty(t, tokens)
+}
+
+func TestClaimsAllows(t *testing.T) {
+	assert.True(t, auth.NewClaims("1", "").Allows(auth.ScopeWrite))
+	assert.True(t, auth.Claims{ID: "1", Scopes: []auth.Scope{auth.ScopeRead}}.Allows(auth.ScopeRead))
+	assert.False(t, auth.Claims{ID: "1", Scopes: []auth.Scope{auth.ScopeRead}}.Allows(auth.ScopeWrite))
+	assert.True(t, auth.Claims{ID: "1", Scopes: []auth.Scope{auth.ScopeWrite}}.Allows(auth.ScopeRead))
+}
@@ -0,0 +1,246 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/konstantinfoerster/card-service-go/internal/aerrors"
+)
+
+var (
+	ErrUserNotFound       = errors.New("user not found")
+	ErrInvalidDisplayName = errors.New("invalid display name")
+	ErrInvalidIdentity    = errors.New("invalid identity")
+)
+
+const maxDisplayNameLen = 100
+
+// User the profile of a user, created on the first authenticated request after the login.
+// Provider and Subject belong to the identity that created the user.
+type User struct {
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	ID          string
+	Provider    string
+	Subject     string
+	Email       string
+	DisplayName string
+}
+
+// Identity an external account of a user, a user can have one identity per provider.
+type Identity struct {
+	Provider string
+	Subject  string
+}
+
+type UserRepository interface {
+	// Create persists the user together with its identity, does nothing if a user with the same id already exists.
+	Create(ctx context.Context, u User) error
+	// FindByID returns ErrUserNotFound if no user with that id exists.
+	FindByID(ctx context.Context, id string) (User, error)
+	// FindByIdentity returns ErrUserNotFound if the identity is not linked to a user.
+	FindByIdentity(ctx context.Context, identity Identity) (User, error)
+	// Update replaces email and display name, returns ErrUserNotFound if no user with that id exists.
+	Update(ctx context.Context, u User) error
+	// Link assigns the identity to the user. If from is not empty all data stored for that
+	// user id is moved to the user and the user from is removed.
+	Link(ctx context.Context, userID string, identity Identity, from string) error
+	// Delete removes the user together with all data that belongs to the user.
+	Delete(ctx context.Context, id string) error
+}
+
+// ProfileUpdate contains all values a user can change.
+type ProfileUpdate struct {
+	DisplayName string
+}
+
+// UserService manages the stored user profiles.
+type UserService struct {
+	repo    UserRepository
+	timeSvc TimeService
+}
+
+func NewUserService(repo UserRepository, timeSvc TimeService) *UserService {
+	return &UserService{
+		repo:    repo,
+		timeSvc: timeSvc,
+	}
+}
+
+// Resolve replaces the provider subject of the claims with the internal user id and adds the stored
+// display name. Creates the user if the claims come from a login and the identity is not known yet,
+// an outdated email is replaced by the one from the provider.
+func (s *UserService) Resolve(ctx context.Context, c Claims) (Claims, error) {
+	c.Subject = c.ID
+
+	var u User
+	var err error
+	if c.Provider == "" {
+		// personal access tokens already contain the user id
+		if u, err = s.repo.FindByID(ctx, c.ID); err != nil {
+			if errors.Is(err, ErrUserNotFound) {
+				return c, nil
+			}
+
+			return Claims{}, aerrors.NewUnknownError(err, "unable-to-load-user")
+		}
+	} else if u, err = s.findOrCreate(ctx, c); err != nil {
+		return Claims{}, err
+	}
+
+	if c.Provider != "" && c.Email != "" && c.Email != u.Email {
+		u.Email = c.Email
+		u.UpdatedAt = s.timeSvc.Now().UTC()
+		if err := s.repo.Update(ctx, u); err != nil {
+			return Claims{}, aerrors.NewUnknownError(err, "unable-to-update-user")
+		}
+	}
+
+	c.ID = u.ID
+	if u.DisplayName != "" {
+		c.Name = u.DisplayName
+	}
+
+	return c, nil
+}
+
+// Link adds the identity of the claims to the user. If the identity already belongs to another user,
+// that user is merged into the given user. Data stored for the subject before users existed is moved as well.
+func (s *UserService) Link(ctx context.Context, userID string, c Claims) error {
+	identity := Identity{Provider: c.Provider, Subject: c.ID}
+	if userID == "" || identity.Provider == "" || identity.Subject == "" {
+		return aerrors.NewInvalidInputError(ErrInvalidIdentity, "invalid-identity", "invalid identity")
+	}
+
+	from := ""
+	other, err := s.findByIdentity(ctx, identity)
+	switch {
+	case err == nil && other.ID == userID:
+		return nil
+	case err == nil:
+		from = other.ID
+	case errors.Is(err, ErrUserNotFound):
+		// only move data of the subject if it is not the id of an unrelated user
+		if _, err := s.repo.FindByID(ctx, identity.Subject); errors.Is(err, ErrUserNotFound) {
+			from = identity.Subject
+		} else if err != nil {
+			return aerrors.NewUnknownError(err, "unable-to-load-user")
+		}
+	default:
+		return aerrors.NewUnknownError(err, "unable-to-load-user")
+	}
+
+	if err := s.repo.Link(ctx, userID, identity, from); err != nil {
+		return aerrors.NewUnknownError(err, "unable-to-link-identity")
+	}
+
+	return nil
+}
+
+// Get returns the profile of the user.
+func (s *UserService) Get(ctx context.Context, id string) (User, error) {
+	u, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return User{}, aerrors.NewNotFoundError(err, "user-not-found")
+		}
+
+		return User{}, aerrors.NewUnknownError(err, "unable-to-load-user")
+	}
+
+	return u, nil
+}
+
+// Update changes the profile of the user.
+func (s *UserService) Update(ctx context.Context, id string, update ProfileUpdate) (User, error) {
+	name := strings.TrimSpace(update.DisplayName)
+	if name == "" || utf8.RuneCountInString(name) > maxDisplayNameLen {
+		return User{}, aerrors.NewInvalidInputError(ErrInvalidDisplayName, "invalid-display-name",
+			"display name must have 1 to 100 characters")
+	}
+
+	u, err := s.Get(ctx, id)
+	if err != nil {
+		return User{}, err
+	}
+
+	u.DisplayName = name
+	u.UpdatedAt = s.timeSvc.Now().UTC()
+	if err := s.repo.Update(ctx, u); err != nil {
+		return User{}, aerrors.NewUnknownError(err, "unable-to-update-user")
+	}
+
+	return u, nil
+}
+
+// Delete removes the user and all data of the user like the collection, tokens and roles.
+func (s *UserService) Delete(ctx context.Context, id string) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return aerrors.NewUnknownError(err, "unable-to-delete-user")
+	}
+
+	return nil
+}
+
+func (s *UserService) findOrCreate(ctx context.Context, c Claims) (User, error) {
+	identity := Identity{Provider: c.Provider, Subject: c.ID}
+	u, err := s.findByIdentity(ctx, identity)
+	if err == nil {
+		return u, nil
+	}
+	if !errors.Is(err, ErrUserNotFound) {
+		return User{}, aerrors.NewUnknownError(err, "unable-to-load-user")
+	}
+
+	// existing data is stored for the subject, new users keep it as id if possible
+	id := c.ID
+	if _, err := s.repo.FindByID(ctx, id); err == nil {
+		id = uuid.New().String()
+	} else if !errors.Is(err, ErrUserNotFound) {
+		return User{}, aerrors.NewUnknownError(err, "unable-to-load-user")
+	}
+
+	now := s.timeSvc.Now().UTC()
+	u = User{
+		ID:          id,
+		Provider:    c.Provider,
+		Subject:     c.ID,
+		Email:       c.Email,
+		DisplayName: c.Name,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.repo.Create(ctx, u); err != nil {
+		return User{}, aerrors.NewUnknownError(err, "unable-to-create-user")
+	}
+
+	return u, nil
+}
+
+// findByIdentity finds the user of the identity. Users that were created before identities existed
+// use the subject as id and have no linked identity, the identity is linked on the first access.
+func (s *UserService) findByIdentity(ctx context.Context, identity Identity) (User, error) {
+	u, err := s.repo.FindByIdentity(ctx, identity)
+	if err == nil || !errors.Is(err, ErrUserNotFound) {
+		return u, err
+	}
+
+	u, err = s.repo.FindByID(ctx, identity.Subject)
+	if err != nil {
+		return User{}, err
+	}
+
+	if u.Provider != identity.Provider || u.Subject != identity.Subject {
+		return User{}, ErrUserNotFound
+	}
+
+	if err := s.repo.Link(ctx, u.ID, identity, ""); err != nil {
+		return User{}, err
+	}
+
+	return u, nil
+}
@@ -0,0 +1,178 @@
+package auth_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/konstantinfoerster/card-service-go/internal/aerrors"
+	"github.com/konstantinfoerster/card-service-go/internal/auth"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/memory"
+	"github.com/konstantinfoerster/card-service-go/internal/jobs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveCreatesUserOnLogin(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	svc := auth.NewUserService(memory.NewUserRepository(), auth.NewFakeTimeService(now))
+	claims := auth.Claims{ID: "myuser", Email: "myuser@localhost", Provider: "google"}
+
+	resolved, err := svc.Resolve(ctx, claims)
+
+	require.NoError(t, err)
+	assert.Equal(t, "myuser", resolved.ID)
+	assert.Equal(t, "myuser", resolved.Subject)
+	u, err := svc.Get(ctx, "myuser")
+	require.NoError(t, err)
+	assert.Equal(t, auth.User{
+		ID: "myuser", Provider: "google", Subject: "myuser", Email: "myuser@localhost", CreatedAt: now, UpdatedAt: now,
+	}, u)
+}
+
+func TestResolveWithoutProvider(t *testing.T) {
+	ctx := context.Background()
+	svc := auth.NewUserService(memory.NewUserRepository(), auth.NewTimeService())
+
+	_, err := svc.Resolve(ctx, auth.NewClaims("myuser", "myuser@localhost"))
+	require.NoError(t, err)
+
+	_, err = svc.Get(ctx, "myuser")
+	var appErr aerrors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, aerrors.ErrNotFound, appErr.ErrorType)
+}
+
+func TestResolveUsesStoredProfile(t *testing.T) {
+	ctx := context.Background()
+	svc := auth.NewUserService(memory.NewUserRepository(), auth.NewTimeService())
+	_, err := svc.Resolve(ctx, auth.Claims{ID: "myuser", Email: "old@localhost", Provider: "google"})
+	require.NoError(t, err)
+	_, err = svc.Update(ctx, "myuser", auth.ProfileUpdate{DisplayName: " My Name "})
+	require.NoError(t, err)
+
+	resolved, err := svc.Resolve(ctx, auth.Claims{ID: "myuser", Email: "new@localhost", Provider: "google"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "My Name", resolved.DisplayName())
+	u, err := svc.Get(ctx, "myuser")
+	require.NoError(t, err)
+	assert.Equal(t, "new@localhost", u.Email)
+}
+
+func TestUpdateProfileInvalidInput(t *testing.T) {
+	ctx := context.Background()
+	svc := auth.NewUserService(memory.NewUserRepository(), auth.NewTimeService())
+
+	cases := []struct {
+		name     string
+		id       string
+		update   auth.ProfileUpdate
+		expected aerrors.ErrorType
+	}{
+		{
+			name:     "empty display name",
+			id:       "myuser",
+			update:   auth.ProfileUpdate{DisplayName: " "},
+			expected: aerrors.ErrInvalidInput,
+		},
+		{
+			name:     "unknown user",
+			id:       "unknown",
+			update:   auth.ProfileUpdate{DisplayName: "name"},
+			expected: aerrors.ErrNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := svc.Update(ctx, tc.id, tc.update)
+
+			var appErr aerrors.AppError
+			require.ErrorAs(t, err, &appErr)
+			assert.Equal(t, tc.expected, appErr.ErrorType)
+		})
+	}
+}
+
+func TestDeleteUser(t *testing.T) {
+	ctx := context.Background()
+	jobRepo := memory.NewJobRepository()
+	svc := auth.NewUserService(memory.NewUserRepository(memory.WithJobs(jobRepo)), auth.NewTimeService())
+	_, err := svc.Resolve(ctx, auth.Claims{ID: "myuser", Provider: "google"})
+	require.NoError(t, err)
+	require.NoError(t, jobRepo.Save(ctx, jobs.Job{ID: "job-1", Owner: "myuser", Status: jobs.StatusDone}))
+
+	require.NoError(t, svc.Delete(ctx, "myuser"))
+
+	_, err = svc.Get(ctx, "myuser")
+	require.Error(t, err)
+	_, err = jobRepo.Find(ctx, "job-1")
+	require.ErrorIs(t, err, jobs.ErrJobNotFound)
+}
+
+func TestResolveNewIdentityWithTakenID(t *testing.T) {
+	ctx := context.Background()
+	svc := auth.NewUserService(memory.NewUserRepository(), auth.NewTimeService())
+	first, err := svc.Resolve(ctx, auth.Claims{ID: "same-subject", Provider: "google"})
+	require.NoError(t, err)
+
+	second, err := svc.Resolve(ctx, auth.Claims{ID: "same-subject", Provider: "local"})
+
+	require.NoError(t, err)
+	assert.NotEqual(t, first.ID, second.ID)
+	assert.Equal(t, "same-subject", second.Subject)
+}
+
+func TestLinkIdentity(t *testing.T) {
+	ctx := context.Background()
+	svc := auth.NewUserService(memory.NewUserRepository(), auth.NewTimeService())
+	google := auth.Claims{ID: "google-1", Email: "myuser@localhost", Provider: "google"}
+	local := auth.Claims{ID: "local-1", Name: "myuser", Provider: "local"}
+	user, err := svc.Resolve(ctx, google)
+	require.NoError(t, err)
+
+	require.NoError(t, svc.Link(ctx, user.ID, local))
+	require.NoError(t, svc.Link(ctx, user.ID, local))
+
+	resolved, err := svc.Resolve(ctx, local)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, resolved.ID)
+	assert.Equal(t, "local-1", resolved.Subject)
+}
+
+func TestLinkIdentityOfOtherUser(t *testing.T) {
+	ctx := context.Background()
+	jobRepo := memory.NewJobRepository()
+	svc := auth.NewUserService(memory.NewUserRepository(memory.WithJobs(jobRepo)), auth.NewTimeService())
+	google := auth.Claims{ID: "google-1", Provider: "google"}
+	local := auth.Claims{ID: "local-1", Provider: "local"}
+	user, err := svc.Resolve(ctx, google)
+	require.NoError(t, err)
+	other, err := svc.Resolve(ctx, local)
+	require.NoError(t, err)
+	require.NotEqual(t, user.ID, other.ID)
+	require.NoError(t, jobRepo.Save(ctx, jobs.Job{ID: "job-1", Owner: other.ID, Status: jobs.StatusDone}))
+
+	require.NoError(t, svc.Link(ctx, user.ID, local))
+
+	resolved, err := svc.Resolve(ctx, local)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, resolved.ID)
+	_, err = svc.Get(ctx, other.ID)
+	require.Error(t, err, "expected merged user to be removed")
+	job, err := jobRepo.Find(ctx, "job-1")
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, job.Owner)
+}
+
+func TestLinkInvalidIdentity(t *testing.T) {
+	svc := auth.NewUserService(memory.NewUserRepository(), auth.NewTimeService())
+
+	err := svc.Link(context.Background(), "myuser", auth.NewClaims("google-1", ""))
+
+	var appErr aerrors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, aerrors.ErrInvalidInput, appErr.ErrorType)
+}
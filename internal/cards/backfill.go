@@ -0,0 +1,191 @@
+package cards
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/konstantinfoerster/card-service-go/internal/aerrors"
+	"golang.org/x/sync/errgroup"
+)
+
+// CardImage a stored image of a card, the path is relative to the image host.
+type CardImage struct {
+	ID   int
+	Path string
+}
+
+// ImageHash the computed hash of a card image, the algorithm is part of the hash.
+type ImageHash struct {
+	ImageID int
+	Hash    Hash
+	// Region the hashed card region, empty for the hash of the whole image.
+	Region CardRegion
+}
+
+type HashRepository interface {
+	// CountMissingHashes returns the number of images without a hash for at least one of the algorithms or
+	// without a hash for at least one of the rerank regions.
+	CountMissingHashes(ctx context.Context, algs []HashAlgorithm, rerank RerankConfig) (int, error)
+	// MissingHashes returns up to limit images without a hash for at least one of the algorithms or rerank
+	// regions and an id greater than afterID, ordered by id.
+	MissingHashes(
+		ctx context.Context, algs []HashAlgorithm, rerank RerankConfig, afterID int, limit int,
+	) ([]CardImage, error)
+	// UpdateHashes inserts the hashes or replaces existing hashes of the same algorithm and region.
+	UpdateHashes(ctx context.Context, hashes []ImageHash) error
+}
+
+// ImageHasher loads the image from the path and computes one hash per algorithm or region.
+type ImageHasher interface {
+	Hash(ctx context.Context, path string, algs ...HashAlgorithm) ([]Hash, error)
+	HashRegions(ctx context.Context, path string, alg HashAlgorithm, regions ...CardRegion) ([]RegionHash, error)
+}
+
+type BackfillConfig struct {
+	// Algorithms the hashes that are computed, only pHash if empty.
+	Algorithms []HashAlgorithm
+	// Rerank the card regions that are hashed as well, no region hashes are computed if it is disabled.
+	Rerank RerankConfig
+	// Workers the number of images that are hashed at the same time.
+	Workers int
+	// BatchSize the number of images that are hashed and written back together.
+	BatchSize int
+	// AfterID skips all images with a lower or equal id, used to continue an aborted run.
+	AfterID int
+}
+
+type BackfillResult struct {
+	Hashed int
+	Failed int
+	// LastID the id of the last processed image, a new run can continue after it.
+	LastID int
+}
+
+// BackfillService computes the hashes of all card images without a hash.
+type BackfillService struct {
+	repo   HashRepository
+	hasher ImageHasher
+}
+
+func NewBackfillService(repo HashRepository, hasher ImageHasher) *BackfillService {
+	return &BackfillService{
+		repo:   repo,
+		hasher: hasher,
+	}
+}
+
+// Run hashes all images without a hash batch by batch. Images that cannot be loaded are logged and skipped.
+// Every batch is written before the next batch starts, an aborted run can be started again and only
+// processes the remaining images.
+func (s *BackfillService) Run(ctx context.Context, cfg BackfillConfig) (BackfillResult, error) {
+	if len(cfg.Algorithms) == 0 {
+		cfg.Algorithms = []HashAlgorithm{PHash}
+	}
+
+	total, err := s.repo.CountMissingHashes(ctx, cfg.Algorithms, cfg.Rerank)
+	if err != nil {
+		return BackfillResult{}, aerrors.NewUnknownError(err, "backfill-count-failed")
+	}
+	slog.Info("hash backfill started", slog.Int("missing", total), slog.Int("afterID", cfg.AfterID))
+
+	result := BackfillResult{LastID: cfg.AfterID}
+	for {
+		images, err := s.repo.MissingHashes(ctx, cfg.Algorithms, cfg.Rerank, result.LastID, max(cfg.BatchSize, 1))
+		if err != nil {
+			return result, aerrors.NewUnknownError(err, "backfill-read-failed")
+		}
+		if len(images) == 0 {
+			break
+		}
+
+		hashes, hashed, err := s.hashAll(ctx, images, cfg)
+		if err != nil {
+			return result, aerrors.NewUnknownError(err, "backfill-aborted")
+		}
+		if err := s.repo.UpdateHashes(ctx, hashes); err != nil {
+			return result, aerrors.NewUnknownError(err, "backfill-write-failed")
+		}
+
+		result.Hashed += hashed
+		result.Failed += len(images) - hashed
+		result.LastID = images[len(images)-1].ID
+		slog.Info("hash backfill progress",
+			slog.Int("hashed", result.Hashed),
+			slog.Int("failed", result.Failed),
+			slog.Int("total", total),
+			slog.Int("lastID", result.LastID),
+		)
+	}
+
+	slog.Info("hash backfill finished", slog.Int("hashed", result.Hashed), slog.Int("failed", result.Failed))
+
+	return result, nil
+}
+
+// hashAll hashes the images with the configured number of workers and returns the hashes and the number of
+// hashed images. Only returns an error if the context is canceled.
+func (s *BackfillService) hashAll(
+	ctx context.Context, images []CardImage, cfg BackfillConfig,
+) ([]ImageHash, int, error) {
+	hashes := make([][]ImageHash, len(images))
+
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(max(cfg.Workers, 1))
+	for i, img := range images {
+		g.Go(func() error {
+			if err := gCtx.Err(); err != nil {
+				return err
+			}
+
+			computed, err := s.hash(gCtx, img, cfg)
+			if err != nil {
+				slog.Warn("failed to hash image",
+					slog.Int("id", img.ID), slog.String("path", img.Path), slog.Any("error", err))
+
+				return nil
+			}
+			hashes[i] = computed
+
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]ImageHash, 0, len(hashes)*(len(cfg.Algorithms)+len(cfg.Rerank.Regions)))
+	hashed := 0
+	for _, h := range hashes {
+		if len(h) > 0 {
+			result = append(result, h...)
+			hashed++
+		}
+	}
+
+	return result, hashed, nil
+}
+
+// hash computes the hashes of the whole image and of the rerank regions.
+func (s *BackfillService) hash(ctx context.Context, img CardImage, cfg BackfillConfig) ([]ImageHash, error) {
+	computed, err := s.hasher.Hash(ctx, img.Path, cfg.Algorithms...)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]ImageHash, 0, len(computed)+len(cfg.Rerank.Regions))
+	for _, h := range computed {
+		hashes = append(hashes, ImageHash{ImageID: img.ID, Hash: h})
+	}
+	if !cfg.Rerank.Enabled() {
+		return hashes, nil
+	}
+
+	regions, err := s.hasher.HashRegions(ctx, img.Path, cfg.Rerank.HashAlgorithm(), cfg.Rerank.Regions...)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range regions {
+		hashes = append(hashes, ImageHash{ImageID: img.ID, Hash: r.Hash, Region: r.Region})
+	}
+
+	return hashes, nil
+}
@@ -0,0 +1,174 @@
+package cards_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errNotFound = errors.New("not found")
+
+func TestBackfill(t *testing.T) {
+	repo := newFakeHashRepository(
+		cards.CardImage{ID: 1, Path: "1.png"},
+		cards.CardImage{ID: 2, Path: "broken.png"},
+		cards.CardImage{ID: 3, Path: "3.png"},
+		cards.CardImage{ID: 4, Path: "4.png"},
+		cards.CardImage{ID: 5, Path: "5.png"},
+	)
+	svc := cards.NewBackfillService(repo, fakeHasher{"1.png": 10, "3.png": 30, "4.png": 40, "5.png": 50})
+
+	result, err := svc.Run(context.Background(), cards.BackfillConfig{Workers: 2, BatchSize: 2})
+
+	require.NoError(t, err)
+	assert.Equal(t, cards.BackfillResult{Hashed: 4, Failed: 1, LastID: 5}, result)
+	assert.Equal(t, map[int]uint64{1: 10, 3: 30, 4: 40, 5: 50}, repo.hashed)
+	assert.Equal(t, 3, repo.batches)
+}
+
+func TestBackfillAllAlgorithms(t *testing.T) {
+	repo := newFakeHashRepository(cards.CardImage{ID: 1, Path: "1.png"})
+	svc := cards.NewBackfillService(repo, fakeHasher{"1.png": 10})
+	algs := []cards.HashAlgorithm{cards.PHash, cards.WHash}
+
+	result, err := svc.Run(context.Background(), cards.BackfillConfig{Algorithms: algs, Workers: 1, BatchSize: 10})
+
+	require.NoError(t, err)
+	assert.Equal(t, cards.BackfillResult{Hashed: 1, Failed: 0, LastID: 1}, result)
+	assert.Equal(t, map[int][]cards.HashAlgorithm{1: algs}, repo.algorithms)
+}
+
+func TestBackfillRegions(t *testing.T) {
+	repo := newFakeHashRepository(cards.CardImage{ID: 1, Path: "1.png"}, cards.CardImage{ID: 2, Path: "broken.png"})
+	svc := cards.NewBackfillService(repo, fakeHasher{"1.png": 10})
+	rerank := cards.RerankConfig{Regions: []cards.CardRegion{cards.ArtRegion, cards.SymbolRegion}}
+
+	result, err := svc.Run(context.Background(), cards.BackfillConfig{Rerank: rerank, Workers: 1, BatchSize: 10})
+
+	require.NoError(t, err)
+	assert.Equal(t, cards.BackfillResult{Hashed: 1, Failed: 1, LastID: 2}, result)
+	assert.Equal(t, map[int][]cards.HashAlgorithm{1: {cards.PHash}}, repo.algorithms)
+	assert.Equal(t, map[int][]cards.CardRegion{1: rerank.Regions}, repo.regions)
+}
+
+func TestBackfillContinuesAfterID(t *testing.T) {
+	repo := newFakeHashRepository(
+		cards.CardImage{ID: 1, Path: "1.png"},
+		cards.CardImage{ID: 3, Path: "3.png"},
+	)
+	svc := cards.NewBackfillService(repo, fakeHasher{"1.png": 10, "3.png": 30})
+
+	result, err := svc.Run(context.Background(), cards.BackfillConfig{Workers: 1, BatchSize: 10, AfterID: 1})
+
+	require.NoError(t, err)
+	assert.Equal(t, cards.BackfillResult{Hashed: 1, Failed: 0, LastID: 3}, result)
+	assert.Equal(t, map[int]uint64{3: 30}, repo.hashed)
+}
+
+func TestBackfillCanceled(t *testing.T) {
+	repo := newFakeHashRepository(cards.CardImage{ID: 1, Path: "1.png"})
+	svc := cards.NewBackfillService(repo, fakeHasher{"1.png": 10})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, err := svc.Run(ctx, cards.BackfillConfig{Workers: 1, BatchSize: 10})
+
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 0, result.LastID)
+	assert.Empty(t, repo.hashed)
+}
+
+// fakeHasher returns the stored value as hash for the path.
+type fakeHasher map[string]uint64
+
+func (h fakeHasher) Hash(_ context.Context, path string, algs ...cards.HashAlgorithm) ([]cards.Hash, error) {
+	v, ok := h[path]
+	if !ok {
+		return nil, errNotFound
+	}
+
+	hashes := make([]cards.Hash, 0, len(algs))
+	for _, alg := range algs {
+		hashes = append(hashes, cards.Hash{Algorithm: alg, Value: []uint64{v}, Bits: 64})
+	}
+
+	return hashes, nil
+}
+
+func (h fakeHasher) HashRegions(
+	_ context.Context, path string, alg cards.HashAlgorithm, regions ...cards.CardRegion,
+) ([]cards.RegionHash, error) {
+	v, ok := h[path]
+	if !ok {
+		return nil, errNotFound
+	}
+
+	hashes := make([]cards.RegionHash, 0, len(regions))
+	for _, r := range regions {
+		hash := cards.Hash{Algorithm: alg, Value: []uint64{v}, Bits: 64}
+		hashes = append(hashes, cards.RegionHash{Region: r, Hash: hash})
+	}
+
+	return hashes, nil
+}
+
+type fakeHashRepository struct {
+	images     []cards.CardImage
+	hashed     map[int]uint64
+	algorithms map[int][]cards.HashAlgorithm
+	regions    map[int][]cards.CardRegion
+	batches    int
+	mu         sync.Mutex
+}
+
+func newFakeHashRepository(images ...cards.CardImage) *fakeHashRepository {
+	return &fakeHashRepository{
+		images:     images,
+		hashed:     make(map[int]uint64),
+		algorithms: make(map[int][]cards.HashAlgorithm),
+		regions:    make(map[int][]cards.CardRegion),
+	}
+}
+
+func (r *fakeHashRepository) CountMissingHashes(
+	_ context.Context, _ []cards.HashAlgorithm, _ cards.RerankConfig,
+) (int, error) {
+	return len(r.images) - len(r.hashed), nil
+}
+
+func (r *fakeHashRepository) MissingHashes(
+	_ context.Context, _ []cards.HashAlgorithm, _ cards.RerankConfig, afterID int, limit int,
+) ([]cards.CardImage, error) {
+	result := make([]cards.CardImage, 0)
+	for _, img := range r.images {
+		if _, ok := r.hashed[img.ID]; ok || img.ID <= afterID {
+			continue
+		}
+		result = append(result, img)
+	}
+
+	return result[:min(limit, len(result))], nil
+}
+
+func (r *fakeHashRepository) UpdateHashes(_ context.Context, hashes []cards.ImageHash) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.batches++
+	for _, h := range hashes {
+		if h.Region != "" {
+			r.regions[h.ImageID] = append(r.regions[h.ImageID], h.Region)
+
+			continue
+		}
+		r.hashed[h.ImageID] = h.Hash.Value[0]
+		r.algorithms[h.ImageID] = append(r.algorithms[h.ImageID], h.Hash.Algorithm)
+	}
+
+	return nil
+}
@@ -0,0 +1,245 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/konstantinfoerster/card-service-go/internal/aerrors"
+)
+
+var (
+	ErrUnsupportedCard = errors.New("unsupported card")
+	ErrUnknownFormat   = errors.New("unknown catalog format, expected a Scryfall or MTGJSON bulk file")
+)
+
+const english = "eng"
+
+// the values of the database enums.
+//
+//nolint:gochecknoglobals
+var (
+	setTypes = []string{
+		"CORE", "EXPANSION", "REPRINT", "BOX", "UN", "FROM_THE_VAULT", "PREMIUM_DECK", "DUEL_DECK", "STARTER",
+		"COMMANDER", "PLANECHASE", "ARCHENEMY", "PROMO", "VANGUARD", "MASTERS", "MEMORABILIA", "DRAFT_INNOVATION",
+		"FUNNY", "MASTERPIECE", "TOKEN", "TREASURE_CHEST", "SPELLBOOK", "ARSENAL", "ALCHEMY",
+	}
+	rarities = []string{"COMMON", "UNCOMMON", "RARE", "MYTHIC", "SPECIAL", "BASIC_LAND", "BONUS"}
+	borders  = []string{"WHITE", "BLACK", "SILVER", "GOLD", "BORDERLESS"}
+	layouts  = []string{
+		"NORMAL", "SPLIT", "FLIP", "TOKEN", "PLANE", "SCHEMA", "PHENOMENON", "LEVELER", "VANGUARD", "MELD",
+		"AFTERMATH", "SAGA", "TRANSFORM", "ADVENTURE", "MODAL_DFC", "SCHEME", "PLANAR", "HOST", "AUGMENT", "CLASS",
+		"REVERSIBLE_CARD",
+	}
+)
+
+// Set a card set, the type is one of the card_set_type values.
+type Set struct {
+	Code     string
+	Name     string
+	Type     string
+	Released time.Time
+	Block    string
+	// Translations the set name per language.
+	Translations []SetTranslation
+}
+
+type SetTranslation struct {
+	Lang string
+	Name string
+}
+
+// Card a print of a card in a single language. The english print defines the card and its faces, prints
+// in other languages only add their translations and images.
+type Card struct {
+	Set    Set
+	Name   string
+	Number string
+	Rarity string
+	Border string
+	Layout string
+	// Lang the language of the print.
+	Lang   string
+	Faces  []Face
+	Images []Image
+}
+
+// English returns true if the card is the english print.
+func (c Card) English() bool {
+	return c.Lang == english
+}
+
+type Face struct {
+	Name              string
+	Text              string
+	FlavorText        string
+	TypeLine          string
+	ManaCost          string
+	ConvertedManaCost float64
+	Colors            []string
+	Artist            string
+	HandModifier      string
+	LifeModifier      string
+	Loyalty           string
+	Power             string
+	Toughness         string
+	MultiverseID      int
+	SuperTypes        []string
+	Types             []string
+	SubTypes          []string
+	Translations      []Translation
+}
+
+// Translation the printed text of a face in another language than english.
+type Translation struct {
+	Lang         string
+	Name         string
+	Text         string
+	FlavorText   string
+	TypeLine     string
+	MultiverseID int
+}
+
+// Image a card image, the path is relative to the image host.
+type Image struct {
+	// Face the index of the shown face.
+	Face     int
+	Lang     string
+	Path     string
+	MimeType string
+}
+
+// Validate returns ErrUnsupportedCard if the card cannot be stored, e.g. because of an unknown layout.
+func (c Card) Validate() error {
+	switch {
+	case c.Set.Code == "" || c.Set.Name == "":
+		return fmt.Errorf("%w, missing set", ErrUnsupportedCard)
+	case c.Name == "" || c.Number == "":
+		return fmt.Errorf("%w, missing name or number", ErrUnsupportedCard)
+	case len(c.Faces) == 0:
+		return fmt.Errorf("%w, missing faces", ErrUnsupportedCard)
+	case c.Lang == "":
+		return fmt.Errorf("%w, unknown language", ErrUnsupportedCard)
+	case !slices.Contains(setTypes, c.Set.Type):
+		return fmt.Errorf("%w, set type %q", ErrUnsupportedCard, c.Set.Type)
+	case !slices.Contains(rarities, c.Rarity):
+		return fmt.Errorf("%w, rarity %q", ErrUnsupportedCard, c.Rarity)
+	case !slices.Contains(borders, c.Border):
+		return fmt.Errorf("%w, border %q", ErrUnsupportedCard, c.Border)
+	case !slices.Contains(layouts, c.Layout):
+		return fmt.Errorf("%w, layout %q", ErrUnsupportedCard, c.Layout)
+	}
+	for _, img := range c.Images {
+		if img.Face < 0 || img.Face >= len(c.Faces) {
+			return fmt.Errorf("%w, image of unknown face %d", ErrUnsupportedCard, img.Face)
+		}
+	}
+
+	return nil
+}
+
+// Reader reads the cards of a bulk file one by one, returns io.EOF after the last card.
+type Reader interface {
+	Next() (Card, error)
+}
+
+type Repository interface {
+	// Upsert inserts or updates the cards together with their sets, faces, translations, types and images.
+	// Cards are identified by their set and number, the result contains the number of added and updated cards.
+	Upsert(ctx context.Context, cards []Card) (Result, error)
+}
+
+type Result struct {
+	Added int
+	// Updated cards that already existed and had changed values, unchanged cards are not counted.
+	Updated int
+	// Skipped cards that cannot be stored, e.g. because of an unknown layout.
+	Skipped int
+}
+
+func (r Result) add(o Result) Result {
+	return Result{Added: r.Added + o.Added, Updated: r.Updated + o.Updated, Skipped: r.Skipped + o.Skipped}
+}
+
+// Importer stores the cards of a bulk file batch by batch.
+type Importer struct {
+	repo Repository
+}
+
+func NewImporter(repo Repository) *Importer {
+	return &Importer{
+		repo: repo,
+	}
+}
+
+// Run reads all cards and upserts them in batches of the given size. Every batch is written before the next
+// batch is read, an aborted run can be started again and updates the already imported cards.
+func (i *Importer) Run(ctx context.Context, r Reader, batchSize int) (Result, error) {
+	batchSize = max(batchSize, 1)
+	slog.Info("catalog import started", slog.Int("batchSize", batchSize))
+
+	var result Result
+	batch := make([]Card, 0, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		written, err := i.repo.Upsert(ctx, batch)
+		if err != nil {
+			return aerrors.NewUnknownError(err, "catalog-write-failed")
+		}
+		result = result.add(written)
+		batch = batch[:0]
+		slog.Info("catalog import progress",
+			slog.Int("added", result.Added),
+			slog.Int("updated", result.Updated),
+			slog.Int("skipped", result.Skipped),
+		)
+
+		return nil
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		card, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return result, aerrors.NewInvalidInputError(err, "catalog-read-failed", "invalid catalog file")
+		}
+
+		if err := card.Validate(); err != nil {
+			slog.Debug("skipped card",
+				slog.String("set", card.Set.Code), slog.String("number", card.Number), slog.Any("error", err))
+			result.Skipped++
+
+			continue
+		}
+
+		batch = append(batch, card)
+		if len(batch) == batchSize {
+			if err := flush(); err != nil {
+				return result, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return result, err
+	}
+
+	slog.Info("catalog import finished",
+		slog.Int("added", result.Added),
+		slog.Int("updated", result.Updated),
+		slog.Int("skipped", result.Skipped),
+	)
+
+	return result, nil
+}
@@ -0,0 +1,134 @@
+package catalog_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/konstantinfoerster/card-service-go/internal/aerrors"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/catalog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errWrite = errors.New("write failed")
+
+func TestImporter(t *testing.T) {
+	repo := &fakeRepository{known: map[string]bool{"DOM 2": true}}
+	r := &sliceReader{cards: []catalog.Card{
+		validCard("1"),
+		validCard("2"),
+		func() catalog.Card { c := validCard("3"); c.Layout = "ART_SERIES"; return c }(),
+		validCard("4"),
+		validCard("5"),
+	}}
+
+	result, err := catalog.NewImporter(repo).Run(context.Background(), r, 2)
+
+	require.NoError(t, err)
+	assert.Equal(t, catalog.Result{Added: 3, Updated: 1, Skipped: 1}, result)
+	assert.Equal(t, []int{2, 2}, repo.batches)
+}
+
+func TestImporterWriteError(t *testing.T) {
+	repo := &fakeRepository{err: errWrite}
+	r := &sliceReader{cards: []catalog.Card{validCard("1")}}
+
+	_, err := catalog.NewImporter(repo).Run(context.Background(), r, 10)
+
+	require.ErrorIs(t, err, errWrite)
+	var appErr aerrors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, "catalog-write-failed", appErr.Key)
+}
+
+func TestImporterCanceled(t *testing.T) {
+	repo := &fakeRepository{}
+	r := &sliceReader{cards: []catalog.Card{validCard("1")}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, err := catalog.NewImporter(repo).Run(ctx, r, 10)
+
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, catalog.Result{}, result)
+	assert.Empty(t, repo.batches)
+}
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name   string
+		modify func(c *catalog.Card)
+	}{
+		{name: "missing set", modify: func(c *catalog.Card) { c.Set.Code = "" }},
+		{name: "missing number", modify: func(c *catalog.Card) { c.Number = "" }},
+		{name: "missing faces", modify: func(c *catalog.Card) { c.Faces = nil }},
+		{name: "unknown language", modify: func(c *catalog.Card) { c.Lang = "" }},
+		{name: "unknown set type", modify: func(c *catalog.Card) { c.Set.Type = "ART_SERIES" }},
+		{name: "unknown rarity", modify: func(c *catalog.Card) { c.Rarity = "TIMESHIFTED" }},
+		{name: "unknown border", modify: func(c *catalog.Card) { c.Border = "YELLOW" }},
+		{name: "image of unknown face", modify: func(c *catalog.Card) { c.Images[0].Face = 1 }},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := validCard("1")
+			tc.modify(&c)
+
+			require.ErrorIs(t, c.Validate(), catalog.ErrUnsupportedCard)
+		})
+	}
+}
+
+func validCard(number string) catalog.Card {
+	return catalog.Card{
+		Set:    catalog.Set{Code: "DOM", Name: "Dominaria", Type: "EXPANSION"},
+		Name:   "Card " + number,
+		Number: number,
+		Rarity: "COMMON",
+		Border: "BLACK",
+		Layout: "NORMAL",
+		Lang:   "eng",
+		Faces:  []catalog.Face{{Name: "Card " + number}},
+		Images: []catalog.Image{{Face: 0, Lang: "eng", Path: number + ".jpg", MimeType: "image/jpeg"}},
+	}
+}
+
+type sliceReader struct {
+	cards []catalog.Card
+}
+
+func (r *sliceReader) Next() (catalog.Card, error) {
+	if len(r.cards) == 0 {
+		return catalog.Card{}, io.EOF
+	}
+	c := r.cards[0]
+	r.cards = r.cards[1:]
+
+	return c, nil
+}
+
+// fakeRepository counts every card as added except the known ones.
+type fakeRepository struct {
+	known   map[string]bool
+	batches []int
+	err     error
+}
+
+func (r *fakeRepository) Upsert(_ context.Context, cards []catalog.Card) (catalog.Result, error) {
+	if r.err != nil {
+		return catalog.Result{}, r.err
+	}
+	r.batches = append(r.batches, len(cards))
+
+	var result catalog.Result
+	for _, c := range cards {
+		if r.known[c.Set.Code+" "+c.Number] {
+			result.Updated++
+		} else {
+			result.Added++
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,86 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/konstantinfoerster/card-service-go/internal/aerrors"
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+)
+
+// Entry a card image together with the card it shows, the image URL is the path in the image store.
+type Entry struct {
+	ImageID int
+	Card    cards.Card
+}
+
+type ExportRepository interface {
+	// Entries returns the card images with an id greater than afterID ordered by their id.
+	Entries(ctx context.Context, afterID int, limit int) ([]Entry, error)
+}
+
+// Exporter writes the card images with their cards in the json format of the card seed, the format
+// read by the detection evaluation.
+type Exporter struct {
+	repo ExportRepository
+}
+
+func NewExporter(repo ExportRepository) *Exporter {
+	return &Exporter{
+		repo: repo,
+	}
+}
+
+// Run writes all entries as json array, the entries are read in batches of the given size. Returns the number
+// of written entries.
+func (e *Exporter) Run(ctx context.Context, w io.Writer, batchSize int) (int, error) {
+	batchSize = max(batchSize, 1)
+
+	if _, err := io.WriteString(w, "["); err != nil {
+		return 0, fmt.Errorf("failed to write export %w", err)
+	}
+
+	written, afterID := 0, 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return written, err
+		}
+
+		entries, err := e.repo.Entries(ctx, afterID, batchSize)
+		if err != nil {
+			return written, aerrors.NewUnknownError(err, "catalog-export-failed")
+		}
+		for _, entry := range entries {
+			data, err := json.Marshal(entry.Card)
+			if err != nil {
+				return written, fmt.Errorf("failed to encode card %w", err)
+			}
+			sep := ",\n  "
+			if written == 0 {
+				sep = "\n  "
+			}
+			if _, err := io.WriteString(w, sep+string(data)); err != nil {
+				return written, fmt.Errorf("failed to write export %w", err)
+			}
+			written++
+			afterID = entry.ImageID
+		}
+		if len(entries) < batchSize {
+			break
+		}
+	}
+
+	end := "\n]\n"
+	if written == 0 {
+		end = "]\n"
+	}
+	if _, err := io.WriteString(w, end); err != nil {
+		return written, fmt.Errorf("failed to write export %w", err)
+	}
+	slog.Info("catalog export finished", slog.Int("cards", written))
+
+	return written, nil
+}
@@ -0,0 +1,64 @@
+package catalog_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/catalog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExporter(t *testing.T) {
+	repo := fakeExportRepository{
+		{ImageID: 2, Card: exportCard(1, "first.jpg")},
+		{ImageID: 5, Card: exportCard(2, "second.jpg")},
+		{ImageID: 7, Card: exportCard(3, "third.jpg")},
+	}
+	var out bytes.Buffer
+
+	written, err := catalog.NewExporter(repo).Run(context.Background(), &out, 2)
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, written)
+	var got []cards.Card
+	require.NoError(t, json.Unmarshal(out.Bytes(), &got))
+	want := []cards.Card{exportCard(1, "first.jpg"), exportCard(2, "second.jpg"), exportCard(3, "third.jpg")}
+	assert.Equal(t, want, got)
+}
+
+func TestExporterEmpty(t *testing.T) {
+	var out bytes.Buffer
+
+	written, err := catalog.NewExporter(fakeExportRepository{}).Run(context.Background(), &out, 10)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, written)
+	assert.Equal(t, "[]\n", out.String())
+}
+
+func exportCard(id int, path string) cards.Card {
+	return cards.Card{
+		ID:     cards.NewID(id).WithFace(id),
+		Name:   "Card",
+		Number: "1",
+		Set:    cards.Set{Name: "Dominaria", Code: "DOM"},
+		Image:  cards.Image{URL: path},
+	}
+}
+
+type fakeExportRepository []catalog.Entry
+
+func (r fakeExportRepository) Entries(_ context.Context, afterID int, limit int) ([]catalog.Entry, error) {
+	result := make([]catalog.Entry, 0, limit)
+	for _, e := range r {
+		if e.ImageID > afterID && len(result) < limit {
+			result = append(result, e)
+		}
+	}
+
+	return result, nil
+}
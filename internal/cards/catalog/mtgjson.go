@@ -0,0 +1,240 @@
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// twoImageLayouts the layouts with an own image per face, all other cards show all faces on the front.
+//
+//nolint:gochecknoglobals
+var twoImageLayouts = []string{"TRANSFORM", "MODAL_DFC", "REVERSIBLE_CARD"}
+
+type mtgjsonSet struct {
+	Code         string            `json:"code"`
+	Name         string            `json:"name"`
+	Type         string            `json:"type"`
+	ReleaseDate  string            `json:"releaseDate"`
+	Block        string            `json:"block"`
+	Translations map[string]string `json:"translations"`
+	Cards        []mtgjsonCard     `json:"cards"`
+}
+
+type mtgjsonCard struct {
+	Name        string   `json:"name"`
+	FaceName    string   `json:"faceName"`
+	Number      string   `json:"number"`
+	Side        string   `json:"side"`
+	Rarity      string   `json:"rarity"`
+	BorderColor string   `json:"borderColor"`
+	Layout      string   `json:"layout"`
+	Text        string   `json:"text"`
+	FlavorText  string   `json:"flavorText"`
+	Type        string   `json:"type"`
+	ManaCost    string   `json:"manaCost"`
+	ManaValue   float64  `json:"manaValue"`
+	Colors      []string `json:"colors"`
+	Artist      string   `json:"artist"`
+	Hand        string   `json:"hand"`
+	Life        string   `json:"life"`
+	Loyalty     string   `json:"loyalty"`
+	Power       string   `json:"power"`
+	Toughness   string   `json:"toughness"`
+	Supertypes  []string `json:"supertypes"`
+	Types       []string `json:"types"`
+	Subtypes    []string `json:"subtypes"`
+	Identifiers struct {
+		MultiverseID string `json:"multiverseId"`
+		ScryfallID   string `json:"scryfallId"`
+	} `json:"identifiers"`
+	ForeignData []struct {
+		Language    string `json:"language"`
+		Name        string `json:"name"`
+		FaceName    string `json:"faceName"`
+		Text        string `json:"text"`
+		FlavorText  string `json:"flavorText"`
+		Type        string `json:"type"`
+		Identifiers struct {
+			MultiverseID string `json:"multiverseId"`
+		} `json:"identifiers"`
+	} `json:"foreignData"`
+}
+
+// MTGJSONReader reads the cards of an MTGJSON AllPrintings file set by set. The faces of a card are separate
+// entries with the same number, the english card contains the texts of all other languages.
+type MTGJSONReader struct {
+	dec     *json.Decoder
+	started bool
+	pending []Card
+}
+
+func NewMTGJSONReader(in io.Reader) *MTGJSONReader {
+	return &MTGJSONReader{
+		dec: json.NewDecoder(in),
+	}
+}
+
+func (r *MTGJSONReader) Next() (Card, error) {
+	if !r.started {
+		if err := r.start(); err != nil {
+			return Card{}, err
+		}
+		r.started = true
+	}
+
+	for len(r.pending) == 0 {
+		if !r.dec.More() {
+			return Card{}, io.EOF
+		}
+		// the set code key is followed by the set
+		if _, err := r.dec.Token(); err != nil {
+			return Card{}, fmt.Errorf("failed to read set code %w", err)
+		}
+		var s mtgjsonSet
+		if err := r.dec.Decode(&s); err != nil {
+			return Card{}, fmt.Errorf("failed to decode mtgjson set %w", err)
+		}
+		r.pending = s.toCards()
+	}
+
+	card := r.pending[0]
+	r.pending = r.pending[1:]
+
+	return card, nil
+}
+
+// start moves the decoder to the first set of the data object, all other keys like meta are skipped.
+func (r *MTGJSONReader) start() error {
+	if err := expectDelim(r.dec, '{'); err != nil {
+		return err
+	}
+	for r.dec.More() {
+		key, err := r.dec.Token()
+		if err != nil {
+			return fmt.Errorf("failed to read json key %w", err)
+		}
+		if key == "data" {
+			return expectDelim(r.dec, '{')
+		}
+
+		var skip json.RawMessage
+		if err := r.dec.Decode(&skip); err != nil {
+			return fmt.Errorf("failed to skip %v %w", key, err)
+		}
+	}
+
+	return fmt.Errorf("%w, missing data", ErrUnknownFormat)
+}
+
+func (s mtgjsonSet) toCards() []Card {
+	released, _ := time.Parse(time.DateOnly, s.ReleaseDate)
+	set := Set{
+		Code:     strings.ToUpper(s.Code),
+		Name:     s.Name,
+		Type:     enumValue(s.Type),
+		Released: released,
+		Block:    s.Block,
+	}
+	for name, translated := range s.Translations {
+		if lang := language(name); lang != "" && translated != "" {
+			set.Translations = append(set.Translations, SetTranslation{Lang: lang, Name: translated})
+		}
+	}
+	slices.SortFunc(set.Translations, func(a, b SetTranslation) int { return strings.Compare(a.Lang, b.Lang) })
+
+	result := make([]Card, 0, len(s.Cards))
+	byNumber := make(map[string]int, len(s.Cards))
+	for _, c := range s.Cards {
+		i, ok := byNumber[c.Number]
+		if !ok {
+			i = len(result)
+			byNumber[c.Number] = i
+			result = append(result, Card{
+				Set:    set,
+				Name:   c.Name,
+				Number: c.Number,
+				Rarity: enumValue(c.Rarity),
+				Border: enumValue(c.BorderColor),
+				Layout: enumValue(c.Layout),
+				Lang:   english,
+			})
+		}
+		card := &result[i]
+
+		face := c.toFace()
+		card.Faces = append(card.Faces, face)
+
+		faceIdx := len(card.Faces) - 1
+		back := c.Side != "" && c.Side != "a"
+		if !back || slices.Contains(twoImageLayouts, card.Layout) {
+			if p := scryfallImagePath(c.Identifiers.ScryfallID, back); p != "" {
+				card.Images = append(card.Images,
+					Image{Face: faceIdx, Lang: english, Path: p, MimeType: imageMimeType})
+			}
+		}
+	}
+
+	return result
+}
+
+func (c mtgjsonCard) toFace() Face {
+	name := c.FaceName
+	if name == "" {
+		name = c.Name
+	}
+
+	face := Face{
+		Name:              name,
+		Text:              c.Text,
+		FlavorText:        c.FlavorText,
+		TypeLine:          c.Type,
+		ManaCost:          c.ManaCost,
+		ConvertedManaCost: c.ManaValue,
+		Colors:            c.Colors,
+		Artist:            c.Artist,
+		HandModifier:      c.Hand,
+		LifeModifier:      c.Life,
+		Loyalty:           c.Loyalty,
+		Power:             c.Power,
+		Toughness:         c.Toughness,
+		MultiverseID:      atoi(c.Identifiers.MultiverseID),
+		SuperTypes:        c.Supertypes,
+		Types:             c.Types,
+		SubTypes:          c.Subtypes,
+	}
+	for _, f := range c.ForeignData {
+		lang := language(f.Language)
+		if lang == "" || lang == english {
+			continue
+		}
+		translated := f.FaceName
+		if translated == "" {
+			translated = f.Name
+		}
+		face.Translations = append(face.Translations, Translation{
+			Lang:         lang,
+			Name:         translated,
+			Text:         f.Text,
+			FlavorText:   f.FlavorText,
+			TypeLine:     f.Type,
+			MultiverseID: atoi(f.Identifiers.MultiverseID),
+		})
+	}
+
+	return face
+}
+
+// atoi returns 0 for invalid numbers, MTGJSON stores the multiverse id as string.
+func atoi(v string) int {
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0
+	}
+
+	return n
+}
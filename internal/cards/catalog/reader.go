@@ -0,0 +1,116 @@
+package catalog
+
+import (
+	"bufio"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// superTypes the super types of a type line, all other types before the dash are card types.
+//
+//nolint:gochecknoglobals
+var superTypes = []string{"Basic", "Elite", "Host", "Legendary", "Ongoing", "Snow", "World"}
+
+// languages maps the language codes of Scryfall and the language names of MTGJSON to the database languages.
+//
+//nolint:gochecknoglobals
+var languages = map[string]string{
+	"en": "eng", "English": "eng",
+	"de": "deu", "German": "deu",
+	"fr": "fra", "French": "fra",
+	"it": "ita", "Italian": "ita",
+	"es": "spa", "Spanish": "spa",
+	"pt": "por", "Portuguese (Brazil)": "por",
+	"ja": "jpn", "Japanese": "jpn",
+	"ko": "kor", "Korean": "kor",
+	"ru": "rus", "Russian": "rus",
+	"zhs": "zhs", "Chinese Simplified": "zhs",
+	"zht": "zht", "Chinese Traditional": "zht",
+	"he": "heb", "Hebrew": "heb",
+	"la": "lat", "Latin": "lat",
+	"grc": "grc", "Ancient Greek": "grc",
+	"ar": "ara", "Arabic": "ara",
+	"sa": "san", "Sanskrit": "san",
+	"ph": "phy", "Phyrexian": "phy",
+}
+
+// NewReader detects the format of the bulk file, a Scryfall bulk file is a json array of cards and an
+// MTGJSON AllPrintings file a json object of sets.
+func NewReader(in io.Reader) (Reader, error) {
+	r := bufio.NewReader(in)
+	for {
+		b, err := r.Peek(1)
+		if err != nil {
+			return nil, ErrUnknownFormat
+		}
+		if unicode.IsSpace(rune(b[0])) {
+			if _, err := r.Discard(1); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		switch b[0] {
+		case '[':
+			return NewScryfallReader(r), nil
+		case '{':
+			return NewMTGJSONReader(r), nil
+		default:
+			return nil, ErrUnknownFormat
+		}
+	}
+}
+
+// language returns the database language or an empty string if the language is unknown.
+func language(code string) string {
+	return languages[code]
+}
+
+// enumValue converts the lower case values of the bulk files to the database enum values.
+func enumValue(v string) string {
+	return strings.ToUpper(strings.ReplaceAll(v, " ", "_"))
+}
+
+// splitTypeLine splits a type line like "Legendary Creature — Human Wizard" into its super, card and sub types.
+func splitTypeLine(typeLine string) ([]string, []string, []string) {
+	main, sub, _ := strings.Cut(typeLine, "—")
+
+	var supers, types []string
+	for _, t := range strings.Fields(main) {
+		if slices.Contains(superTypes, t) {
+			supers = append(supers, t)
+		} else {
+			types = append(types, t)
+		}
+	}
+
+	return supers, types, strings.Fields(sub)
+}
+
+// imagePath returns the path of the image URL without the query, the image paths of both formats are relative
+// to the Scryfall image host https://cards.scryfall.io/.
+func imagePath(imageURL string) string {
+	u, err := url.Parse(imageURL)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimPrefix(u.Path, "/")
+}
+
+// scryfallImagePath returns the path of the normal image of the side of the Scryfall card.
+func scryfallImagePath(scryfallID string, back bool) string {
+	if len(scryfallID) < 2 {
+		return ""
+	}
+	side := "front"
+	if back {
+		side = "back"
+	}
+
+	return "normal/" + side + "/" + scryfallID[0:1] + "/" + scryfallID[1:2] + "/" + scryfallID + ".jpg"
+}
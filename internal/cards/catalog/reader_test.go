@@ -0,0 +1,160 @@
+package catalog_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/konstantinfoerster/card-service-go/internal/cards/catalog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewReaderDetectsFormat(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+		want    any
+	}{
+		{name: "scryfall", content: " \n[]", want: &catalog.ScryfallReader{}},
+		{name: "mtgjson", content: `{"data": {}}`, want: &catalog.MTGJSONReader{}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := catalog.NewReader(strings.NewReader(tc.content))
+
+			require.NoError(t, err)
+			assert.IsType(t, tc.want, r)
+		})
+	}
+}
+
+func TestNewReaderUnknownFormat(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+	}{
+		{name: "empty", content: ""},
+		{name: "csv", content: "name,set\n"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := catalog.NewReader(strings.NewReader(tc.content))
+
+			require.ErrorIs(t, err, catalog.ErrUnknownFormat)
+		})
+	}
+}
+
+func TestScryfallReader(t *testing.T) {
+	cards := readAll(t, "testdata/scryfall.json")
+
+	require.Len(t, cards, 4)
+	elves := cards[0]
+	assert.Equal(t, catalog.Set{
+		Code:     "DOM",
+		Name:     "Dominaria",
+		Type:     "EXPANSION",
+		Released: time.Date(2018, 4, 27, 0, 0, 0, 0, time.UTC),
+	}, elves.Set)
+	assert.Equal(t, "168", elves.Number)
+	assert.Equal(t, "COMMON", elves.Rarity)
+	assert.Equal(t, "BLACK", elves.Border)
+	assert.Equal(t, "NORMAL", elves.Layout)
+	assert.True(t, elves.English())
+	require.Len(t, elves.Faces, 1)
+	assert.Equal(t, "Llanowar Elves", elves.Faces[0].Name)
+	assert.Equal(t, 442119, elves.Faces[0].MultiverseID)
+	assert.Equal(t, []string{"Creature"}, elves.Faces[0].Types)
+	assert.Equal(t, []string{"Elf", "Druid"}, elves.Faces[0].SubTypes)
+	assert.Empty(t, elves.Faces[0].Translations)
+	assert.Equal(t, []catalog.Image{{
+		Face:     0,
+		Lang:     "eng",
+		Path:     "normal/front/7/3/73542493-cd0b-4bb7-a5b8-8f889c76e4d6.jpg",
+		MimeType: "image/jpeg",
+	}}, elves.Images)
+
+	german := cards[1]
+	assert.Equal(t, "deu", german.Lang)
+	assert.Empty(t, german.Faces[0].FlavorText)
+	assert.Equal(t, []catalog.Translation{{
+		Lang:         "deu",
+		Name:         "Elfen von Llanowar",
+		Text:         "{T}: Erzeuge {G}.",
+		FlavorText:   "Ein gebrochener Knochen für jeden zertretenen Zweig.",
+		TypeLine:     "Kreatur — Elf, Druide",
+		MultiverseID: 443000,
+	}}, german.Faces[0].Translations)
+
+	delver := cards[2]
+	require.Len(t, delver.Faces, 2)
+	assert.Equal(t, "Insectile Aberration", delver.Faces[1].Name)
+	assert.Equal(t, 226755, delver.Faces[1].MultiverseID)
+	require.Len(t, delver.Images, 2)
+	assert.Equal(t, 1, delver.Images[1].Face)
+	assert.Equal(t, "normal/back/1/1/11bf83bb-c95b-4b4f-9a56-ce7a1816307a.jpg", delver.Images[1].Path)
+
+	require.ErrorIs(t, cards[3].Validate(), catalog.ErrUnsupportedCard)
+}
+
+func TestMTGJSONReader(t *testing.T) {
+	cards := readAll(t, "testdata/mtgjson.json")
+
+	require.Len(t, cards, 2)
+	delver := cards[0]
+	assert.Equal(t, "ISD", delver.Set.Code)
+	assert.Equal(t, "Innistrad", delver.Set.Block)
+	assert.Equal(t, []catalog.SetTranslation{
+		{Lang: "deu", Name: "Innistrad"},
+		{Lang: "fra", Name: "Innistrad"},
+	}, delver.Set.Translations)
+	assert.Equal(t, "Delver of Secrets // Insectile Aberration", delver.Name)
+	assert.Equal(t, "TRANSFORM", delver.Layout)
+	require.Len(t, delver.Faces, 2)
+	assert.Equal(t, "Delver of Secrets", delver.Faces[0].Name)
+	assert.Equal(t, 226749, delver.Faces[0].MultiverseID)
+	assert.Equal(t, []catalog.Translation{{
+		Lang:         "deu",
+		Name:         "Erforscher der Geheimnisse",
+		Text:         "Schau dir zu Beginn deines Versorgungssegments die oberste Karte deiner Bibliothek an.",
+		TypeLine:     "Kreatur — Mensch, Zauberer",
+		MultiverseID: 247321,
+	}}, delver.Faces[0].Translations)
+	assert.Equal(t, "Insectile Aberration", delver.Faces[1].Name)
+	assert.Equal(t, []catalog.Image{
+		{Face: 0, Lang: "eng", Path: "normal/front/1/1/11bf83bb-c95b-4b4f-9a56-ce7a1816307a.jpg", MimeType: "image/jpeg"},
+		{Face: 1, Lang: "eng", Path: "normal/back/1/1/11bf83bb-c95b-4b4f-9a56-ce7a1816307a.jpg", MimeType: "image/jpeg"},
+	}, delver.Images)
+
+	olivia := cards[1]
+	assert.Equal(t, "MYTHIC", olivia.Rarity)
+	require.Len(t, olivia.Faces, 1)
+	assert.Equal(t, []string{"Legendary"}, olivia.Faces[0].SuperTypes)
+	assert.Equal(t, []string{"B", "R"}, olivia.Faces[0].Colors)
+	assert.Equal(t, 0, olivia.Faces[0].MultiverseID)
+	require.NoError(t, olivia.Validate())
+}
+
+func readAll(t *testing.T, path string) []catalog.Card {
+	t.Helper()
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = f.Close() })
+	r, err := catalog.NewReader(f)
+	require.NoError(t, err)
+
+	var cards []catalog.Card
+	for {
+		c, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return cards
+		}
+		require.NoError(t, err)
+		cards = append(cards, c)
+	}
+}
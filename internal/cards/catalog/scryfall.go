@@ -0,0 +1,174 @@
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const imageMimeType = "image/jpeg"
+
+type scryfallCard struct {
+	Name          string             `json:"name"`
+	Lang          string             `json:"lang"`
+	Set           string             `json:"set"`
+	SetName       string             `json:"set_name"`
+	SetType       string             `json:"set_type"`
+	ReleasedAt    string             `json:"released_at"`
+	Number        string             `json:"collector_number"`
+	Rarity        string             `json:"rarity"`
+	BorderColor   string             `json:"border_color"`
+	Layout        string             `json:"layout"`
+	CMC           float64            `json:"cmc"`
+	MultiverseIDs []int              `json:"multiverse_ids"`
+	ImageURIs     map[string]string  `json:"image_uris"`
+	Faces         []scryfallCardFace `json:"card_faces"`
+	HandModifier  string             `json:"hand_modifier"`
+	LifeModifier  string             `json:"life_modifier"`
+	scryfallCardFace
+}
+
+type scryfallCardFace struct {
+	Name            string            `json:"name"`
+	ManaCost        string            `json:"mana_cost"`
+	TypeLine        string            `json:"type_line"`
+	OracleText      string            `json:"oracle_text"`
+	FlavorText      string            `json:"flavor_text"`
+	Colors          []string          `json:"colors"`
+	Artist          string            `json:"artist"`
+	Loyalty         string            `json:"loyalty"`
+	Power           string            `json:"power"`
+	Toughness       string            `json:"toughness"`
+	PrintedName     string            `json:"printed_name"`
+	PrintedText     string            `json:"printed_text"`
+	PrintedTypeLine string            `json:"printed_type_line"`
+	ImageURIs       map[string]string `json:"image_uris"`
+}
+
+// ScryfallReader reads the cards of a Scryfall bulk file, e.g. default cards or all cards. Every entry
+// is a print in a single language, prints in other languages than english add their printed text
+// as translation.
+type ScryfallReader struct {
+	dec     *json.Decoder
+	started bool
+}
+
+func NewScryfallReader(in io.Reader) *ScryfallReader {
+	return &ScryfallReader{
+		dec: json.NewDecoder(in),
+	}
+}
+
+func (r *ScryfallReader) Next() (Card, error) {
+	if !r.started {
+		if err := expectDelim(r.dec, '['); err != nil {
+			return Card{}, err
+		}
+		r.started = true
+	}
+	if !r.dec.More() {
+		return Card{}, io.EOF
+	}
+
+	var c scryfallCard
+	if err := r.dec.Decode(&c); err != nil {
+		return Card{}, fmt.Errorf("failed to decode scryfall card %w", err)
+	}
+
+	return c.toCard(), nil
+}
+
+func (c scryfallCard) toCard() Card {
+	lang := language(c.Lang)
+	released, _ := time.Parse(time.DateOnly, c.ReleasedAt)
+	card := Card{
+		Set: Set{
+			Code:     strings.ToUpper(c.Set),
+			Name:     c.SetName,
+			Type:     enumValue(c.SetType),
+			Released: released,
+		},
+		Name:   c.Name,
+		Number: c.Number,
+		Rarity: enumValue(c.Rarity),
+		Border: enumValue(c.BorderColor),
+		Layout: enumValue(c.Layout),
+		Lang:   lang,
+	}
+
+	faces := c.Faces
+	if len(faces) == 0 {
+		// the name and the image of the card are shadowed by the card fields
+		single := c.scryfallCardFace
+		single.Name = c.Name
+		faces = []scryfallCardFace{single}
+	}
+	for i, f := range faces {
+		supers, types, subs := splitTypeLine(f.TypeLine)
+		face := Face{
+			Name:              f.Name,
+			Text:              f.OracleText,
+			FlavorText:        f.FlavorText,
+			TypeLine:          f.TypeLine,
+			ManaCost:          f.ManaCost,
+			ConvertedManaCost: c.CMC,
+			Colors:            f.Colors,
+			Artist:            f.Artist,
+			HandModifier:      c.HandModifier,
+			LifeModifier:      c.LifeModifier,
+			Loyalty:           f.Loyalty,
+			Power:             f.Power,
+			Toughness:         f.Toughness,
+			SuperTypes:        supers,
+			Types:             types,
+			SubTypes:          subs,
+		}
+		if face.Colors == nil {
+			face.Colors = c.Colors
+		}
+		if face.Artist == "" {
+			face.Artist = c.Artist
+		}
+		if i < len(c.MultiverseIDs) {
+			face.MultiverseID = c.MultiverseIDs[i]
+		}
+		if !card.English() && f.PrintedName != "" {
+			// the flavor text is printed in the language of the card
+			face.FlavorText = ""
+			face.Translations = []Translation{{
+				Lang:         lang,
+				Name:         f.PrintedName,
+				Text:         f.PrintedText,
+				FlavorText:   f.FlavorText,
+				TypeLine:     f.PrintedTypeLine,
+				MultiverseID: face.MultiverseID,
+			}}
+		}
+		card.Faces = append(card.Faces, face)
+
+		if p := imagePath(f.ImageURIs["normal"]); p != "" {
+			card.Images = append(card.Images, Image{Face: i, Lang: lang, Path: p, MimeType: imageMimeType})
+		}
+	}
+
+	// cards with a single image for all faces, e.g. split or adventure cards
+	if p := imagePath(c.ImageURIs["normal"]); p != "" && len(card.Images) == 0 {
+		card.Images = append(card.Images, Image{Face: 0, Lang: lang, Path: p, MimeType: imageMimeType})
+	}
+
+	return card
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	t, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("failed to read json token %w", err)
+	}
+	if d, ok := t.(json.Delim); !ok || d != delim {
+		return fmt.Errorf("%w, expected %s but got %v", ErrUnknownFormat, delim, t)
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package cards
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/konstantinfoerster/card-service-go/internal/aerrors"
+)
+
+var ErrDebugUnsupported = errors.New("detector does not support debugging")
+
+// DebugImage an intermediate image of the detection, PNG encoded.
+type DebugImage struct {
+	Name string
+	PNG  []byte
+}
+
+// DebugDetector a detector that returns its intermediate images together with the detected cards.
+type DebugDetector interface {
+	DetectDebug(img io.Reader) ([]Detectable, []DebugImage, error)
+}
+
+// DebugCandidate a detected card with everything used to find its matches.
+type DebugCandidate struct {
+	Box Box
+	// Hashes the hash of every orientation for every algorithm.
+	Hashes []Hash
+	// Scores the best scores of all orientations, before the cards are loaded and re-ranked.
+	Scores Scores
+	// Orientation the clockwise rotation of the best score.
+	Orientation Degree
+	Matches     Matches
+}
+
+// DebugResult the intermediate images of the detection and the details of every candidate.
+type DebugResult struct {
+	Stages     []DebugImage
+	Candidates []DebugCandidate
+}
+
+// Debug detects the cards like Detect, but also returns the intermediate images and the hashes and scores
+// of every candidate. Requires a detector that implements DebugDetector.
+func (s *DetectService) Debug(ctx context.Context, c Collector, in io.Reader) (DebugResult, error) {
+	detector, ok := s.detector.(DebugDetector)
+	if !ok {
+		return DebugResult{}, aerrors.NewInvalidInputError(ErrDebugUnsupported, "debug-unsupported",
+			"the detector does not support debugging")
+	}
+
+	result, stages, err := detector.DetectDebug(in)
+	if err != nil {
+		if errors.Is(err, ErrInvalidImage) {
+			return DebugResult{}, aerrors.NewInvalidInputError(err, "invalid-file",
+				"unsupported image, supported formats are JPEG, PNG, GIF and WebP")
+		}
+
+		return DebugResult{}, aerrors.NewUnknownError(err, "detection-failed")
+	}
+
+	candidates := make([]DebugCandidate, 0, len(result))
+	for _, r := range result {
+		found, err := s.search(ctx, r)
+		if err != nil {
+			return DebugResult{}, err
+		}
+
+		matches, err := s.matchFound(ctx, c, r, found)
+		if err != nil {
+			return DebugResult{}, err
+		}
+
+		candidates = append(candidates, DebugCandidate{
+			Box:         r.Box(),
+			Hashes:      found.hashes,
+			Scores:      found.scores,
+			Orientation: found.orientation,
+			Matches:     matches,
+		})
+	}
+
+	return DebugResult{Stages: stages, Candidates: candidates}, nil
+}
@@ -1,13 +1,27 @@
 package cards
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"slices"
+	"time"
 
 	"github.com/konstantinfoerster/card-service-go/internal/aerrors"
 )
 
+var (
+	// ErrInvalidImage detectors return this error if the input is not an image in a supported format.
+	ErrInvalidImage         = errors.New("invalid image")
+	ErrUnknownHashAlgorithm = errors.New("unknown hash algorithm")
+	ErrUnknownScoring       = errors.New("unknown scoring")
+)
+
+// maxMatches the number of matches returned per detected card.
+const maxMatches = 5
+
 type Score struct {
 	ID    ID
 	Score int
@@ -53,9 +67,152 @@ func EmptyMatches(p Page) Matches {
 	}
 }
 
+// Box the position of a detected card inside the analyzed image. All values are relative
+// to the image size, between 0 and 1, so they can be applied to a scaled version of the image.
+type Box struct {
+	X      float64
+	Y      float64
+	Width  float64
+	Height float64
+}
+
+// FullBox a box that covers the whole image.
+func FullBox() Box {
+	return Box{Width: 1, Height: 1}
+}
+
+// DetectedRegion a single card found in the image together with the best matches for it.
+type DetectedRegion struct {
+	Box     Box
+	Matches Matches
+	// Orientation the clockwise rotation that turns the detected card upright.
+	Orientation Degree
+}
+
+// Best returns the match with the best score, false if the region has no matches.
+func (r DetectedRegion) Best() (Match, bool) {
+	if len(r.Matches.Result) == 0 {
+		return Match{}, false
+	}
+
+	return r.Matches.Result[0], true
+}
+
+// DetectionResult contains one region per card found in the image.
+type DetectionResult struct {
+	Regions []DetectedRegion
+}
+
+// CollectResult the regions of a detection split by whether the best match was added to the collection.
+type CollectResult struct {
+	// Added contains the regions whose best match was added, the amount of the match is the new amount.
+	Added []DetectedRegion
+	// Pending contains the regions without a good enough match, they need a manual confirmation.
+	Pending []DetectedRegion
+}
+
+type DetectConfig struct {
+	// CollectMaxScore the highest score of a best match that is added to the collection without
+	// confirmation, lower scores are better.
+	CollectMaxScore int `yaml:"collect_max_score"`
+	// IndexRefresh how often the hash index checks for new, updated or removed card image hashes.
+	IndexRefresh time.Duration `yaml:"index_refresh"`
+	// Hashes the algorithms used to find matching cards, only pHash is used if empty.
+	Hashes []HashConfig `yaml:"hashes"`
+	// Scoring how the scores of multiple hash algorithms are combined.
+	Scoring Scoring `yaml:"scoring"`
+	// Rerank the card regions used to reorder the prints of the same card.
+	Rerank RerankConfig `yaml:"rerank"`
+}
+
+// HashConfigs returns the configured hash algorithms or pHash if none is configured.
+func (c DetectConfig) HashConfigs() []HashConfig {
+	if len(c.Hashes) == 0 {
+		return []HashConfig{{Algorithm: PHash, MaxDistance: DefaultMaxDistance}}
+	}
+
+	return c.Hashes
+}
+
+// Algorithms returns the configured hash algorithms.
+func (c DetectConfig) Algorithms() []HashAlgorithm {
+	algs := make([]HashAlgorithm, 0, len(c.HashConfigs()))
+	for _, h := range c.HashConfigs() {
+		algs = append(algs, h.Algorithm)
+	}
+
+	return algs
+}
+
+func (c DetectConfig) Validate() error {
+	for _, h := range c.HashConfigs() {
+		if !slices.Contains(HashAlgorithms(), h.Algorithm) {
+			return fmt.Errorf("%w %q", ErrUnknownHashAlgorithm, h.Algorithm)
+		}
+	}
+
+	switch c.Scoring {
+	case "", ScoringAny, ScoringCombined:
+	default:
+		return fmt.Errorf("%w %q", ErrUnknownScoring, c.Scoring)
+	}
+
+	return c.Rerank.Validate()
+}
+
+// DefaultMaxDistance hashes with a higher distance are not similar enough to be a match.
+const DefaultMaxDistance = 59
+
+// HashAlgorithm the algorithm used to compute the hash of an image.
+type HashAlgorithm string
+
+const (
+	// PHash perception hash based on the discrete cosine transform.
+	PHash HashAlgorithm = "phash"
+	// DHash difference hash based on the gradient between neighboring pixels.
+	DHash HashAlgorithm = "dhash"
+	// AHash average hash based on the mean brightness.
+	AHash HashAlgorithm = "ahash"
+	// WHash wavelet hash based on the haar wavelet transform.
+	WHash HashAlgorithm = "wavelet"
+)
+
+// HashAlgorithms returns all supported hash algorithms.
+func HashAlgorithms() []HashAlgorithm {
+	return []HashAlgorithm{PHash, DHash, AHash, WHash}
+}
+
+type HashConfig struct {
+	Algorithm HashAlgorithm `yaml:"algorithm"`
+	// MaxDistance hashes with a higher distance are not similar enough to be a match,
+	// DefaultMaxDistance is used if not set.
+	MaxDistance int `yaml:"max_distance"`
+}
+
+// Threshold returns the max distance or the default if it is not set.
+func (c HashConfig) Threshold() int {
+	if c.MaxDistance <= 0 {
+		return DefaultMaxDistance
+	}
+
+	return c.MaxDistance
+}
+
+// Scoring defines how the scores of multiple hash algorithms are combined.
+type Scoring string
+
+const (
+	// ScoringAny a card matches if any algorithm matches, the score is the lowest distance. This is the default.
+	ScoringAny Scoring = "any"
+	// ScoringCombined a card only matches if all algorithms match, the score is the sum of all distances.
+	// Results in fewer false positives, but the scores are higher than with a single algorithm.
+	ScoringCombined Scoring = "combined"
+)
+
 type Hash struct {
-	Value []uint64
-	Bits  int
+	Algorithm HashAlgorithm
+	Value     []uint64
+	Bits      int
 }
 
 func (h Hash) AsBase2() []string {
@@ -69,6 +226,8 @@ func (h Hash) AsBase2() []string {
 
 type DetectRepository interface {
 	Top5MatchesByHash(ctx context.Context, hashes ...Hash) (Scores, error)
+	// RegionHashes returns the stored region hashes of the cards computed with the given algorithm.
+	RegionHashes(ctx context.Context, alg HashAlgorithm, ids ...ID) (map[ID][]RegionHash, error)
 }
 
 type Detector interface {
@@ -76,75 +235,253 @@ type Detector interface {
 }
 
 type DetectService struct {
-	cRepo    CardRepository
-	dRepo    DetectRepository
-	detector Detector
+	cfg         DetectConfig
+	cRepo       CardRepository
+	dRepo       DetectRepository
+	collectRepo CollectionRepository
+	detector    Detector
+	ocr         OCR
 }
 
-func NewDetectService(cRepo CardRepository, dRepo DetectRepository, detector Detector) *DetectService {
-	return &DetectService{
-		cRepo:    cRepo,
-		dRepo:    dRepo,
-		detector: detector,
+func NewDetectService(
+	cfg DetectConfig,
+	cRepo CardRepository,
+	dRepo DetectRepository,
+	collectRepo CollectionRepository,
+	detector Detector,
+	opts ...DetectOption,
+) *DetectService {
+	s := &DetectService{
+		cfg:         cfg,
+		cRepo:       cRepo,
+		dRepo:       dRepo,
+		collectRepo: collectRepo,
+		detector:    detector,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
 }
 
+// Degree a clockwise rotation in degrees.
 type Degree int
 
 const (
-	None Degree = iota
-	Degree90
-	Degree180
+	None      Degree = 0
+	Degree90  Degree = 90
+	Degree180 Degree = 180
+	Degree270 Degree = 270
 )
 
+// Orientations returns all rotations that are tried to turn a detected card upright, cards can be placed
+// sideways or upside down.
+func Orientations() []Degree {
+	return []Degree{None, Degree90, Degree180, Degree270}
+}
+
 type Detectable interface {
 	Rotate(angle Degree) Detectable
-	Hash() (Hash, error)
+	Hash(alg HashAlgorithm) (Hash, error)
+	// Crop returns the part of the detectable inside the box, relative to its size.
+	Crop(box Box) Detectable
+	Box() Box
 }
 
-func (s *DetectService) Detect(ctx context.Context, c Collector, in io.Reader) (Matches, error) {
+// Detect finds all cards in the image and searches the best matches for each of them.
+func (s *DetectService) Detect(ctx context.Context, c Collector, in io.Reader) (DetectionResult, error) {
 	result, dErr := s.detector.Detect(in)
 	if dErr != nil {
-		return Matches{}, aerrors.NewUnknownError(dErr, "detection-failed")
+		if errors.Is(dErr, ErrInvalidImage) {
+			return DetectionResult{}, aerrors.NewInvalidInputError(dErr, "invalid-file",
+				"unsupported image, supported formats are JPEG, PNG, GIF and WebP")
+		}
+
+		return DetectionResult{}, aerrors.NewUnknownError(dErr, "detection-failed")
 	}
 
-	hashes := make([]Hash, 0)
+	regions := make([]DetectedRegion, 0, len(result))
 	for _, r := range result {
-		hash, err := r.Hash()
+		matches, orientation, err := s.match(ctx, c, r)
 		if err != nil {
-			return Matches{}, aerrors.NewUnknownError(err, "hashing-failed")
+			return DetectionResult{}, err
 		}
-		hashes = append(hashes, hash)
 
-		rhash, err := r.Rotate(Degree180).Hash()
+		regions = append(regions, DetectedRegion{
+			Box:         r.Box(),
+			Matches:     matches,
+			Orientation: orientation,
+		})
+	}
+
+	return DetectionResult{Regions: regions}, nil
+}
+
+// DetectAndCollect finds all cards in the image and adds one copy of the best match of each region
+// to the collection, if the score of the match is not above the configured threshold.
+func (s *DetectService) DetectAndCollect(ctx context.Context, c Collector, in io.Reader) (CollectResult, error) {
+	result, err := s.Detect(ctx, c, in)
+	if err != nil {
+		return CollectResult{}, err
+	}
+
+	collected := CollectResult{
+		Added:   make([]DetectedRegion, 0),
+		Pending: make([]DetectedRegion, 0),
+	}
+	// the same card can be found in multiple regions
+	amounts := make(map[ID]int)
+	for _, r := range result.Regions {
+		best, ok := r.Best()
+		if !ok || best.Confidence > s.cfg.CollectMaxScore {
+			collected.Pending = append(collected.Pending, r)
+
+			continue
+		}
+
+		amount, ok := amounts[best.ID]
+		if !ok {
+			amount = best.Amount
+		}
+		item, err := NewCollectable(best.ID, amount+1)
 		if err != nil {
-			return Matches{}, aerrors.NewUnknownError(err, "rotated-hashing-failed")
+			return CollectResult{}, err
 		}
-		hashes = append(hashes, rhash)
+
+		if err := s.collectRepo.Collect(ctx, item, c); err != nil {
+			return CollectResult{}, aerrors.NewUnknownError(err, "unable-to-collect-item")
+		}
+		amounts[best.ID] = item.Amount
+
+		r.Matches.Result[0].Amount = item.Amount
+		collected.Added = append(collected.Added, r)
+	}
+
+	return collected, nil
+}
+
+// match searches the cards that match the detectable in any orientation and returns them together with the
+// orientation of the best match.
+func (s *DetectService) match(ctx context.Context, c Collector, d Detectable) (Matches, Degree, error) {
+	found, err := s.search(ctx, d)
+	if err != nil {
+		return Matches{}, None, err
 	}
 
-	scores, err := s.dRepo.Top5MatchesByHash(ctx, hashes...)
+	matches, err := s.matchFound(ctx, c, d, found)
 	if err != nil {
-		return Matches{}, aerrors.NewUnknownError(err, "unable-to-execute-hash-search")
+		return Matches{}, None, err
 	}
 
-	if len(scores) == 0 {
+	return matches, found.orientation, nil
+}
+
+// matchFound loads the cards of the search result and re-ranks them.
+func (s *DetectService) matchFound(ctx context.Context, c Collector, d Detectable, found searchResult) (Matches, error) {
+	if len(found.scores) == 0 {
 		return EmptyMatches(DefaultPage()), nil
 	}
 
-	filter := NewFilter().WithCollector(c)
-	for _, s := range scores {
-		filter = filter.WithID(s.ID)
+	ids := make([]ID, 0, len(found.scores))
+	for _, s := range found.scores {
+		ids = append(ids, s.ID)
 	}
-	limit := 5
-	page := NewPage(1, limit)
+	filter := NewFilter().WithCollector(c).WithID(ids...)
+	page := NewPage(1, maxMatches)
 	cards, err := s.cRepo.Find(ctx, filter, page)
 	if err != nil {
 		return Matches{}, aerrors.NewUnknownError(err, "unable-to-execute-card-search-by-id")
 	}
 
-	matches := NewMatches(cards, scores, page)
+	matches := NewMatches(cards, found.scores, page)
 	matches.HasMore = false
+	slices.SortStableFunc(matches.Result, func(a, b Match) int {
+		return cmp.Compare(a.Confidence, b.Confidence)
+	})
+
+	upright := d.Rotate(found.orientation)
+	if s.cfg.Rerank.Enabled() {
+		matches.Result, err = s.rerank(ctx, upright, matches.Result)
+		if err != nil {
+			return Matches{}, err
+		}
+	}
+
+	if s.ocr != nil {
+		text, err := s.readText(ctx, upright)
+		if err != nil {
+			return Matches{}, err
+		}
+		matches.Result = applyText(text, matches.Result)
+	}
 
 	return matches, nil
 }
+
+// searchResult the scores of all orientations, every card only once with its best score.
+type searchResult struct {
+	scores Scores
+	// orientation the orientation with the best score.
+	orientation Degree
+	// hashes the hashes of all orientations for every algorithm.
+	hashes []Hash
+}
+
+// search runs the hash search for every orientation. A card can match in multiple orientations, only its best
+// score is kept.
+func (s *DetectService) search(ctx context.Context, d Detectable) (searchResult, error) {
+	best := make(map[ID]Score)
+	orientations := make(map[ID]Degree)
+	all := make([]Hash, 0, len(Orientations())*len(s.cfg.HashConfigs()))
+	for _, o := range Orientations() {
+		hashes, err := s.hashes(d.Rotate(o))
+		if err != nil {
+			return searchResult{}, err
+		}
+		all = append(all, hashes...)
+
+		scores, err := s.dRepo.Top5MatchesByHash(ctx, hashes...)
+		if err != nil {
+			return searchResult{}, aerrors.NewUnknownError(err, "unable-to-execute-hash-search")
+		}
+		for _, sc := range scores {
+			if b, ok := best[sc.ID]; ok && b.Score <= sc.Score {
+				continue
+			}
+			best[sc.ID] = sc
+			orientations[sc.ID] = o
+		}
+	}
+
+	scores := make(Scores, 0, len(best))
+	for _, sc := range best {
+		scores = append(scores, sc)
+	}
+	slices.SortFunc(scores, func(a, b Score) int {
+		return cmp.Or(cmp.Compare(a.Score, b.Score), cmp.Compare(a.ID.CardID, b.ID.CardID),
+			cmp.Compare(a.ID.FaceID, b.ID.FaceID))
+	})
+	scores = scores[:min(maxMatches, len(scores))]
+
+	orientation := None
+	if len(scores) > 0 {
+		orientation = orientations[scores[0].ID]
+	}
+
+	return searchResult{scores: scores, orientation: orientation, hashes: all}, nil
+}
+
+// hashes returns the hash of every configured algorithm.
+func (s *DetectService) hashes(d Detectable) ([]Hash, error) {
+	hashes := make([]Hash, 0, len(s.cfg.HashConfigs()))
+	for _, alg := range s.cfg.Algorithms() {
+		hash, err := d.Hash(alg)
+		if err != nil {
+			return nil, aerrors.NewUnknownError(err, "hashing-failed")
+		}
+		hashes = append(hashes, hash)
+	}
+
+	return hashes, nil
+}
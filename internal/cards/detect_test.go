@@ -0,0 +1,469 @@
+package cards_test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/imaging"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/memory"
+	"github.com/konstantinfoerster/card-service-go/internal/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectGroupsMatchesPerRegion(t *testing.T) {
+	ctx := context.Background()
+	seed, err := test.CardSeed()
+	require.NoError(t, err)
+	cRepo, err := memory.NewCardRepository(seed, nil)
+	require.NoError(t, err)
+	left := cards.Box{X: 0, Y: 0, Width: 0.5, Height: 1}
+	right := cards.Box{X: 0.5, Y: 0, Width: 0.5, Height: 1}
+	unknown := cards.Box{X: 0.25, Y: 0.25, Width: 0.1, Height: 0.1}
+	dRepo := fakeDetectRepository{
+		1: {{ID: cards.ID{CardID: 434, FaceID: 434}, Score: 10}, {ID: cards.ID{CardID: 706, FaceID: 706}, Score: 3}},
+		2: {{ID: cards.ID{CardID: 514, FaceID: 514}, Score: 5}},
+	}
+	detector := fakeDetector{
+		fakeDetectable{hash: 1, box: left},
+		fakeDetectable{hash: 2, box: right},
+		fakeDetectable{hash: 3, box: unknown},
+	}
+	svc := cards.NewDetectService(cards.DetectConfig{}, cRepo, dRepo, cRepo, detector)
+
+	result, err := svc.Detect(ctx, cards.NewCollector("myUser"), nil)
+
+	require.NoError(t, err)
+	require.Len(t, result.Regions, 3)
+	assert.Equal(t, left, result.Regions[0].Box)
+	assert.Equal(t, []int{3, 10}, confidences(result.Regions[0].Matches))
+	assert.Equal(t, right, result.Regions[1].Box)
+	assert.Equal(t, []int{5}, confidences(result.Regions[1].Matches))
+	assert.Equal(t, unknown, result.Regions[2].Box)
+	assert.Empty(t, result.Regions[2].Matches.Result)
+}
+
+func TestDetectAndCollect(t *testing.T) {
+	ctx := context.Background()
+	seed, err := test.CardSeed()
+	require.NoError(t, err)
+	cRepo, err := memory.NewCardRepository(seed, nil)
+	require.NoError(t, err)
+	attorney := cards.ID{CardID: 434, FaceID: 434}
+	hordes := cards.ID{CardID: 706, FaceID: 706}
+	dRepo := fakeDetectRepository{
+		1: {{ID: attorney, Score: 3}},
+		2: {{ID: hordes, Score: 8}},
+	}
+	detector := fakeDetector{
+		fakeDetectable{hash: 1, box: cards.FullBox()},
+		fakeDetectable{hash: 1, box: cards.FullBox()},
+		fakeDetectable{hash: 2, box: cards.FullBox()},
+		fakeDetectable{hash: 3, box: cards.FullBox()},
+	}
+	svc := cards.NewDetectService(cards.DetectConfig{CollectMaxScore: 5}, cRepo, dRepo, cRepo, detector)
+	collector := cards.NewCollector("myUser")
+
+	result, err := svc.DetectAndCollect(ctx, collector, nil)
+
+	require.NoError(t, err)
+	require.Len(t, result.Added, 2)
+	assert.Equal(t, 1, result.Added[0].Matches.Result[0].Amount)
+	assert.Equal(t, 2, result.Added[1].Matches.Result[0].Amount)
+	assert.Len(t, result.Pending, 2)
+	collected, err := cRepo.Find(ctx, cards.NewFilter().WithCollector(collector).WithOnlyCollected(), cards.NewPage(1, 10))
+	require.NoError(t, err)
+	require.Len(t, collected.Result, 1)
+	assert.Equal(t, attorney, collected.Result[0].ID)
+	assert.Equal(t, 2, collected.Result[0].Amount)
+}
+
+func TestDetectHashesWithAllAlgorithms(t *testing.T) {
+	ctx := context.Background()
+	cRepo, err := memory.NewCardRepository(nil, nil)
+	require.NoError(t, err)
+	dRepo := &recordingDetectRepository{}
+	cfg := cards.DetectConfig{Hashes: []cards.HashConfig{{Algorithm: cards.PHash}, {Algorithm: cards.WHash}}}
+	detector := fakeDetector{fakeDetectable{hash: 1, box: cards.FullBox()}}
+	svc := cards.NewDetectService(cfg, cRepo, dRepo, cRepo, detector)
+
+	_, err = svc.Detect(ctx, cards.NewCollector("myUser"), nil)
+
+	require.NoError(t, err)
+	// every algorithm for each of the four orientations
+	expected := []cards.HashAlgorithm{
+		cards.PHash, cards.WHash, cards.PHash, cards.WHash, cards.PHash, cards.WHash, cards.PHash, cards.WHash,
+	}
+	assert.Equal(t, expected, dRepo.algorithms)
+}
+
+func TestDetectFindsSidewaysCards(t *testing.T) {
+	ctx := context.Background()
+	seed, err := test.CardSeed()
+	require.NoError(t, err)
+	cRepo, err := memory.NewCardRepository(seed, nil)
+	require.NoError(t, err)
+	owl := cards.ID{CardID: 281, FaceID: 281}
+	hordes := cards.ID{CardID: 706, FaceID: 706}
+	// the hash of the fake detectable is increased by the rotation
+	dRepo := fakeDetectRepository{
+		1:   {{ID: owl, Score: 8}},
+		91:  {{ID: owl, Score: 2}},
+		181: {{ID: hordes, Score: 5}},
+	}
+	detector := fakeDetector{fakeDetectable{hash: 1, box: cards.FullBox()}}
+	svc := cards.NewDetectService(cards.DetectConfig{}, cRepo, dRepo, cRepo, detector)
+
+	result, err := svc.Detect(ctx, cards.NewCollector("myUser"), nil)
+
+	require.NoError(t, err)
+	require.Len(t, result.Regions, 1)
+	assert.Equal(t, cards.Degree90, result.Regions[0].Orientation)
+	require.Len(t, result.Regions[0].Matches.Result, 2)
+	assert.Equal(t, owl, result.Regions[0].Matches.Result[0].ID)
+	assert.Equal(t, []int{2, 5}, confidences(result.Regions[0].Matches))
+}
+
+func TestDetectReranksPrints(t *testing.T) {
+	ctx := context.Background()
+	seed, err := test.CardSeed()
+	require.NoError(t, err)
+	cRepo, err := memory.NewCardRepository(seed, nil)
+	require.NoError(t, err)
+	owl := cards.ID{CardID: 281, FaceID: 281}
+	owlReprint := cards.ID{CardID: 282, FaceID: 282}
+	hordes := cards.ID{CardID: 706, FaceID: 706}
+	dRepo := regionDetectRepository{
+		fakeDetectRepository: fakeDetectRepository{
+			1: {{ID: owl, Score: 3}, {ID: hordes, Score: 4}, {ID: owlReprint, Score: 5}},
+		},
+		regions: map[cards.ID][]cards.RegionHash{
+			owl:        {regionHash(cards.ArtRegion, 0xFF)},
+			owlReprint: {regionHash(cards.ArtRegion, 1)},
+			hordes:     {regionHash(cards.ArtRegion, 1)},
+		},
+	}
+	detector := fakeDetector{fakeDetectable{hash: 1, box: cards.FullBox()}}
+
+	cases := []struct {
+		name     string
+		rerank   cards.RerankConfig
+		expected []cards.ID
+	}{
+		{
+			name:     "disabled",
+			expected: []cards.ID{owl, hordes, owlReprint},
+		},
+		{
+			name:     "prints ordered by art",
+			rerank:   cards.RerankConfig{Regions: []cards.CardRegion{cards.ArtRegion}},
+			expected: []cards.ID{owlReprint, hordes, owl},
+		},
+		{
+			name:     "missing region hashes",
+			rerank:   cards.RerankConfig{Regions: []cards.CardRegion{cards.SymbolRegion}},
+			expected: []cards.ID{owl, hordes, owlReprint},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := cards.DetectConfig{Rerank: tc.rerank}
+			svc := cards.NewDetectService(cfg, cRepo, dRepo, cRepo, detector)
+
+			result, err := svc.Detect(ctx, cards.NewCollector("myUser"), nil)
+
+			require.NoError(t, err)
+			require.Len(t, result.Regions, 1)
+			ids := make([]cards.ID, 0)
+			for _, m := range result.Regions[0].Matches.Result {
+				ids = append(ids, m.ID)
+			}
+			assert.Equal(t, tc.expected, ids)
+		})
+	}
+}
+
+func TestDetectUsesOCR(t *testing.T) {
+	ctx := context.Background()
+	seed, err := test.CardSeed()
+	require.NoError(t, err)
+	cRepo, err := memory.NewCardRepository(seed, nil)
+	require.NoError(t, err)
+	owl := cards.ID{CardID: 281, FaceID: 281}
+	owlReprint := cards.ID{CardID: 282, FaceID: 282}
+	hordes := cards.ID{CardID: 706, FaceID: 706}
+	dRepo := fakeDetectRepository{
+		1: {{ID: owl, Score: 3}, {ID: hordes, Score: 4}, {ID: owlReprint, Score: 5}},
+	}
+	detector := fakeDetector{fakeDetectable{hash: 1, box: cards.FullBox()}}
+
+	cases := []struct {
+		name     string
+		ocr      cards.OCR
+		expected []cards.ID
+	}{
+		{
+			name:     "no ocr",
+			expected: []cards.ID{owl, hordes, owlReprint},
+		},
+		{
+			name:     "filtered by name",
+			ocr:      imaging.NewFakeOCR("Sage 0wl", ""),
+			expected: []cards.ID{owl, owlReprint},
+		},
+		{
+			name:     "boosted by collector number",
+			ocr:      imaging.NewFakeOCR("Sage Owl", "104★/350 R"),
+			expected: []cards.ID{owlReprint, owl},
+		},
+		{
+			name:     "collector number with leading zeros",
+			ocr:      imaging.NewFakeOCR("", "0104 M15 EN"),
+			expected: []cards.ID{owl, hordes, owlReprint},
+		},
+		{
+			name:     "unknown name",
+			ocr:      imaging.NewFakeOCR("Llanowar Elves", "1"),
+			expected: []cards.ID{owl, hordes, owlReprint},
+		},
+		{
+			name:     "nothing read",
+			ocr:      imaging.NewFakeOCR("", ""),
+			expected: []cards.ID{owl, hordes, owlReprint},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := cards.NewDetectService(cards.DetectConfig{}, cRepo, dRepo, cRepo, detector, cards.WithOCR(tc.ocr))
+
+			result, err := svc.Detect(ctx, cards.NewCollector("myUser"), nil)
+
+			require.NoError(t, err)
+			require.Len(t, result.Regions, 1)
+			ids := make([]cards.ID, 0)
+			for _, m := range result.Regions[0].Matches.Result {
+				ids = append(ids, m.ID)
+			}
+			assert.Equal(t, tc.expected, ids)
+		})
+	}
+}
+
+func TestDebug(t *testing.T) {
+	ctx := context.Background()
+	seed, err := test.CardSeed()
+	require.NoError(t, err)
+	cRepo, err := memory.NewCardRepository(seed, nil)
+	require.NoError(t, err)
+	attorney := cards.ID{CardID: 434, FaceID: 434}
+	dRepo := &countingDetectRepository{fakeDetectRepository: fakeDetectRepository{1: {{ID: attorney, Score: 3}}}}
+	stages := []cards.DebugImage{{Name: "edges", PNG: []byte("png")}}
+	detector := fakeDebugDetector{
+		fakeDetector: fakeDetector{fakeDetectable{hash: 1, box: cards.FullBox()}},
+		stages:       stages,
+	}
+	svc := cards.NewDetectService(cards.DetectConfig{}, cRepo, dRepo, cRepo, detector)
+
+	result, err := svc.Debug(ctx, cards.NewCollector("myUser"), nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, stages, result.Stages)
+	require.Len(t, result.Candidates, 1)
+	candidate := result.Candidates[0]
+	assert.Equal(t, cards.FullBox(), candidate.Box)
+	hashes := make([]cards.Hash, 0)
+	for _, o := range cards.Orientations() {
+		hashes = append(hashes, cards.Hash{Algorithm: cards.PHash, Value: []uint64{1 + uint64(o)}, Bits: 64})
+	}
+	assert.Equal(t, hashes, candidate.Hashes)
+	assert.Equal(t, cards.Scores{{ID: attorney, Score: 3}}, candidate.Scores)
+	assert.Equal(t, cards.None, candidate.Orientation)
+	assert.Equal(t, []int{3}, confidences(candidate.Matches))
+	// the hashes of every orientation are searched once
+	assert.Equal(t, len(cards.Orientations()), dRepo.searches)
+}
+
+func TestDebugUnsupportedDetector(t *testing.T) {
+	cRepo, err := memory.NewCardRepository(nil, nil)
+	require.NoError(t, err)
+	svc := cards.NewDetectService(cards.DetectConfig{}, cRepo, fakeDetectRepository{}, cRepo, fakeDetector{})
+
+	_, err = svc.Debug(context.Background(), cards.NewCollector("myUser"), nil)
+
+	require.ErrorIs(t, err, cards.ErrDebugUnsupported)
+}
+
+func TestDetectConfigValidate(t *testing.T) {
+	cases := []struct {
+		name     string
+		cfg      cards.DetectConfig
+		expected error
+	}{
+		{
+			name: "defaults",
+			cfg:  cards.DetectConfig{},
+		},
+		{
+			name: "all algorithms",
+			cfg: cards.DetectConfig{
+				Hashes: []cards.HashConfig{
+					{Algorithm: cards.PHash}, {Algorithm: cards.DHash}, {Algorithm: cards.AHash}, {Algorithm: cards.WHash},
+				},
+				Scoring: cards.ScoringCombined,
+			},
+		},
+		{
+			name:     "unknown algorithm",
+			cfg:      cards.DetectConfig{Hashes: []cards.HashConfig{{Algorithm: "md5"}}},
+			expected: cards.ErrUnknownHashAlgorithm,
+		},
+		{
+			name:     "unknown scoring",
+			cfg:      cards.DetectConfig{Scoring: "average"},
+			expected: cards.ErrUnknownScoring,
+		},
+		{
+			name:     "unknown rerank region",
+			cfg:      cards.DetectConfig{Rerank: cards.RerankConfig{Regions: []cards.CardRegion{"border"}}},
+			expected: cards.ErrUnknownCardRegion,
+		},
+		{
+			name: "unknown rerank algorithm",
+			cfg: cards.DetectConfig{
+				Rerank: cards.RerankConfig{Regions: []cards.CardRegion{cards.ArtRegion}, Algorithm: "md5"},
+			},
+			expected: cards.ErrUnknownHashAlgorithm,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.cfg.Validate()
+
+			require.ErrorIs(t, err, tc.expected)
+		})
+	}
+}
+
+func confidences(m cards.Matches) []int {
+	result := make([]int, 0, len(m.Result))
+	for _, r := range m.Result {
+		result = append(result, r.Confidence)
+	}
+
+	return result
+}
+
+type fakeDetector []cards.Detectable
+
+func (d fakeDetector) Detect(_ io.Reader) ([]cards.Detectable, error) {
+	return d, nil
+}
+
+type fakeDebugDetector struct {
+	fakeDetector
+	stages []cards.DebugImage
+}
+
+func (d fakeDebugDetector) DetectDebug(_ io.Reader) ([]cards.Detectable, []cards.DebugImage, error) {
+	return d.fakeDetector, d.stages, nil
+}
+
+type fakeDetectable struct {
+	hash  uint64
+	box   cards.Box
+	angle cards.Degree
+}
+
+func (d fakeDetectable) Rotate(angle cards.Degree) cards.Detectable {
+	d.angle = (d.angle + angle) % 360
+
+	return d
+}
+
+// Hash returns the hash increased by the rotation, e.g. 91 for the hash 1 rotated by 90 degree.
+func (d fakeDetectable) Hash(alg cards.HashAlgorithm) (cards.Hash, error) {
+	return cards.Hash{Algorithm: alg, Value: []uint64{d.hash + uint64(d.angle)}, Bits: 64}, nil
+}
+
+func (d fakeDetectable) Crop(_ cards.Box) cards.Detectable {
+	return d
+}
+
+func (d fakeDetectable) Box() cards.Box {
+	return d.box
+}
+
+// fakeDetectRepository returns the scores stored for the first value of the hash.
+type fakeDetectRepository map[uint64]cards.Scores
+
+func (r fakeDetectRepository) Top5MatchesByHash(_ context.Context, hashes ...cards.Hash) (cards.Scores, error) {
+	scores := make(cards.Scores, 0)
+	for _, h := range hashes {
+		scores = append(scores, r[h.Value[0]]...)
+	}
+
+	return scores, nil
+}
+
+func (r fakeDetectRepository) RegionHashes(
+	_ context.Context, _ cards.HashAlgorithm, _ ...cards.ID,
+) (map[cards.ID][]cards.RegionHash, error) {
+	return map[cards.ID][]cards.RegionHash{}, nil
+}
+
+// regionDetectRepository returns the stored region hashes of the requested cards.
+type regionDetectRepository struct {
+	fakeDetectRepository
+	regions map[cards.ID][]cards.RegionHash
+}
+
+func (r regionDetectRepository) RegionHashes(
+	_ context.Context, _ cards.HashAlgorithm, ids ...cards.ID,
+) (map[cards.ID][]cards.RegionHash, error) {
+	result := make(map[cards.ID][]cards.RegionHash)
+	for _, id := range ids {
+		result[id] = r.regions[id]
+	}
+
+	return result, nil
+}
+
+func regionHash(region cards.CardRegion, value uint64) cards.RegionHash {
+	return cards.RegionHash{
+		Region: region,
+		Hash:   cards.Hash{Algorithm: cards.PHash, Value: []uint64{value}, Bits: 64},
+	}
+}
+
+// countingDetectRepository counts the hash searches.
+type countingDetectRepository struct {
+	fakeDetectRepository
+	searches int
+}
+
+func (r *countingDetectRepository) Top5MatchesByHash(ctx context.Context, hashes ...cards.Hash) (cards.Scores, error) {
+	r.searches++
+
+	return r.fakeDetectRepository.Top5MatchesByHash(ctx, hashes...)
+}
+
+// recordingDetectRepository records the algorithms of all searched hashes and never finds a match.
+type recordingDetectRepository struct {
+	algorithms []cards.HashAlgorithm
+}
+
+func (r *recordingDetectRepository) Top5MatchesByHash(_ context.Context, hashes ...cards.Hash) (cards.Scores, error) {
+	for _, h := range hashes {
+		r.algorithms = append(r.algorithms, h.Algorithm)
+	}
+
+	return cards.Scores{}, nil
+}
+
+func (r *recordingDetectRepository) RegionHashes(
+	_ context.Context, _ cards.HashAlgorithm, _ ...cards.ID,
+) (map[cards.ID][]cards.RegionHash, error) {
+	return map[cards.ID][]cards.RegionHash{}, nil
+}
@@ -0,0 +1,232 @@
+package evaluation
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/konstantinfoerster/card-service-go/internal/aio"
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+)
+
+var ErrInvalidLabel = errors.New("invalid label, expected file name <card id>-<face id>[-description].<ext>")
+
+// Sample a labeled photo, the expected card is part of the file name.
+type Sample struct {
+	Path     string
+	Expected cards.ID
+}
+
+// LoadSamples reads all files of the directory as samples. The file name must start with the expected
+// card and face id separated by a dash, e.g. 434-434.jpg or 434-434-sideways.jpg.
+func LoadSamples(dir string) ([]Sample, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sample directory %w", err)
+	}
+
+	samples := make([]Sample, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+
+		id, err := parseLabel(e.Name())
+		if err != nil {
+			return nil, fmt.Errorf("%s, %w", e.Name(), err)
+		}
+		samples = append(samples, Sample{Path: filepath.Join(dir, e.Name()), Expected: id})
+	}
+
+	return samples, nil
+}
+
+func parseLabel(name string) (cards.ID, error) {
+	parts := strings.SplitN(strings.TrimSuffix(name, filepath.Ext(name)), "-", 3)
+	if len(parts) < 2 {
+		return cards.ID{}, ErrInvalidLabel
+	}
+
+	cardID, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return cards.ID{}, errors.Join(err, ErrInvalidLabel)
+	}
+	faceID, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return cards.ID{}, errors.Join(err, ErrInvalidLabel)
+	}
+
+	return cards.NewID(cardID).WithFace(faceID), nil
+}
+
+// Report the accuracy and timings of an evaluation run.
+type Report struct {
+	Samples      int     `json:"samples"`
+	Top1         int     `json:"top1"`
+	Top5         int     `json:"top5"`
+	Top1Accuracy float64 `json:"top1Accuracy"`
+	Top5Accuracy float64 `json:"top5Accuracy"`
+	// AvgConfidence the average confidence of the best match of all samples with a match, lower is better.
+	AvgConfidence float64        `json:"avgConfidence"`
+	Stages        []StageTiming  `json:"stages"`
+	Results       []SampleResult `json:"results"`
+}
+
+// SampleResult the outcome of a single sample.
+type SampleResult struct {
+	File     string   `json:"file"`
+	Expected cards.ID `json:"expected"`
+	// Best the best match of all regions, nil if nothing was found.
+	Best       *cards.ID `json:"best,omitempty"`
+	Confidence int       `json:"confidence"`
+	// Rank the position of the expected card in all matches ordered by confidence, 0 if not found.
+	Rank    int    `json:"rank"`
+	Regions int    `json:"regions"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Evaluator runs samples through the detection and measures the time of every stage.
+type Evaluator struct {
+	svc   *cards.DetectService
+	timer *timer
+}
+
+func New(
+	cfg cards.DetectConfig,
+	cRepo cards.CardRepository,
+	dRepo cards.DetectRepository,
+	detector cards.Detector,
+	opts ...cards.DetectOption,
+) *Evaluator {
+	t := newTimer()
+
+	return &Evaluator{
+		// the collection is not used by the detection
+		svc: cards.NewDetectService(cfg,
+			timedCardRepository{CardRepository: cRepo, timer: t},
+			timedDetectRepository{repo: dRepo, timer: t},
+			nil,
+			timedDetector{detector: detector, timer: t},
+			opts...,
+		),
+		timer: t,
+	}
+}
+
+// Run detects the cards of all samples. A failed sample is part of the report and does not stop the run.
+func (e *Evaluator) Run(ctx context.Context, samples []Sample) Report {
+	collector := cards.NewCollector("evaluation")
+	report := Report{
+		Samples: len(samples),
+		Results: make([]SampleResult, 0, len(samples)),
+	}
+
+	confidence := 0
+	matched := 0
+	for _, s := range samples {
+		result := e.run(ctx, collector, s)
+		report.Results = append(report.Results, result)
+
+		if result.Best != nil {
+			confidence += result.Confidence
+			matched++
+		}
+		if result.Rank == 1 {
+			report.Top1++
+		}
+		if result.Rank >= 1 && result.Rank <= 5 {
+			report.Top5++
+		}
+	}
+
+	if report.Samples > 0 {
+		report.Top1Accuracy = float64(report.Top1) / float64(report.Samples)
+		report.Top5Accuracy = float64(report.Top5) / float64(report.Samples)
+	}
+	if matched > 0 {
+		report.AvgConfidence = float64(confidence) / float64(matched)
+	}
+	report.Stages = e.timer.stages(report.Samples)
+
+	return report
+}
+
+func (e *Evaluator) run(ctx context.Context, collector cards.Collector, s Sample) SampleResult {
+	result := SampleResult{File: filepath.Base(s.Path), Expected: s.Expected}
+
+	in, err := os.Open(s.Path)
+	if err != nil {
+		result.Error = err.Error()
+
+		return result
+	}
+	defer aio.Close(in)
+
+	start := time.Now()
+	detection, err := e.svc.Detect(ctx, collector, in)
+	e.timer.track(stageTotal, start)
+	if err != nil {
+		result.Error = err.Error()
+
+		return result
+	}
+
+	result.Regions = len(detection.Regions)
+	matches := rank(detection)
+	if len(matches) > 0 {
+		result.Best = &matches[0].ID
+		result.Confidence = matches[0].Confidence
+	}
+	for i, m := range matches {
+		if m.ID.Eq(s.Expected) {
+			result.Rank = i + 1
+
+			break
+		}
+	}
+
+	return result
+}
+
+// rank returns the matches of all regions ordered by confidence, every card only once with its best confidence.
+func rank(detection cards.DetectionResult) []cards.Match {
+	result := make([]cards.Match, 0)
+	for _, r := range detection.Regions {
+		for _, m := range r.Matches.Result {
+			i := slices.IndexFunc(result, func(o cards.Match) bool { return o.ID.Eq(m.ID) })
+			if i < 0 {
+				result = append(result, m)
+			} else if m.Confidence < result[i].Confidence {
+				result[i] = m
+			}
+		}
+	}
+	slices.SortStableFunc(result, func(a, b cards.Match) int {
+		return cmp.Compare(a.Confidence, b.Confidence)
+	})
+
+	return result
+}
+
+// LoadCatalog reads the cards that can be detected from a json file, same format as the card seed.
+func LoadCatalog(path string) ([]cards.Card, error) {
+	raw, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read catalog %w", err)
+	}
+
+	var catalog []cards.Card
+	if err := json.Unmarshal(raw, &catalog); err != nil {
+		return nil, fmt.Errorf("failed to parse catalog %w", err)
+	}
+
+	return catalog, nil
+}
@@ -0,0 +1,109 @@
+package evaluation_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/evaluation"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/imaging"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/memory"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/storage"
+)
+
+func TestLoadSamples(t *testing.T) {
+	samples, err := evaluation.LoadSamples("testdata/samples")
+
+	require.NoError(t, err)
+	assert.Equal(t, []evaluation.Sample{
+		{Path: filepath.Join("testdata", "samples", "1-1-modified.jpg"), Expected: cards.NewID(1).WithFace(1)},
+		{Path: filepath.Join("testdata", "samples", "1-1.jpg"), Expected: cards.NewID(1).WithFace(1)},
+		{Path: filepath.Join("testdata", "samples", "2-1-unknown.jpg"), Expected: cards.NewID(2).WithFace(1)},
+	}, samples)
+}
+
+func TestLoadSamplesInvalidLabel(t *testing.T) {
+	cases := []struct {
+		name string
+		file string
+	}{
+		{name: "No face id", file: "1.jpg"},
+		{name: "Not a number", file: "card-1.jpg"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, tc.file), []byte("x"), 0o600))
+
+			_, err := evaluation.LoadSamples(dir)
+
+			require.ErrorIs(t, err, evaluation.ErrInvalidLabel)
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	dCfg := cards.DetectConfig{}
+	catalog, err := evaluation.LoadCatalog("testdata/catalog.json")
+	require.NoError(t, err)
+	cRepo, err := memory.NewCardRepository(catalog, nil)
+	require.NoError(t, err)
+	dRepo, err := memory.NewDetectRepository(catalog, storage.NewLocalStore("testdata"), dCfg)
+	require.NoError(t, err)
+	samples, err := evaluation.LoadSamples("testdata/samples")
+	require.NoError(t, err)
+
+	report := evaluation.New(dCfg, cRepo, dRepo, imaging.NewFakeDetector()).Run(context.Background(), samples)
+
+	assert.Equal(t, 3, report.Samples)
+	assert.Equal(t, 2, report.Top1)
+	assert.Equal(t, 2, report.Top5)
+	assert.InDelta(t, 2.0/3.0, report.Top1Accuracy, 0.001)
+	assert.InDelta(t, 2.0/3.0, report.Top5Accuracy, 0.001)
+	require.Len(t, report.Results, 3)
+	assert.Equal(t, 1, report.Results[1].Rank)
+	assert.Equal(t, 0, report.Results[1].Confidence)
+	assert.Equal(t, 0, report.Results[2].Rank)
+	calls := make(map[string]int, len(report.Stages))
+	for _, s := range report.Stages {
+		calls[s.Name] = s.Calls
+	}
+	// the re-ranking is disabled
+	assert.Equal(t, map[string]int{"detect": 3, "hash": 12, "lookup": 12, "search": 2, "rerank": 0, "total": 3}, calls)
+}
+
+func TestReportOutput(t *testing.T) {
+	best := cards.NewID(1).WithFace(1)
+	report := evaluation.Report{
+		Samples:      1,
+		Top1:         1,
+		Top5:         1,
+		Top1Accuracy: 1,
+		Top5Accuracy: 1,
+		Stages:       []evaluation.StageTiming{{Name: "detect", Calls: 1, TotalMs: 1.5, AvgMs: 1.5}},
+		Results: []evaluation.SampleResult{
+			{File: "1-1.jpg", Expected: cards.NewID(1).WithFace(1), Best: &best, Rank: 1, Regions: 1},
+		},
+	}
+
+	var md bytes.Buffer
+	err := report.WriteMarkdown(&md)
+
+	require.NoError(t, err)
+	assert.Contains(t, md.String(), "| 1 | 100.0% (1) | 100.0% (1) | 0.00 |")
+	assert.Contains(t, md.String(), "| detect | 1 | 1.50 | 1.50 |")
+	assert.Contains(t, md.String(), "| 1-1.jpg | 1-1 | 1-1 | 0 | 1 | 1 |  |")
+
+	raw, err := json.Marshal(report)
+	require.NoError(t, err)
+	var decoded evaluation.Report
+	require.NoError(t, json.Unmarshal(raw, &decoded))
+	assert.Equal(t, report, decoded)
+}
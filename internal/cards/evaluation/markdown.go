@@ -0,0 +1,46 @@
+package evaluation
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+)
+
+// WriteMarkdown writes the summary, the stage timings and the result of every sample as markdown tables.
+func (r Report) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+
+	b.WriteString("| Samples | Top-1 | Top-5 | Avg. confidence |\n")
+	b.WriteString("|---|---|---|---|\n")
+	fmt.Fprintf(&b, "| %d | %.1f%% (%d) | %.1f%% (%d) | %.2f |\n",
+		r.Samples, r.Top1Accuracy*100, r.Top1, r.Top5Accuracy*100, r.Top5, r.AvgConfidence)
+
+	b.WriteString("\n| Stage | Calls | Total ms | Avg. ms per sample |\n")
+	b.WriteString("|---|---|---|---|\n")
+	for _, s := range r.Stages {
+		fmt.Fprintf(&b, "| %s | %d | %.2f | %.2f |\n", s.Name, s.Calls, s.TotalMs, s.AvgMs)
+	}
+
+	b.WriteString("\n| File | Expected | Best | Confidence | Rank | Regions | Error |\n")
+	b.WriteString("|---|---|---|---|---|---|---|\n")
+	for _, s := range r.Results {
+		best := "-"
+		if s.Best != nil {
+			best = formatID(*s.Best)
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %d | %d | %d | %s |\n",
+			s.File, formatID(s.Expected), best, s.Confidence, s.Rank, s.Regions, s.Error)
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write report %w", err)
+	}
+
+	return nil
+}
+
+func formatID(id cards.ID) string {
+	return fmt.Sprintf("%d-%d", id.CardID, id.FaceID)
+}
@@ -0,0 +1,136 @@
+package evaluation
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+)
+
+const (
+	stageDetect = "detect"
+	stageHash   = "hash"
+	stageLookup = "lookup"
+	stageSearch = "search"
+	stageRerank = "rerank"
+	stageTotal  = "total"
+)
+
+// StageTiming the time spent in one stage of the detection.
+type StageTiming struct {
+	Name    string  `json:"name"`
+	Calls   int     `json:"calls"`
+	TotalMs float64 `json:"totalMs"`
+	// AvgMs the average time per sample.
+	AvgMs float64 `json:"avgMs"`
+}
+
+type timer struct {
+	calls map[string]int
+	total map[string]time.Duration
+	mu    sync.Mutex
+}
+
+func newTimer() *timer {
+	return &timer{
+		calls: make(map[string]int),
+		total: make(map[string]time.Duration),
+	}
+}
+
+func (t *timer) track(stage string, start time.Time) {
+	elapsed := time.Since(start)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.calls[stage]++
+	t.total[stage] += elapsed
+}
+
+// stages returns the timings of all stages in the order they are executed.
+func (t *timer) stages(samples int) []StageTiming {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	result := make([]StageTiming, 0, len(t.calls))
+	for _, name := range []string{stageDetect, stageHash, stageLookup, stageSearch, stageRerank, stageTotal} {
+		total := float64(t.total[name].Microseconds()) / 1000
+		s := StageTiming{Name: name, Calls: t.calls[name], TotalMs: total}
+		if samples > 0 {
+			s.AvgMs = total / float64(samples)
+		}
+		result = append(result, s)
+	}
+
+	return result
+}
+
+type timedDetector struct {
+	detector cards.Detector
+	timer    *timer
+}
+
+func (d timedDetector) Detect(img io.Reader) ([]cards.Detectable, error) {
+	start := time.Now()
+	result, err := d.detector.Detect(img)
+	d.timer.track(stageDetect, start)
+
+	detectables := make([]cards.Detectable, 0, len(result))
+	for _, r := range result {
+		detectables = append(detectables, timedDetectable{Detectable: r, timer: d.timer})
+	}
+
+	return detectables, err
+}
+
+type timedDetectable struct {
+	cards.Detectable
+	timer *timer
+}
+
+func (d timedDetectable) Rotate(angle cards.Degree) cards.Detectable {
+	return timedDetectable{Detectable: d.Detectable.Rotate(angle), timer: d.timer}
+}
+
+func (d timedDetectable) Hash(alg cards.HashAlgorithm) (cards.Hash, error) {
+	defer d.timer.track(stageHash, time.Now())
+
+	return d.Detectable.Hash(alg)
+}
+
+func (d timedDetectable) Crop(box cards.Box) cards.Detectable {
+	return timedDetectable{Detectable: d.Detectable.Crop(box), timer: d.timer}
+}
+
+type timedDetectRepository struct {
+	repo  cards.DetectRepository
+	timer *timer
+}
+
+func (r timedDetectRepository) Top5MatchesByHash(ctx context.Context, hashes ...cards.Hash) (cards.Scores, error) {
+	defer r.timer.track(stageLookup, time.Now())
+
+	return r.repo.Top5MatchesByHash(ctx, hashes...)
+}
+
+func (r timedDetectRepository) RegionHashes(
+	ctx context.Context, alg cards.HashAlgorithm, ids ...cards.ID,
+) (map[cards.ID][]cards.RegionHash, error) {
+	defer r.timer.track(stageRerank, time.Now())
+
+	return r.repo.RegionHashes(ctx, alg, ids...)
+}
+
+type timedCardRepository struct {
+	cards.CardRepository
+	timer *timer
+}
+
+func (r timedCardRepository) Find(ctx context.Context, filter cards.Filter, page cards.Page) (cards.Cards, error) {
+	defer r.timer.track(stageSearch, time.Now())
+
+	return r.CardRepository.Find(ctx, filter, page)
+}
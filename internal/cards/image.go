@@ -0,0 +1,148 @@
+package cards
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/konstantinfoerster/card-service-go/internal/aerrors"
+)
+
+var (
+	ErrImageNotFound    = errors.New("image not found")
+	ErrUnknownImageSize = errors.New("unknown image size")
+)
+
+// ImageSize a size in which card images are served, the original image is served for the OriginalSize.
+type ImageSize string
+
+const (
+	OriginalSize ImageSize = ""
+	// ListSize the thumbnail used in card lists.
+	ListSize ImageSize = "list"
+	// DetailSize the thumbnail used in the card details.
+	DetailSize ImageSize = "detail"
+)
+
+// ImageSizes returns all thumbnail sizes.
+func ImageSizes() []ImageSize {
+	return []ImageSize{ListSize, DetailSize}
+}
+
+// Width returns the width of the thumbnail in pixels, 0 for the original image.
+func (s ImageSize) Width() int {
+	switch s {
+	case ListSize:
+		return 146
+	case DetailSize:
+		return 488
+	default:
+		return 0
+	}
+}
+
+// ParseImageSize returns the size with the given name, an empty name is the original size.
+func ParseImageSize(name string) (ImageSize, error) {
+	size := ImageSize(name)
+	if size != OriginalSize && !slices.Contains(ImageSizes(), size) {
+		return OriginalSize, fmt.Errorf("%w %q", ErrUnknownImageSize, name)
+	}
+
+	return size, nil
+}
+
+// ImageFile a stored card image, the content is only set for opened images and must be closed by the caller.
+type ImageFile struct {
+	Content     io.ReadCloser
+	ContentType string
+	// Size the size in bytes, -1 if unknown.
+	Size int64
+	// ModTime the time of the last change, zero if unknown.
+	ModTime time.Time
+	// Tag identifies the content of the image, e.g. the ETag of the storage. Empty if the storage has none.
+	Tag string
+}
+
+// ETag returns a strong entity tag that changes whenever the file is replaced. Files without a tag are
+// identified by their modification time and size, an empty ETag is returned if one of them is unknown.
+func (f ImageFile) ETag() string {
+	if f.Tag != "" {
+		return fmt.Sprintf(`"%s"`, f.Tag)
+	}
+	if f.ModTime.IsZero() || f.Size < 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(`"%x-%x"`, f.ModTime.UnixNano(), f.Size)
+}
+
+// ImageStore stores card images and thumbnails by their path.
+type ImageStore interface {
+	// Open returns the image with its content, ErrImageNotFound if it does not exist.
+	Open(ctx context.Context, path string) (ImageFile, error)
+	// Stat returns the image without its content, ErrImageNotFound if it does not exist.
+	Stat(ctx context.Context, path string) (ImageFile, error)
+	// Put stores the image, an existing image with the same path is replaced.
+	Put(ctx context.Context, path string, data []byte, contentType string) error
+}
+
+type ImageRepository interface {
+	// Image returns the card image with the given id, ErrImageNotFound if it does not exist.
+	Image(ctx context.Context, id int) (CardImage, error)
+}
+
+// ImageFiles opens card images by their path, thumbnails are created on first access.
+type ImageFiles interface {
+	Open(ctx context.Context, path string, size ImageSize) (ImageFile, error)
+	Stat(ctx context.Context, path string, size ImageSize) (ImageFile, error)
+}
+
+// ImageService serves card images and their thumbnails.
+type ImageService struct {
+	repo  ImageRepository
+	files ImageFiles
+}
+
+func NewImageService(repo ImageRepository, files ImageFiles) *ImageService {
+	return &ImageService{
+		repo:  repo,
+		files: files,
+	}
+}
+
+// Image opens the card image with the given id in the given size.
+func (s *ImageService) Image(ctx context.Context, id int, size ImageSize) (ImageFile, error) {
+	return s.file(ctx, id, size, s.files.Open)
+}
+
+// Stat returns the card image with the given id in the given size without its content.
+func (s *ImageService) Stat(ctx context.Context, id int, size ImageSize) (ImageFile, error) {
+	return s.file(ctx, id, size, s.files.Stat)
+}
+
+func (s *ImageService) file(
+	ctx context.Context, id int, size ImageSize, load func(context.Context, string, ImageSize) (ImageFile, error),
+) (ImageFile, error) {
+	img, err := s.repo.Image(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrImageNotFound) {
+			return ImageFile{}, aerrors.NewNotFoundError(err, "image-not-found")
+		}
+
+		return ImageFile{}, aerrors.NewUnknownError(err, "unable-to-find-image")
+	}
+
+	f, err := load(ctx, img.Path, size)
+	if err != nil {
+		if errors.Is(err, ErrImageNotFound) {
+			return ImageFile{}, aerrors.NewNotFoundError(err, "image-not-found")
+		}
+
+		return ImageFile{}, aerrors.NewUnknownError(err, "unable-to-load-image")
+	}
+
+	return f, nil
+}
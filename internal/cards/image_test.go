@@ -0,0 +1,170 @@
+package cards_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/konstantinfoerster/card-service-go/internal/aerrors"
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errBrokenDisk = errors.New("broken disk")
+
+func TestParseImageSize(t *testing.T) {
+	cases := []struct {
+		name     string
+		expected cards.ImageSize
+	}{
+		{name: "", expected: cards.OriginalSize},
+		{name: "list", expected: cards.ListSize},
+		{name: "detail", expected: cards.DetailSize},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			size, err := cards.ParseImageSize(tc.name)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, size)
+		})
+	}
+}
+
+func TestParseUnknownImageSize(t *testing.T) {
+	_, err := cards.ParseImageSize("huge")
+
+	require.ErrorIs(t, err, cards.ErrUnknownImageSize)
+}
+
+func TestImage(t *testing.T) {
+	repo := memory.NewImageRepository([]cards.CardImage{{ID: 1, Path: "card.jpg"}})
+	svc := cards.NewImageService(repo, fakeImageFiles{"list/card.jpg": "thumbnail"})
+
+	f, err := svc.Image(context.Background(), 1, cards.ListSize)
+	require.NoError(t, err)
+	content, err := io.ReadAll(f.Content)
+
+	require.NoError(t, err)
+	assert.Equal(t, "thumbnail", string(content))
+}
+
+func TestImageStat(t *testing.T) {
+	repo := memory.NewImageRepository([]cards.CardImage{{ID: 1, Path: "card.jpg"}})
+	svc := cards.NewImageService(repo, fakeImageFiles{"list/card.jpg": "thumbnail"})
+
+	f, err := svc.Stat(context.Background(), 1, cards.ListSize)
+
+	require.NoError(t, err)
+	assert.Nil(t, f.Content)
+	assert.Equal(t, int64(9), f.Size)
+}
+
+func TestImageFileETag(t *testing.T) {
+	modTime := time.Unix(1700000000, 0)
+	cases := []struct {
+		name     string
+		file     cards.ImageFile
+		expected string
+	}{
+		{
+			name:     "tag of the storage",
+			file:     cards.ImageFile{Tag: "abc", Size: 4, ModTime: modTime},
+			expected: `"abc"`,
+		},
+		{
+			name:     "modification time and size",
+			file:     cards.ImageFile{Size: 4, ModTime: modTime},
+			expected: `"17979cfe362a0000-4"`,
+		},
+		{
+			name:     "unknown modification time",
+			file:     cards.ImageFile{Size: 4},
+			expected: "",
+		},
+		{
+			name:     "unknown size",
+			file:     cards.ImageFile{Size: -1, ModTime: modTime},
+			expected: "",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.file.ETag())
+		})
+	}
+}
+
+func TestImageErrors(t *testing.T) {
+	cases := []struct {
+		name        string
+		id          int
+		expectedKey string
+		expectedErr aerrors.ErrorType
+	}{
+		{
+			name:        "unknown image",
+			id:          99,
+			expectedKey: "image-not-found",
+			expectedErr: aerrors.ErrNotFound,
+		},
+		{
+			name:        "missing file",
+			id:          1,
+			expectedKey: "image-not-found",
+			expectedErr: aerrors.ErrNotFound,
+		},
+		{
+			name:        "unreadable file",
+			id:          2,
+			expectedKey: "unable-to-load-image",
+			expectedErr: aerrors.ErrUnknown,
+		},
+	}
+	repo := memory.NewImageRepository([]cards.CardImage{{ID: 1, Path: "missing.jpg"}, {ID: 2, Path: "broken.jpg"}})
+	svc := cards.NewImageService(repo, fakeImageFiles{})
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := svc.Image(context.Background(), tc.id, cards.OriginalSize)
+
+			var appErr aerrors.AppError
+			require.ErrorAs(t, err, &appErr)
+			assert.Equal(t, tc.expectedKey, appErr.Key)
+			assert.Equal(t, tc.expectedErr, appErr.ErrorType)
+		})
+	}
+}
+
+// fakeImageFiles the content of the images by their size and path, broken.jpg fails to load.
+type fakeImageFiles map[string]string
+
+func (f fakeImageFiles) Open(_ context.Context, path string, size cards.ImageSize) (cards.ImageFile, error) {
+	if path == "broken.jpg" {
+		return cards.ImageFile{}, errBrokenDisk
+	}
+	key := path
+	if size != cards.OriginalSize {
+		key = string(size) + "/" + path
+	}
+	content, ok := f[key]
+	if !ok {
+		return cards.ImageFile{}, cards.ErrImageNotFound
+	}
+
+	return cards.ImageFile{
+		Content: io.NopCloser(strings.NewReader(content)),
+		Size:    int64(len(content)),
+	}, nil
+}
+
+func (f fakeImageFiles) Stat(ctx context.Context, path string, size cards.ImageSize) (cards.ImageFile, error) {
+	file, err := f.Open(ctx, path, size)
+	file.Content = nil
+
+	return file, err
+}
@@ -0,0 +1,80 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+	"golang.org/x/image/draw"
+)
+
+// debugStages collects the intermediate images of a detection, a nil recorder ignores all images.
+type debugStages struct {
+	images []cards.DebugImage
+	err    error
+}
+
+func (s *debugStages) add(name string, img image.Image) {
+	if s == nil || s.err != nil {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		s.err = fmt.Errorf("failed to encode debug image %s %w", name, err)
+
+		return
+	}
+	s.images = append(s.images, cards.DebugImage{Name: name, PNG: buf.Bytes()})
+}
+
+// maskImage returns the marked pixels of the mask in white.
+func maskImage(mask []bool, width, height int) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for i, marked := range mask {
+		if marked {
+			img.Pix[i] = 255
+		}
+	}
+
+	return img
+}
+
+// outline returns a copy of the image with the outline of every quad drawn on top.
+func outline(src *image.RGBA, quads []quad) *image.RGBA {
+	dst := image.NewRGBA(src.Rect)
+	draw.Draw(dst, dst.Rect, src, src.Rect.Min, draw.Src)
+
+	c := color.RGBA{R: 255, A: 255}
+	for _, q := range quads {
+		for i := range q {
+			line(dst, q[i], q[(i+1)%len(q)], c)
+		}
+	}
+
+	return dst
+}
+
+// line draws a line that is three pixels wide.
+func line(img *image.RGBA, from, to point, c color.RGBA) {
+	steps := int(math.Ceil(math.Max(math.Abs(to.X-from.X), math.Abs(to.Y-from.Y))))
+	for s := 0; s <= steps; s++ {
+		t := 0.0
+		if steps > 0 {
+			t = float64(s) / float64(steps)
+		}
+		x := int(math.Round(from.X + (to.X-from.X)*t))
+		y := int(math.Round(from.Y + (to.Y-from.Y)*t))
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				if image.Pt(x+dx, y+dy).In(img.Rect) {
+					img.SetRGBA(x+dx, y+dy, c)
+				}
+			}
+		}
+	}
+}
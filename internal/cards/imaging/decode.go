@@ -0,0 +1,225 @@
+package imaging
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+	"golang.org/x/image/webp"
+)
+
+var ErrUnsupportedImage = errors.Join(errors.New("unsupported image format"), cards.ErrInvalidImage)
+
+const (
+	FormatJPEG = "image/jpeg"
+	FormatPNG  = "image/png"
+	FormatGIF  = "image/gif"
+	FormatWebP = "image/webp"
+	FormatHEIC = "image/heic"
+)
+
+// sniffLen the number of bytes needed to detect the format.
+const sniffLen = 512
+
+// Decode detects the format of the image and decodes it. JPEG images are rotated according to their
+// EXIF orientation. Returns ErrUnsupportedImage for any format other than JPEG, PNG, GIF and WebP.
+func Decode(in io.Reader) (image.Image, error) {
+	if in == nil {
+		return nil, ErrInvalidInput
+	}
+
+	r := bufio.NewReaderSize(in, sniffLen)
+	// an error means the input is shorter than sniffLen, the available bytes are still used
+	header, _ := r.Peek(sniffLen)
+	format := sniff(header)
+
+	var img image.Image
+	var err error
+	switch format {
+	case FormatJPEG:
+		// the exif data is part of the header segments, they are kept to read the orientation
+		var data []byte
+		if data, err = io.ReadAll(r); err != nil {
+			return nil, fmt.Errorf("failed to read image %w", err)
+		}
+		if img, err = jpeg.Decode(bytes.NewReader(data)); err == nil {
+			img = orient(img, orientation(data))
+		}
+	case FormatPNG:
+		img, err = png.Decode(r)
+	case FormatGIF:
+		img, err = gif.Decode(r)
+	case FormatWebP:
+		img, err = webp.Decode(r)
+	case FormatHEIC:
+		return nil, fmt.Errorf("no decoder for %s, %w", format, ErrUnsupportedImage)
+	default:
+		return nil, ErrUnsupportedImage
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s, %w", format, errors.Join(err, cards.ErrInvalidImage))
+	}
+
+	return img, nil
+}
+
+// DecodeConfig returns the dimensions of the image without decoding the image data, the EXIF
+// orientation is not applied. Returns ErrUnsupportedImage for the same formats as Decode.
+func DecodeConfig(in io.Reader) (image.Config, error) {
+	if in == nil {
+		return image.Config{}, ErrInvalidInput
+	}
+
+	r := bufio.NewReaderSize(in, sniffLen)
+	header, _ := r.Peek(sniffLen)
+	format := sniff(header)
+
+	var cfg image.Config
+	var err error
+	switch format {
+	case FormatJPEG:
+		cfg, err = jpeg.DecodeConfig(r)
+	case FormatPNG:
+		cfg, err = png.DecodeConfig(r)
+	case FormatGIF:
+		cfg, err = gif.DecodeConfig(r)
+	case FormatWebP:
+		cfg, err = webp.DecodeConfig(r)
+	case FormatHEIC:
+		return image.Config{}, fmt.Errorf("no decoder for %s, %w", format, ErrUnsupportedImage)
+	default:
+		return image.Config{}, ErrUnsupportedImage
+	}
+
+	if err != nil {
+		return image.Config{}, fmt.Errorf("failed to decode %s config, %w", format, errors.Join(err, cards.ErrInvalidImage))
+	}
+
+	return cfg, nil
+}
+
+// sniff returns the mime type of the image data or an empty string if it is not a known image format.
+func sniff(header []byte) string {
+	// HEIC files are ISO base media files with a heic brand, http.DetectContentType does not know them
+	if len(header) >= 12 && string(header[4:8]) == "ftyp" {
+		switch string(header[8:12]) {
+		case "heic", "heix", "hevc", "hevx", "heim", "heis", "mif1", "msf1":
+			return FormatHEIC
+		}
+	}
+
+	switch format := http.DetectContentType(header); format {
+	case FormatJPEG, FormatPNG, FormatGIF, FormatWebP:
+		return format
+	}
+
+	return ""
+}
+
+// orientation reads the EXIF orientation of the JPEG data, returns 1 (no transformation) if it is missing.
+func orientation(data []byte) int {
+	const noTransformation = 1
+	const exifHeaderLen = 6
+	const tagOrientation = 0x0112
+
+	// skip the start of image marker and walk through the segments until the image data starts
+	pos := 2
+	for pos+4 <= len(data) && data[pos] == 0xFF {
+		marker := data[pos+1]
+		length := int(binary.BigEndian.Uint16(data[pos+2 : pos+4]))
+		// the length includes the two length bytes, a shorter length is a malformed segment
+		if marker == 0xDA || length < 2 || pos+2+length > len(data) {
+			return noTransformation
+		}
+		segment := data[pos+4 : pos+2+length]
+		pos += 2 + length
+
+		if marker != 0xE1 || len(segment) < exifHeaderLen || string(segment[:exifHeaderLen]) != "Exif\x00\x00" {
+			continue
+		}
+
+		tiff := segment[exifHeaderLen:]
+		if len(tiff) < 8 {
+			return noTransformation
+		}
+		var order binary.ByteOrder
+		switch string(tiff[:2]) {
+		case "II":
+			order = binary.LittleEndian
+		case "MM":
+			order = binary.BigEndian
+		default:
+			return noTransformation
+		}
+
+		ifd := int(order.Uint32(tiff[4:8]))
+		if ifd+2 > len(tiff) {
+			return noTransformation
+		}
+		entries := int(order.Uint16(tiff[ifd : ifd+2]))
+		for i := range entries {
+			entry := ifd + 2 + i*12
+			if entry+12 > len(tiff) {
+				break
+			}
+			if order.Uint16(tiff[entry:entry+2]) == tagOrientation {
+				return int(order.Uint16(tiff[entry+8 : entry+10]))
+			}
+		}
+
+		return noTransformation
+	}
+
+	return noTransformation
+}
+
+// orient transforms the image so that it is shown upright for the given EXIF orientation.
+func orient(img image.Image, o int) image.Image {
+	if o < 2 || o > 8 {
+		return img
+	}
+
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+	dstWidth, dstHeight := width, height
+	// orientations 5 to 8 swap width and height
+	if o >= 5 {
+		dstWidth, dstHeight = height, width
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := range dstHeight {
+		for x := range dstWidth {
+			sx, sy := x, y
+			switch o {
+			case 2: // mirrored horizontal
+				sx = width - 1 - x
+			case 3: // rotated 180
+				sx, sy = width-1-x, height-1-y
+			case 4: // mirrored vertical
+				sy = height - 1 - y
+			case 5: // mirrored horizontal and rotated 270 clockwise
+				sx, sy = y, x
+			case 6: // rotated 90 clockwise
+				sx, sy = y, height-1-x
+			case 7: // mirrored horizontal and rotated 90 clockwise
+				sx, sy = width-1-y, height-1-x
+			case 8: // rotated 270 clockwise
+				sx, sy = width-1-y, x
+			}
+			dst.Set(x, y, img.At(b.Min.X+sx, b.Min.Y+sy))
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,216 @@
+package imaging_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/imaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecode(t *testing.T) {
+	src := testImage(4, 2)
+	webpImg, err := os.ReadFile(filepath.Join("testdata", "pixel.webp"))
+	require.NoError(t, err)
+	cases := []struct {
+		name     string
+		data     []byte
+		expected image.Point
+	}{
+		{
+			name:     "jpeg",
+			data:     encode(t, src, "jpeg"),
+			expected: image.Pt(4, 2),
+		},
+		{
+			name:     "png",
+			data:     encode(t, src, "png"),
+			expected: image.Pt(4, 2),
+		},
+		{
+			name:     "gif",
+			data:     encode(t, src, "gif"),
+			expected: image.Pt(4, 2),
+		},
+		{
+			name:     "webp",
+			data:     webpImg,
+			expected: image.Pt(1, 1),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			img, err := imaging.Decode(bytes.NewReader(tc.data))
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, img.Bounds().Size())
+		})
+	}
+}
+
+func TestDecodeConfig(t *testing.T) {
+	data := encode(t, testImage(40, 20), "png")
+
+	cfg, err := imaging.DecodeConfig(bytes.NewReader(data))
+
+	require.NoError(t, err)
+	assert.Equal(t, 40, cfg.Width)
+	assert.Equal(t, 20, cfg.Height)
+}
+
+func TestDecodeUnsupported(t *testing.T) {
+	cases := []struct {
+		name string
+		data []byte
+	}{
+		{
+			name: "heic",
+			data: append([]byte{0, 0, 0, 24}, []byte("ftypheic\x00\x00\x00\x00mif1heic")...),
+		},
+		{
+			name: "text",
+			data: []byte("not an image"),
+		},
+		{
+			name: "empty",
+			data: []byte{},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := imaging.Decode(bytes.NewReader(tc.data))
+
+			require.ErrorIs(t, err, imaging.ErrUnsupportedImage)
+			require.ErrorIs(t, err, cards.ErrInvalidImage)
+		})
+	}
+}
+
+func TestDecodeBrokenImage(t *testing.T) {
+	data := encode(t, testImage(4, 2), "png")
+
+	_, err := imaging.Decode(bytes.NewReader(data[:len(data)/2]))
+
+	require.ErrorIs(t, err, cards.ErrInvalidImage)
+	require.NotErrorIs(t, err, imaging.ErrUnsupportedImage)
+}
+
+func TestDecodeExifOrientation(t *testing.T) {
+	// the top left pixel is red, after the transformation it must be in the given corner
+	cases := []struct {
+		name        string
+		orientation uint16
+		size        image.Point
+		red         image.Point
+	}{
+		{name: "upright", orientation: 1, size: image.Pt(40, 20), red: image.Pt(0, 0)},
+		{name: "mirrored", orientation: 2, size: image.Pt(40, 20), red: image.Pt(39, 0)},
+		{name: "rotated 180", orientation: 3, size: image.Pt(40, 20), red: image.Pt(39, 19)},
+		{name: "rotated 90 clockwise", orientation: 6, size: image.Pt(20, 40), red: image.Pt(19, 0)},
+		{name: "rotated 270 clockwise", orientation: 8, size: image.Pt(20, 40), red: image.Pt(0, 39)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			data := withOrientation(encode(t, testImage(40, 20), "jpeg"), tc.orientation)
+
+			img, err := imaging.Decode(bytes.NewReader(data))
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.size, img.Bounds().Size())
+			r, g, b, _ := img.At(tc.red.X, tc.red.Y).RGBA()
+			assert.Greater(t, r>>8, uint32(200))
+			assert.Less(t, g>>8, uint32(80))
+			assert.Less(t, b>>8, uint32(80))
+		})
+	}
+}
+
+func TestDecodeMalformedSegment(t *testing.T) {
+	cases := []struct {
+		name   string
+		length byte
+	}{
+		{name: "length 0", length: 0},
+		{name: "length 1", length: 1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// the jpeg decoder skips the bytes of the segment without a valid length
+			valid := encode(t, testImage(40, 20), "jpeg")
+			data := append([]byte{0xFF, 0xD8, 0xFF, 0x00, 0x00, tc.length}, valid[2:]...)
+
+			var img image.Image
+			var err error
+			require.NotPanics(t, func() {
+				img, err = imaging.Decode(bytes.NewReader(data))
+			})
+			require.NoError(t, err)
+			assert.Equal(t, image.Pt(40, 20), img.Bounds().Size())
+		})
+	}
+}
+
+// testImage a white image with a red square in the top left corner.
+func testImage(width, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			img.Set(x, y, color.White)
+			if x < width/4 && y < height/4 || x == 0 && y == 0 {
+				img.Set(x, y, color.RGBA{R: 255, A: 255})
+			}
+		}
+	}
+
+	return img
+}
+
+func encode(t *testing.T, img image.Image, format string) []byte {
+	t.Helper()
+
+	buf := new(bytes.Buffer)
+	var err error
+	switch format {
+	case "jpeg":
+		err = jpeg.Encode(buf, img, &jpeg.Options{Quality: 100})
+	case "png":
+		err = png.Encode(buf, img)
+	case "gif":
+		err = gif.Encode(buf, img, nil)
+	}
+	require.NoError(t, err)
+
+	return buf.Bytes()
+}
+
+// withOrientation adds an EXIF segment with the orientation directly after the start of image marker.
+func withOrientation(data []byte, orientation uint16) []byte {
+	tiff := new(bytes.Buffer)
+	tiff.WriteString("MM")
+	_ = binary.Write(tiff, binary.BigEndian, uint16(42))
+	_ = binary.Write(tiff, binary.BigEndian, uint32(8))
+	_ = binary.Write(tiff, binary.BigEndian, uint16(1))
+	// tag, type short, count, value padded to 4 bytes
+	_ = binary.Write(tiff, binary.BigEndian, []uint16{0x0112, 3, 0, 1, orientation, 0})
+	_ = binary.Write(tiff, binary.BigEndian, uint32(0))
+
+	segment := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+	app1 := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(app1[2:], uint16(len(segment)+2))
+
+	result := append([]byte{}, data[:2]...)
+	result = append(result, app1...)
+	result = append(result, segment...)
+
+	return append(result, data[2:]...)
+}
@@ -0,0 +1,8 @@
+//go:build !opencv
+
+package imaging
+
+// NewDetector without OpenCV the pure Go detector is used.
+func NewDetector() *EdgeDetector {
+	return NewEdgeDetector()
+}
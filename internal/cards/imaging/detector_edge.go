@@ -0,0 +1,351 @@
+package imaging
+
+import (
+	"cmp"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"log/slog"
+	"math"
+	"slices"
+
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+	"golang.org/x/image/draw"
+)
+
+const (
+	// detectHeight bigger images are scaled down to this height before the detection.
+	detectHeight = 1024
+	// cardWidth and cardHeight the size of a detected card after the perspective warp.
+	cardWidth  = 200
+	cardHeight = 300
+	// minCardArea the smallest area in pixel of the scaled image that can be a card.
+	minCardArea = 3000
+	// edgeThreshold the minimal gradient magnitude of an edge pixel.
+	edgeThreshold = 100
+	// edgeRadius how many pixels edges are grown to close gaps, blur and gradient add one more pixel.
+	edgeRadius = 2
+	// minFill how much of the rectangle around a contour must be covered by its convex hull.
+	minFill = 0.85
+	// minAspect and maxAspect the allowed ratio between the short and the long side of a card.
+	minAspect = 0.55
+	maxAspect = 0.9
+)
+
+// EdgeDetector finds cards with an edge detection written in pure Go, it works without cgo.
+// Edges are grouped into contours, every contour with the shape of a card becomes a candidate.
+type EdgeDetector struct {
+	log *slog.Logger
+}
+
+func NewEdgeDetector() *EdgeDetector {
+	return &EdgeDetector{
+		log: slog.Default(),
+	}
+}
+
+func (d *EdgeDetector) Detect(in io.Reader) ([]cards.Detectable, error) {
+	return d.detect(in, nil)
+}
+
+// DetectDebug detects the cards like Detect and returns the scaled image, the edges, the found contours and
+// every warped candidate.
+func (d *EdgeDetector) DetectDebug(in io.Reader) ([]cards.Detectable, []cards.DebugImage, error) {
+	stages := &debugStages{}
+	candidates, err := d.detect(in, stages)
+	if err != nil {
+		return nil, nil, err
+	}
+	if stages.err != nil {
+		return nil, nil, stages.err
+	}
+
+	return candidates, stages.images, nil
+}
+
+func (d *EdgeDetector) detect(in io.Reader, stages *debugStages) ([]cards.Detectable, error) {
+	src, err := Decode(in)
+	if err != nil {
+		return nil, err
+	}
+
+	img := scaleToHeight(src, detectHeight)
+	stages.add("scaled", img)
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	edges := dilate(edgeMask(img), width, height, edgeRadius)
+	stages.add("edges", maskImage(edges, width, height))
+
+	found := findCards(edges, width, height)
+	stages.add("contours", outline(img, found))
+
+	candidates := make([]cards.Detectable, 0)
+	for _, q := range found {
+		card, ok := warp(img, q.portrait(), cardWidth, cardHeight)
+		if !ok {
+			continue
+		}
+		stages.add(fmt.Sprintf("candidate-%d", len(candidates)), card)
+
+		candidates = append(candidates, NewRegion(card, boxOf(q, width, height)))
+	}
+
+	d.log.Debug("found candidates", slog.Int("value", len(candidates)))
+
+	return candidates, nil
+}
+
+// scaleToHeight returns a RGBA copy of the image that is not higher than the given height.
+func scaleToHeight(src image.Image, height int) *image.RGBA {
+	bounds := src.Bounds()
+	if bounds.Dy() <= height {
+		dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+		draw.Draw(dst, dst.Rect, src, bounds.Min, draw.Src)
+
+		return dst
+	}
+
+	ratio := float64(bounds.Dx()) / float64(bounds.Dy())
+	width := int(float64(height) * ratio)
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Rect, src, bounds, draw.Src, nil)
+
+	return dst
+}
+
+// edgeMask marks all pixels of the blurred grayscale image with a strong sobel gradient.
+func edgeMask(img *image.RGBA) []bool {
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+	gray := make([]float64, width*height)
+	for y := range height {
+		for x := range width {
+			c := img.RGBAAt(x, y)
+			gray[y*width+x] = 0.299*float64(c.R) + 0.587*float64(c.G) + 0.114*float64(c.B)
+		}
+	}
+	gray = blur(gray, width, height)
+
+	at := func(x, y int) float64 {
+		x = min(max(x, 0), width-1)
+		y = min(max(y, 0), height-1)
+
+		return gray[y*width+x]
+	}
+	mask := make([]bool, width*height)
+	for y := range height {
+		for x := range width {
+			gx := at(x+1, y-1) + 2*at(x+1, y) + at(x+1, y+1) - at(x-1, y-1) - 2*at(x-1, y) - at(x-1, y+1)
+			gy := at(x-1, y+1) + 2*at(x, y+1) + at(x+1, y+1) - at(x-1, y-1) - 2*at(x, y-1) - at(x+1, y-1)
+			mask[y*width+x] = math.Abs(gx)+math.Abs(gy) >= edgeThreshold
+		}
+	}
+
+	return mask
+}
+
+// blur applies a 5x5 gaussian blur, separated into a horizontal and a vertical pass.
+func blur(values []float64, width, height int) []float64 {
+	kernel := [5]float64{1.0 / 16, 4.0 / 16, 6.0 / 16, 4.0 / 16, 1.0 / 16}
+	pass := func(src []float64, dx, dy int) []float64 {
+		dst := make([]float64, len(src))
+		for y := range height {
+			for x := range width {
+				sum := 0.0
+				for k, f := range kernel {
+					sx := min(max(x+(k-2)*dx, 0), width-1)
+					sy := min(max(y+(k-2)*dy, 0), height-1)
+					sum += f * src[sy*width+sx]
+				}
+				dst[y*width+x] = sum
+			}
+		}
+
+		return dst
+	}
+
+	return pass(pass(values, 1, 0), 0, 1)
+}
+
+// dilate grows the marked pixels by the radius to close small gaps in the edges.
+func dilate(mask []bool, width, height, radius int) []bool {
+	pass := func(src []bool, dx, dy int) []bool {
+		dst := make([]bool, len(src))
+		for y := range height {
+			for x := range width {
+				for r := -radius; r <= radius; r++ {
+					sx, sy := x+r*dx, y+r*dy
+					if sx >= 0 && sx < width && sy >= 0 && sy < height && src[sy*width+sx] {
+						dst[y*width+x] = true
+
+						break
+					}
+				}
+			}
+		}
+
+		return dst
+	}
+
+	return pass(pass(mask, 1, 0), 0, 1)
+}
+
+// findCards groups connected edge pixels into contours and returns the rectangle of every contour that
+// looks like a card, biggest first. Rectangles inside an already found card, like the art of the card, are skipped.
+func findCards(edges []bool, width, height int) []quad {
+	visited := make([]bool, len(edges))
+	stack := make([]int, 0)
+	pixels := make([]int, 0)
+	found := make([]quad, 0)
+	for start := range edges {
+		if !edges[start] || visited[start] {
+			continue
+		}
+
+		// flood fill with 8 neighbors
+		pixels = pixels[:0]
+		stack = append(stack[:0], start)
+		visited[start] = true
+		for len(stack) > 0 {
+			idx := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			pixels = append(pixels, idx)
+			x, y := idx%width, idx/width
+			for ny := max(y-1, 0); ny <= min(y+1, height-1); ny++ {
+				for nx := max(x-1, 0); nx <= min(x+1, width-1); nx++ {
+					n := ny*width + nx
+					if edges[n] && !visited[n] {
+						visited[n] = true
+						stack = append(stack, n)
+					}
+				}
+			}
+		}
+
+		if q, ok := cardShape(pixels, width); ok {
+			found = append(found, q)
+		}
+	}
+
+	slices.SortFunc(found, func(a, b quad) int {
+		return cmp.Compare(b.area(), a.area())
+	})
+
+	result := make([]quad, 0, len(found))
+	for _, q := range found {
+		nested := slices.ContainsFunc(result, func(o quad) bool {
+			return o.contains(q.center())
+		})
+		if !nested {
+			result = append(result, q)
+		}
+	}
+
+	return result
+}
+
+// cardShape returns the rectangle around the contour if it has the size and shape of a card.
+func cardShape(pixels []int, width int) (quad, bool) {
+	if len(pixels) < 4 {
+		return quad{}, false
+	}
+
+	// only the outermost pixels of each row can be part of the hull
+	rows := make(map[int][2]int)
+	for _, idx := range pixels {
+		x, y := idx%width, idx/width
+		r, ok := rows[y]
+		if !ok {
+			rows[y] = [2]int{x, x}
+
+			continue
+		}
+		rows[y] = [2]int{min(r[0], x), max(r[1], x)}
+	}
+	points := make([]point, 0, 2*len(rows))
+	for y, r := range rows {
+		points = append(points, point{X: float64(r[0]), Y: float64(y)}, point{X: float64(r[1]), Y: float64(y)})
+	}
+
+	hull := convexHull(points)
+	if len(hull) < 3 {
+		return quad{}, false
+	}
+
+	rect := minAreaRect(hull)
+	area := rect.area()
+	if area < minCardArea || polygonArea(hull)/area < minFill {
+		return quad{}, false
+	}
+
+	a, b := rect[0].dist(rect[1]), rect[1].dist(rect[2])
+	aspect := math.Min(a, b) / math.Max(a, b)
+	if aspect < minAspect || aspect > maxAspect {
+		return quad{}, false
+	}
+
+	// the edges extend beyond the real border of the card
+	return rect.inset(edgeRadius + 1), true
+}
+
+// warp maps the quad to an upright image with the given size.
+func warp(src *image.RGBA, q quad, width, height int) (*image.RGBA, bool) {
+	w, h := float64(width), float64(height)
+	m, ok := homography(quad{{X: 0, Y: 0}, {X: w, Y: 0}, {X: w, Y: h}, {X: 0, Y: h}}, q)
+	if !ok {
+		return nil, false
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			p := project(m, point{X: float64(x) + 0.5, Y: float64(y) + 0.5})
+			dst.SetRGBA(x, y, bilinear(src, p.X-0.5, p.Y-0.5))
+		}
+	}
+
+	return dst, true
+}
+
+func bilinear(img *image.RGBA, x, y float64) color.RGBA {
+	maxX, maxY := img.Rect.Dx()-1, img.Rect.Dy()-1
+	x0, y0 := int(math.Floor(x)), int(math.Floor(y))
+	fx, fy := x-float64(x0), y-float64(y0)
+	at := func(px, py int) color.RGBA {
+		return img.RGBAAt(min(max(px, 0), maxX), min(max(py, 0), maxY))
+	}
+
+	c00, c10, c01, c11 := at(x0, y0), at(x0+1, y0), at(x0, y0+1), at(x0+1, y0+1)
+	mix := func(v00, v10, v01, v11 uint8) uint8 {
+		top := float64(v00)*(1-fx) + float64(v10)*fx
+		bottom := float64(v01)*(1-fx) + float64(v11)*fx
+
+		return uint8(math.Round(top*(1-fy) + bottom*fy))
+	}
+
+	return color.RGBA{
+		R: mix(c00.R, c10.R, c01.R, c11.R),
+		G: mix(c00.G, c10.G, c01.G, c11.G),
+		B: mix(c00.B, c10.B, c01.B, c11.B),
+		A: mix(c00.A, c10.A, c01.A, c11.A),
+	}
+}
+
+// boxOf the upright bounding box of the quad relative to the image size.
+func boxOf(q quad, width, height int) cards.Box {
+	minX, minY := math.MaxFloat64, math.MaxFloat64
+	maxX, maxY := -math.MaxFloat64, -math.MaxFloat64
+	for _, p := range q {
+		minX, maxX = math.Min(minX, p.X), math.Max(maxX, p.X)
+		minY, maxY = math.Min(minY, p.Y), math.Max(maxY, p.Y)
+	}
+	w, h := float64(width), float64(height)
+	minX, maxX = math.Max(minX, 0), math.Min(maxX, w)
+	minY, maxY = math.Max(minY, 0), math.Min(maxY, h)
+
+	return cards.Box{
+		X:      minX / w,
+		Y:      minY / h,
+		Width:  (maxX - minX) / w,
+		Height: (maxY - minY) / h,
+	}
+}
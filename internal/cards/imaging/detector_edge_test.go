@@ -0,0 +1,132 @@
+package imaging_test
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/imaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:gochecknoglobals
+var update = flag.Bool("update", false, "update the golden images")
+
+func TestEdgeDetector(t *testing.T) {
+	cases := []struct {
+		name     string
+		img      string
+		expected []cards.Box
+	}{
+		{
+			name: "straight and rotated card",
+			img:  "cards.jpg",
+			expected: []cards.Box{
+				{X: 0.522, Y: 0.246, Width: 0.324, Height: 0.524},
+				{X: 0.122, Y: 0.267, Width: 0.244, Height: 0.456},
+			},
+		},
+		{
+			name:     "no card",
+			img:      "empty.jpg",
+			expected: []cards.Box{},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			in, err := os.Open(filepath.Join("testdata", tc.img))
+			require.NoError(t, err)
+			defer in.Close()
+
+			result, err := imaging.NewEdgeDetector().Detect(in)
+
+			require.NoError(t, err)
+			require.Len(t, result, len(tc.expected))
+			for i, r := range result {
+				box := r.Box()
+				assert.InDelta(t, tc.expected[i].X, box.X, 0.01)
+				assert.InDelta(t, tc.expected[i].Y, box.Y, 0.01)
+				assert.InDelta(t, tc.expected[i].Width, box.Width, 0.01)
+				assert.InDelta(t, tc.expected[i].Height, box.Height, 0.01)
+
+				golden := filepath.Join("testdata", "golden", fmt.Sprintf("%s-%d.png", tc.img, i))
+				assertGolden(t, golden, r.(imaging.Region).Image)
+			}
+		})
+	}
+}
+
+func TestEdgeDetectorDebug(t *testing.T) {
+	in, err := os.Open(filepath.Join("testdata", "cards.jpg"))
+	require.NoError(t, err)
+	defer in.Close()
+
+	result, stages, err := imaging.NewEdgeDetector().DetectDebug(in)
+
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	names := make([]string, 0, len(stages))
+	for _, s := range stages {
+		names = append(names, s.Name)
+		_, err := png.Decode(bytes.NewReader(s.PNG))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{"scaled", "edges", "contours", "candidate-0", "candidate-1"}, names)
+}
+
+func TestEdgeDetectorInvalidInput(t *testing.T) {
+	_, err := imaging.NewEdgeDetector().Detect(nil)
+
+	require.ErrorIs(t, err, imaging.ErrInvalidInput)
+}
+
+// assertGolden compares the image with the golden image, small differences are allowed because
+// of rounding. Run the tests with -update to write the current images as golden images.
+func assertGolden(t *testing.T, path string, actual image.Image) {
+	t.Helper()
+
+	if *update {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+		out, err := os.Create(path)
+		require.NoError(t, err)
+		defer out.Close()
+		require.NoError(t, png.Encode(out, actual))
+
+		return
+	}
+
+	in, err := os.Open(path)
+	require.NoError(t, err)
+	defer in.Close()
+	expected, err := png.Decode(in)
+	require.NoError(t, err)
+
+	require.Equal(t, expected.Bounds().Size(), actual.Bounds().Size())
+	var diff uint64
+	eb, ab := expected.Bounds(), actual.Bounds()
+	for y := range eb.Dy() {
+		for x := range eb.Dx() {
+			er, eg, eB, _ := expected.At(eb.Min.X+x, eb.Min.Y+y).RGBA()
+			ar, ag, aB, _ := actual.At(ab.Min.X+x, ab.Min.Y+y).RGBA()
+			diff += absDiff(er, ar) + absDiff(eg, ag) + absDiff(eB, aB)
+		}
+	}
+	// average difference per channel in the range 0 to 255
+	avg := float64(diff) / float64(eb.Dx()*eb.Dy()*3) / 257
+	assert.Lessf(t, avg, 4.0, "image differs from golden image %s", path)
+}
+
+func absDiff(a, b uint32) uint64 {
+	if a > b {
+		return uint64(a - b)
+	}
+
+	return uint64(b - a)
+}
@@ -1,7 +1,6 @@
 package imaging
 
 import (
-	"image"
 	"io"
 
 	"github.com/konstantinfoerster/card-service-go/internal/cards"
@@ -15,14 +14,26 @@ func NewFakeDetector() *FakeDetector {
 }
 
 func (d FakeDetector) Detect(in io.Reader) ([]cards.Detectable, error) {
-	if in == nil {
-		return nil, ErrInvalidInput
+	img, err := NewImage(in)
+	if err != nil {
+		return nil, err
 	}
 
-	dImg, _, err := image.Decode(in)
+	return []cards.Detectable{img}, nil
+}
+
+// DetectDebug returns the input image as the only card and as the only stage.
+func (d FakeDetector) DetectDebug(in io.Reader) ([]cards.Detectable, []cards.DebugImage, error) {
+	img, err := NewImage(in)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	stages := &debugStages{}
+	stages.add("input", img.Image)
+	if stages.err != nil {
+		return nil, nil, stages.err
 	}
 
-	return []cards.Detectable{Image{dImg}}, nil
+	return []cards.Detectable{img}, stages.images, nil
 }
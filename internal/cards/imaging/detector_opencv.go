@@ -7,8 +7,8 @@ import (
 	"errors"
 	"fmt"
 	"image"
+	"image/color"
 	"image/jpeg"
-	_ "image/jpeg"
 	"io"
 	"log/slog"
 	"math"
@@ -33,6 +33,25 @@ type BoxDetector struct {
 }
 
 func (d *BoxDetector) Detect(in io.Reader) ([]cards.Detectable, error) {
+	return d.detect(in, nil)
+}
+
+// DetectDebug detects the cards like Detect and returns the normalized image, the canny edges, the found
+// contours and every warped candidate.
+func (d *BoxDetector) DetectDebug(in io.Reader) ([]cards.Detectable, []cards.DebugImage, error) {
+	stages := &debugStages{}
+	candidates, err := d.detect(in, stages)
+	if err != nil {
+		return nil, nil, err
+	}
+	if stages.err != nil {
+		return nil, nil, stages.err
+	}
+
+	return candidates, stages.images, nil
+}
+
+func (d *BoxDetector) detect(in io.Reader, stages *debugStages) ([]cards.Detectable, error) {
 	if in == nil {
 		return nil, ErrInvalidInput
 	}
@@ -43,7 +62,9 @@ func (d *BoxDetector) Detect(in io.Reader) ([]cards.Detectable, error) {
 	}
 
 	resized := new(bytes.Buffer)
-	d.resize(buf, resized, 1024)
+	if err := d.resize(buf, resized, 1024); err != nil {
+		return nil, err
+	}
 	buf = nil
 
 	orig, err := gocv.IMDecode(resized.Bytes(), gocv.IMReadColor)
@@ -54,8 +75,9 @@ func (d *BoxDetector) Detect(in io.Reader) ([]cards.Detectable, error) {
 
 	normalized := d.normalizeColors(orig)
 	defer normalized.Close()
+	stages.addMat("normalized", normalized)
 
-	candidates, err := d.findCandidates(orig, normalized)
+	candidates, err := d.findCandidates(orig, stages)
 	if err != nil {
 		if errors.Is(err, ErrNoContours) {
 			return make([]cards.Detectable, 0), nil
@@ -70,7 +92,7 @@ func (d *BoxDetector) Detect(in io.Reader) ([]cards.Detectable, error) {
 }
 
 func (d *BoxDetector) resize(in io.Reader, out io.Writer, height int) error {
-	srcImg, err := jpeg.Decode(in)
+	srcImg, err := Decode(in)
 	if err != nil {
 		return err
 	}
@@ -124,8 +146,8 @@ func (d *BoxDetector) normalizeColors(orig gocv.Mat) gocv.Mat {
 	return normalized
 }
 
-func (d *BoxDetector) findCandidates(orig gocv.Mat, normalized gocv.Mat) ([]cards.Detectable, error) {
-	contours, err := d.findContours(orig)
+func (d *BoxDetector) findCandidates(orig gocv.Mat, stages *debugStages) ([]cards.Detectable, error) {
+	contours, err := d.findContours(orig, stages)
 	if err != nil {
 		return nil, err
 	}
@@ -135,18 +157,33 @@ func (d *BoxDetector) findCandidates(orig gocv.Mat, normalized gocv.Mat) ([]card
 	for i := 0; i < contours.Size(); i++ {
 		pv := contours.At(i)
 
-		img, err := singleCandidate(orig, pv, i)
+		img, err := singleCandidate(orig, pv)
 		if err != nil {
 			return nil, err
 		}
+		stages.add(fmt.Sprintf("candidate-%d", i), img)
 
-		images = append(images, Image{img})
+		images = append(images, NewRegion(img, boundingBox(orig, pv)))
 	}
 
 	return images, nil
 }
 
-func singleCandidate(orig gocv.Mat, pv gocv.PointVector, i int) (image.Image, error) {
+// boundingBox the upright bounding box of the contour relative to the image size.
+func boundingBox(orig gocv.Mat, pv gocv.PointVector) cards.Box {
+	r := gocv.BoundingRect(pv).Intersect(image.Rect(0, 0, orig.Cols(), orig.Rows()))
+	width := float64(orig.Cols())
+	height := float64(orig.Rows())
+
+	return cards.Box{
+		X:      float64(r.Min.X) / width,
+		Y:      float64(r.Min.Y) / height,
+		Width:  float64(r.Dx()) / width,
+		Height: float64(r.Dy()) / height,
+	}
+}
+
+func singleCandidate(orig gocv.Mat, pv gocv.PointVector) (image.Image, error) {
 	origImg := gocv.NewPointVector()
 	defer origImg.Close()
 	minR := gocv.MinAreaRect(pv)
@@ -179,9 +216,6 @@ func singleCandidate(orig gocv.Mat, pv gocv.PointVector, i int) (image.Image, er
 	defer perspective.Close()
 	gocv.WarpPerspective(orig, &perspective, transform, image.Point{X: maxWidth, Y: maxHeight})
 
-	// gocv.IMWrite(fmt.Sprintf("/tmp/match-%v.jpg", i), perspective)
-	// log.Debug().Msgf("wrote matched img to %s", fmt.Sprintf("/tmp/match-%v.jpg", i))
-
 	pImg, err := perspective.ToImage()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create image from perspective %w", err)
@@ -190,7 +224,7 @@ func singleCandidate(orig gocv.Mat, pv gocv.PointVector, i int) (image.Image, er
 	return pImg, nil
 }
 
-func (d *BoxDetector) findContours(orig gocv.Mat) (gocv.PointsVector, error) {
+func (d *BoxDetector) findContours(orig gocv.Mat, stages *debugStages) (gocv.PointsVector, error) {
 	blur := gocv.NewMat()
 	defer blur.Close()
 	gocv.GaussianBlur(orig, &blur, image.Point{7, 7}, 0, 0, gocv.BorderDefault)
@@ -203,6 +237,7 @@ func (d *BoxDetector) findContours(orig gocv.Mat) (gocv.PointsVector, error) {
 	canny := gocv.NewMat()
 	defer canny.Close()
 	gocv.Canny(blur, &canny, float32(baseThreshold), float32(baseThreshold)*2)
+	stages.addMat("canny", canny)
 
 	p5 := image.Point{5, 5}
 	kernel := gocv.GetStructuringElement(gocv.MorphRect, p5)
@@ -274,9 +309,31 @@ func (d *BoxDetector) findContours(orig gocv.Mat) (gocv.PointsVector, error) {
 		cardContours.Append(p)
 	}
 
+	if stages != nil {
+		overlay := orig.Clone()
+		defer overlay.Close()
+		gocv.DrawContours(&overlay, cardContours, -1, color.RGBA{R: 255, A: 255}, 3)
+		stages.addMat("contours", overlay)
+	}
+
 	if cardContours.Size() == 0 {
 		return gocv.PointsVector{}, ErrNoCardContours
 	}
 
 	return cardContours, nil
 }
+
+// addMat adds the matrix as debug image, a failed conversion is kept as error of the recorder.
+func (s *debugStages) addMat(name string, mat gocv.Mat) {
+	if s == nil || s.err != nil {
+		return
+	}
+
+	img, err := mat.ToImage()
+	if err != nil {
+		s.err = fmt.Errorf("failed to convert debug image %s %w", name, err)
+
+		return
+	}
+	s.add(name, img)
+}
@@ -0,0 +1,31 @@
+package imaging
+
+import (
+	"fmt"
+	"io"
+	"math/bits"
+
+	"github.com/corona10/goimagehash"
+)
+
+// FrameHash a cheap 64 bit hash of a whole camera frame, used to skip frames that show the same scene.
+type FrameHash uint64
+
+func NewFrameHash(in io.Reader) (FrameHash, error) {
+	img, err := Decode(in)
+	if err != nil {
+		return 0, err
+	}
+
+	h, err := goimagehash.DifferenceHash(img)
+	if err != nil {
+		return 0, fmt.Errorf("failed to hash frame %w", err)
+	}
+
+	return FrameHash(h.GetHash()), nil
+}
+
+// Distance returns the number of different bits.
+func (h FrameHash) Distance(o FrameHash) int {
+	return bits.OnesCount64(uint64(h ^ o))
+}
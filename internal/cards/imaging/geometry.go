@@ -0,0 +1,229 @@
+package imaging
+
+import (
+	"math"
+	"slices"
+)
+
+type point struct {
+	X float64
+	Y float64
+}
+
+func (p point) sub(o point) point {
+	return point{X: p.X - o.X, Y: p.Y - o.Y}
+}
+
+func (p point) dist(o point) float64 {
+	return math.Hypot(p.X-o.X, p.Y-o.Y)
+}
+
+func cross(o, a, b point) float64 {
+	return (a.X-o.X)*(b.Y-o.Y) - (a.Y-o.Y)*(b.X-o.X)
+}
+
+// quad four corners in clockwise or counterclockwise order.
+type quad [4]point
+
+func (q quad) area() float64 {
+	return polygonArea(q[:])
+}
+
+func (q quad) center() point {
+	return point{
+		X: (q[0].X + q[1].X + q[2].X + q[3].X) / 4,
+		Y: (q[0].Y + q[1].Y + q[2].Y + q[3].Y) / 4,
+	}
+}
+
+// contains true if the point is inside the convex quad.
+func (q quad) contains(p point) bool {
+	sign := 0.0
+	for i := range q {
+		c := cross(q[i], q[(i+1)%len(q)], p)
+		if c == 0 {
+			continue
+		}
+		if sign == 0 {
+			sign = c
+		} else if (sign > 0) != (c > 0) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// inset moves all sides of the rectangle inwards by d.
+func (q quad) inset(d float64) quad {
+	var r quad
+	for i := range q {
+		next, prev := q[(i+1)%len(q)], q[(i+len(q)-1)%len(q)]
+		toNext, toPrev := next.sub(q[i]), prev.sub(q[i])
+		ln, lp := math.Hypot(toNext.X, toNext.Y), math.Hypot(toPrev.X, toPrev.Y)
+		r[i] = point{
+			X: q[i].X + d*toNext.X/ln + d*toPrev.X/lp,
+			Y: q[i].Y + d*toNext.Y/ln + d*toPrev.Y/lp,
+		}
+	}
+
+	return r
+}
+
+// portrait reorders the corners so that the first edge is a short edge and the first corner is the one
+// closest to the top left, that results in an upright image after the warp.
+func (q quad) portrait() quad {
+	if q[0].dist(q[1]) > q[1].dist(q[2]) {
+		q = quad{q[1], q[2], q[3], q[0]}
+	}
+	if q[2].X+q[2].Y < q[0].X+q[0].Y {
+		q = quad{q[2], q[3], q[0], q[1]}
+	}
+
+	return q
+}
+
+// convexHull returns the convex hull in counterclockwise order using the monotone chain algorithm.
+func convexHull(points []point) []point {
+	if len(points) < 3 {
+		return points
+	}
+
+	pts := slices.Clone(points)
+	slices.SortFunc(pts, func(a, b point) int {
+		if a.X != b.X {
+			if a.X < b.X {
+				return -1
+			}
+
+			return 1
+		}
+		if a.Y < b.Y {
+			return -1
+		}
+		if a.Y > b.Y {
+			return 1
+		}
+
+		return 0
+	})
+
+	hull := make([]point, 0, 2*len(pts))
+	for _, p := range pts {
+		for len(hull) >= 2 && cross(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+	lower := len(hull) + 1
+	for i := len(pts) - 2; i >= 0; i-- {
+		p := pts[i]
+		for len(hull) >= lower && cross(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+
+	return hull[:len(hull)-1]
+}
+
+func polygonArea(points []point) float64 {
+	area := 0.0
+	for i := range points {
+		j := (i + 1) % len(points)
+		area += points[i].X*points[j].Y - points[j].X*points[i].Y
+	}
+
+	return math.Abs(area) / 2
+}
+
+// minAreaRect the rotated rectangle with the smallest area that contains all points of the convex hull.
+// One side of that rectangle is always collinear with an edge of the hull.
+func minAreaRect(hull []point) quad {
+	best := math.MaxFloat64
+	var rect quad
+	for i := range hull {
+		edge := hull[(i+1)%len(hull)].sub(hull[i])
+		length := math.Hypot(edge.X, edge.Y)
+		if length == 0 {
+			continue
+		}
+		u := point{X: edge.X / length, Y: edge.Y / length}
+		v := point{X: -u.Y, Y: u.X}
+
+		minU, maxU := math.MaxFloat64, -math.MaxFloat64
+		minV, maxV := math.MaxFloat64, -math.MaxFloat64
+		for _, p := range hull {
+			pu := p.X*u.X + p.Y*u.Y
+			pv := p.X*v.X + p.Y*v.Y
+			minU, maxU = math.Min(minU, pu), math.Max(maxU, pu)
+			minV, maxV = math.Min(minV, pv), math.Max(maxV, pv)
+		}
+
+		area := (maxU - minU) * (maxV - minV)
+		if area >= best {
+			continue
+		}
+		best = area
+		corner := func(a, b float64) point {
+			return point{X: a*u.X + b*v.X, Y: a*u.Y + b*v.Y}
+		}
+		rect = quad{corner(minU, minV), corner(maxU, minV), corner(maxU, maxV), corner(minU, maxV)}
+	}
+
+	return rect
+}
+
+// homography the perspective transformation that maps the corners of from to the corners of to.
+func homography(from, to quad) ([9]float64, bool) {
+	// a*h = b with the 8 unknown values of the 3x3 matrix, the last value is fixed to 1
+	var a [8][9]float64
+	for i := range from {
+		x, y := from[i].X, from[i].Y
+		u, v := to[i].X, to[i].Y
+		a[2*i] = [9]float64{x, y, 1, 0, 0, 0, -u * x, -u * y, u}
+		a[2*i+1] = [9]float64{0, 0, 0, x, y, 1, -v * x, -v * y, v}
+	}
+
+	// gaussian elimination with partial pivoting
+	n := len(a)
+	for col := range n {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(a[row][col]) > math.Abs(a[pivot][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(a[pivot][col]) < 1e-12 {
+			return [9]float64{}, false
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+
+		for row := range n {
+			if row == col {
+				continue
+			}
+			f := a[row][col] / a[col][col]
+			for k := col; k <= n; k++ {
+				a[row][k] -= f * a[col][k]
+			}
+		}
+	}
+
+	var h [9]float64
+	for i := range n {
+		h[i] = a[i][n] / a[i][i]
+	}
+	h[8] = 1
+
+	return h, true
+}
+
+func project(h [9]float64, p point) point {
+	w := h[6]*p.X + h[7]*p.Y + h[8]
+
+	return point{
+		X: (h[0]*p.X + h[1]*p.Y + h[2]) / w,
+		Y: (h[3]*p.X + h[4]*p.Y + h[5]) / w,
+	}
+}
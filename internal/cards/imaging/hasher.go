@@ -0,0 +1,72 @@
+package imaging
+
+import (
+	"context"
+	"errors"
+
+	"github.com/konstantinfoerster/card-service-go/internal/aio"
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+)
+
+var ErrLoadImage = errors.New("failed to load image")
+
+// Hasher loads card images from the image store and computes their hashes.
+type Hasher struct {
+	store cards.ImageStore
+}
+
+func NewHasher(store cards.ImageStore) *Hasher {
+	return &Hasher{
+		store: store,
+	}
+}
+
+// Hash loads the image once and computes one hash per algorithm.
+func (h *Hasher) Hash(ctx context.Context, path string, algs ...cards.HashAlgorithm) ([]cards.Hash, error) {
+	img, err := h.load(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]cards.Hash, 0, len(algs))
+	for _, alg := range algs {
+		hash, err := img.Hash(alg)
+		if err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, hash)
+	}
+
+	return hashes, nil
+}
+
+// HashRegions loads the image once and computes the hash of every card region with the given algorithm.
+func (h *Hasher) HashRegions(
+	ctx context.Context, path string, alg cards.HashAlgorithm, regions ...cards.CardRegion,
+) ([]cards.RegionHash, error) {
+	img, err := h.load(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]cards.RegionHash, 0, len(regions))
+	for _, r := range regions {
+		hash, err := img.crop(r.Box()).Hash(alg)
+		if err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, cards.RegionHash{Region: r, Hash: hash})
+	}
+
+	return hashes, nil
+}
+
+func (h *Hasher) load(ctx context.Context, path string) (Image, error) {
+	f, err := h.store.Open(ctx, path)
+	if err != nil {
+		return Image{}, errors.Join(err, ErrLoadImage)
+	}
+	defer aio.Close(f.Content)
+
+	return NewImage(f.Content)
+}
@@ -0,0 +1,89 @@
+package imaging_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/imaging"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHasher(t *testing.T) {
+	srv := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	defer srv.Close()
+	phash, err := hashFile(filepath.Join("testdata", "cards.jpg"), cards.PHash)
+	require.NoError(t, err)
+	dhash, err := hashFile(filepath.Join("testdata", "cards.jpg"), cards.DHash)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name  string
+		store cards.ImageStore
+	}{
+		{
+			name:  "local directory",
+			store: storage.NewLocalStore("testdata"),
+		},
+		{
+			name:  "http host",
+			store: storage.NewHTTPStore(srv.URL + "/"),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			h, err := imaging.NewHasher(tc.store).Hash(context.Background(), "cards.jpg", cards.PHash, cards.DHash)
+
+			require.NoError(t, err)
+			assert.Equal(t, []cards.Hash{phash, dhash}, h)
+		})
+	}
+}
+
+func TestHasherRegions(t *testing.T) {
+	img := loadImage(t, "cards.jpg")
+	art, err := img.Crop(cards.ArtRegion.Box()).Hash(cards.PHash)
+	require.NoError(t, err)
+	symbol, err := img.Crop(cards.SymbolRegion.Box()).Hash(cards.PHash)
+	require.NoError(t, err)
+
+	h, err := imaging.NewHasher(storage.NewLocalStore("testdata")).
+		HashRegions(context.Background(), "cards.jpg", cards.PHash, cards.ArtRegion, cards.SymbolRegion)
+
+	require.NoError(t, err)
+	assert.Equal(t, []cards.RegionHash{{Region: cards.ArtRegion, Hash: art}, {Region: cards.SymbolRegion, Hash: symbol}}, h)
+	assert.NotEqual(t, art.Value, symbol.Value)
+}
+
+func TestHasherImageNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	defer srv.Close()
+
+	for _, store := range []cards.ImageStore{storage.NewLocalStore("testdata"), storage.NewHTTPStore(srv.URL)} {
+		_, err := imaging.NewHasher(store).Hash(context.Background(), "unknown.jpg", cards.PHash)
+
+		require.ErrorIs(t, err, imaging.ErrLoadImage)
+		require.ErrorIs(t, err, cards.ErrImageNotFound)
+	}
+}
+
+func hashFile(path string, alg cards.HashAlgorithm) (cards.Hash, error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return cards.Hash{}, err
+	}
+	defer in.Close()
+
+	img, err := imaging.NewImage(in)
+	if err != nil {
+		return cards.Hash{}, err
+	}
+
+	return img.Hash(alg)
+}
@@ -4,10 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"image"
+	"image/draw"
 	"io"
 
-	_ "image/jpeg"
-
 	"github.com/anthonynsimon/bild/transform"
 	"github.com/corona10/goimagehash"
 	"github.com/konstantinfoerster/card-service-go/internal/cards"
@@ -20,11 +19,7 @@ type Image struct {
 }
 
 func NewImage(in io.Reader) (Image, error) {
-	if in == nil {
-		return Image{}, ErrInvalidInput
-	}
-
-	dImg, _, err := image.Decode(in)
+	dImg, err := Decode(in)
 	if err != nil {
 		return Image{}, err
 	}
@@ -33,29 +28,134 @@ func NewImage(in io.Reader) (Image, error) {
 }
 
 func (img Image) Rotate(angle cards.Degree) cards.Detectable {
-	if angle == cards.None {
+	return img.rotate(angle)
+}
+
+// rotate turns the image clockwise, multiples of 90 degree are rotated without interpolation.
+func (img Image) rotate(angle cards.Degree) Image {
+	switch angle {
+	case cards.None:
 		return img
+	case cards.Degree90, cards.Degree180, cards.Degree270:
+		return Image{rotateQuarter(img, int(angle)/90)}
+	default:
+		return Image{transform.Rotate(img, float64(angle), &transform.RotationOptions{ResizeBounds: true})}
+	}
+}
+
+// rotateQuarter turns the image clockwise by the given number of quarter turns.
+func rotateQuarter(src image.Image, quarters int) *image.RGBA {
+	b := src.Bounds()
+	width, height := b.Dx(), b.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	if quarters%2 == 1 {
+		dst = image.NewRGBA(image.Rect(0, 0, height, width))
 	}
 
-	rImg := transform.Rotate(img, float64(angle), nil)
+	for y := range height {
+		for x := range width {
+			c := src.At(b.Min.X+x, b.Min.Y+y)
+			switch quarters {
+			case 1:
+				dst.Set(height-1-y, x, c)
+			case 2:
+				dst.Set(width-1-x, height-1-y, c)
+			default:
+				dst.Set(y, width-1-x, c)
+			}
+		}
+	}
+
+	return dst
+}
+
+func (img Image) Crop(box cards.Box) cards.Detectable {
+	return img.crop(box)
+}
+
+func (img Image) crop(box cards.Box) Image {
+	b := img.Bounds()
+	width, height := float64(b.Dx()), float64(b.Dy())
+	rect := image.Rect(
+		b.Min.X+int(box.X*width),
+		b.Min.Y+int(box.Y*height),
+		b.Min.X+int((box.X+box.Width)*width),
+		b.Min.Y+int((box.Y+box.Height)*height),
+	).Intersect(b)
+
+	dst := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	draw.Draw(dst, dst.Rect, img, rect.Min, draw.Src)
+
+	return Image{dst}
+}
 
-	return Image{rImg}
+// Box an image always covers the whole analyzed image.
+func (img Image) Box() cards.Box {
+	return cards.FullBox()
 }
 
-func (img Image) Hash() (cards.Hash, error) {
-	width := 16
-	height := 16
-	ph, err := goimagehash.ExtPerceptionHash(img, width, height)
+// Hash computes a 256 bit hash of the image with the given algorithm.
+func (img Image) Hash(alg cards.HashAlgorithm) (cards.Hash, error) {
+	size := 16
+
+	var h *goimagehash.ExtImageHash
+	var err error
+	switch alg {
+	case cards.PHash:
+		h, err = goimagehash.ExtPerceptionHash(img, size, size)
+	case cards.DHash:
+		h, err = goimagehash.ExtDifferenceHash(img, size, size)
+	case cards.AHash:
+		h, err = goimagehash.ExtAverageHash(img, size, size)
+	case cards.WHash:
+		return waveletHash(img, size), nil
+	default:
+		return cards.Hash{}, fmt.Errorf("%w %q", cards.ErrUnknownHashAlgorithm, alg)
+	}
 	if err != nil {
-		return cards.Hash{}, fmt.Errorf("failed create phash %w", err)
+		return cards.Hash{}, fmt.Errorf("failed create %s %w", alg, err)
 	}
 
 	return cards.Hash{
-		Value: ph.GetHash(),
-		Bits:  ph.Bits(),
+		Algorithm: alg,
+		Value:     h.GetHash(),
+		Bits:      h.Bits(),
 	}, nil
 }
 
+// Region a part of a bigger image, e.g. one card of a photo that shows multiple cards.
+type Region struct {
+	Image
+	box cards.Box
+}
+
+func NewRegion(img image.Image, box cards.Box) Region {
+	return Region{
+		Image: Image{img},
+		box:   box,
+	}
+}
+
+func (r Region) Rotate(angle cards.Degree) cards.Detectable {
+	return Region{
+		Image: r.Image.rotate(angle),
+		box:   r.box,
+	}
+}
+
+// Crop returns the part of the region, the position inside the analyzed image stays the same.
+func (r Region) Crop(box cards.Box) cards.Detectable {
+	return Region{
+		Image: r.Image.crop(box),
+		box:   r.box,
+	}
+}
+
+// Box the position of the region inside the analyzed image.
+func (r Region) Box() cards.Box {
+	return r.box
+}
+
 func Distance(h1 cards.Hash, h2 cards.Hash) (int, error) {
 	ph1 := goimagehash.NewExtImageHash(h1.Value, goimagehash.PHash, h1.Bits)
 	ph2 := goimagehash.NewExtImageHash(h2.Value, goimagehash.PHash, h2.Bits)
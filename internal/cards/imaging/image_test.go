@@ -0,0 +1,89 @@
+package imaging_test
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/anthonynsimon/bild/transform"
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/imaging"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/index"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashAlgorithms(t *testing.T) {
+	img := loadImage(t, "cards.jpg")
+	b := img.Bounds()
+	// same image with a lower resolution
+	scaled := imaging.Image{Image: transform.Resize(img, b.Dx()/3, b.Dy()/3, transform.Linear)}
+	other := loadImage(t, "empty.jpg")
+
+	for _, alg := range cards.HashAlgorithms() {
+		t.Run(string(alg), func(t *testing.T) {
+			h, err := img.Hash(alg)
+			require.NoError(t, err)
+			hScaled, err := scaled.Hash(alg)
+			require.NoError(t, err)
+			hOther, err := other.Hash(alg)
+			require.NoError(t, err)
+
+			assert.Equal(t, alg, h.Algorithm)
+			assert.Equal(t, 256, h.Bits)
+			assert.Len(t, h.Value, 4)
+			assert.Less(t, index.Distance(h, hScaled), 32)
+			assert.Greater(t, index.Distance(h, hOther), index.Distance(h, hScaled))
+		})
+	}
+}
+
+func TestHashUnknownAlgorithm(t *testing.T) {
+	_, err := loadImage(t, "cards.jpg").Hash("unknown")
+
+	require.ErrorIs(t, err, cards.ErrUnknownHashAlgorithm)
+}
+
+func loadImage(t *testing.T, name string) imaging.Image {
+	t.Helper()
+
+	in, err := os.Open(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	defer in.Close()
+	img, err := imaging.NewImage(in)
+	require.NoError(t, err)
+
+	return img
+}
+
+func TestRotate(t *testing.T) {
+	// 2x1 image with a red left and a blue right pixel
+	src := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	red := color.RGBA{R: 255, A: 255}
+	blue := color.RGBA{B: 255, A: 255}
+	src.SetRGBA(0, 0, red)
+	src.SetRGBA(1, 0, blue)
+
+	cases := []struct {
+		angle cards.Degree
+		size  image.Point
+		redAt image.Point
+	}{
+		{angle: cards.None, size: image.Pt(2, 1), redAt: image.Pt(0, 0)},
+		{angle: cards.Degree90, size: image.Pt(1, 2), redAt: image.Pt(0, 0)},
+		{angle: cards.Degree180, size: image.Pt(2, 1), redAt: image.Pt(1, 0)},
+		{angle: cards.Degree270, size: image.Pt(1, 2), redAt: image.Pt(0, 1)},
+	}
+	for _, tc := range cases {
+		t.Run(fmt.Sprintf("%d", tc.angle), func(t *testing.T) {
+			rotated := imaging.Image{Image: src}.Rotate(tc.angle).(imaging.Image)
+
+			assert.Equal(t, tc.size, rotated.Bounds().Size())
+			r, g, b, a := rotated.At(tc.redAt.X, tc.redAt.Y).RGBA()
+			assert.Equal(t, []uint32{0xffff, 0, 0, 0xffff}, []uint32{r, g, b, a})
+		})
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !tesseract
+
+package imaging
+
+import "github.com/konstantinfoerster/card-service-go/internal/cards"
+
+// NewOCR without tesseract no OCR is available, the matches are only found by their hashes.
+func NewOCR() cards.OCR {
+	return nil
+}
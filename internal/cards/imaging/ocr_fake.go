@@ -0,0 +1,21 @@
+package imaging
+
+import (
+	"context"
+
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+)
+
+// FakeOCR returns the same text per region for every card.
+type FakeOCR map[cards.CardRegion]string
+
+func NewFakeOCR(name string, collectorLine string) FakeOCR {
+	return FakeOCR{
+		cards.NameRegion:      name,
+		cards.CollectorRegion: collectorLine,
+	}
+}
+
+func (o FakeOCR) Text(_ context.Context, _ cards.Detectable, region cards.CardRegion) (string, error) {
+	return o[region], nil
+}
@@ -0,0 +1,64 @@
+//go:build tesseract
+
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"os/exec"
+	"strings"
+
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+	"golang.org/x/image/draw"
+)
+
+var ErrUnsupportedDetectable = errors.New("detectable is not an image")
+
+// NewOCR with tesseract the text is read by the tesseract command line tool, it must be on the PATH.
+func NewOCR() cards.OCR {
+	return NewTesseractOCR("tesseract")
+}
+
+type TesseractOCR struct {
+	bin string
+}
+
+func NewTesseractOCR(bin string) *TesseractOCR {
+	return &TesseractOCR{
+		bin: bin,
+	}
+}
+
+// Text crops the region and reads it as a single line of text.
+func (o *TesseractOCR) Text(ctx context.Context, d cards.Detectable, region cards.CardRegion) (string, error) {
+	img, ok := d.Crop(region.Box()).(image.Image)
+	if !ok {
+		return "", ErrUnsupportedDetectable
+	}
+
+	// the regions of a warped candidate are only a few pixels high, tesseract needs bigger letters
+	scale := 4
+	b := img.Bounds()
+	scaled := image.NewRGBA(image.Rect(0, 0, b.Dx()*scale, b.Dy()*scale))
+	draw.CatmullRom.Scale(scaled, scaled.Rect, img, b, draw.Src, nil)
+
+	var in bytes.Buffer
+	if err := png.Encode(&in, scaled); err != nil {
+		return "", fmt.Errorf("failed to encode region %w", err)
+	}
+
+	// page segmentation mode 7, the image is a single text line
+	//nolint:gosec // the binary is not user input
+	cmd := exec.CommandContext(ctx, o.bin, "stdin", "stdout", "--psm", "7")
+	cmd.Stdin = &in
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to run tesseract %w", err)
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
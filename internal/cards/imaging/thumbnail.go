@@ -0,0 +1,107 @@
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image/jpeg"
+	"path"
+	"strings"
+
+	"github.com/anthonynsimon/bild/transform"
+	"github.com/konstantinfoerster/card-service-go/internal/aio"
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+)
+
+const thumbnailQuality = 85
+
+// Thumbnails opens card images from the image store and keeps their thumbnails in a cache store.
+// A thumbnail is created on first access and recreated if the original image is newer.
+type Thumbnails struct {
+	images cards.ImageStore
+	cache  cards.ImageStore
+}
+
+func NewThumbnails(images cards.ImageStore, cache cards.ImageStore) *Thumbnails {
+	return &Thumbnails{
+		images: images,
+		cache:  cache,
+	}
+}
+
+// Open opens the image with the given path in the given size.
+// Returns cards.ErrImageNotFound if the original image does not exist.
+func (t *Thumbnails) Open(ctx context.Context, p string, size cards.ImageSize) (cards.ImageFile, error) {
+	if size.Width() == 0 {
+		return t.images.Open(ctx, p)
+	}
+
+	thumbnail, err := t.thumbnail(ctx, p, size)
+	if err != nil {
+		return cards.ImageFile{}, err
+	}
+
+	return t.cache.Open(ctx, thumbnail)
+}
+
+// Stat returns the image with the given path in the given size without its content.
+// Returns cards.ErrImageNotFound if the original image does not exist.
+func (t *Thumbnails) Stat(ctx context.Context, p string, size cards.ImageSize) (cards.ImageFile, error) {
+	if size.Width() == 0 {
+		return t.images.Stat(ctx, p)
+	}
+
+	thumbnail, err := t.thumbnail(ctx, p, size)
+	if err != nil {
+		return cards.ImageFile{}, err
+	}
+
+	return t.cache.Stat(ctx, thumbnail)
+}
+
+// thumbnail returns the path of the thumbnail in the cache, the thumbnail is created if it is missing or
+// older than the original image.
+func (t *Thumbnails) thumbnail(ctx context.Context, p string, size cards.ImageSize) (string, error) {
+	src, err := t.images.Stat(ctx, p)
+	if err != nil {
+		return "", err
+	}
+
+	thumbnail := path.Join(string(size), strings.TrimSuffix(p, path.Ext(p))+".jpg")
+	if cached, err := t.cache.Stat(ctx, thumbnail); err != nil || cached.ModTime.Before(src.ModTime) {
+		if err := t.create(ctx, p, thumbnail, size.Width()); err != nil {
+			return "", err
+		}
+	}
+
+	return thumbnail, nil
+}
+
+// create scales the original image down to the width and stores it as JPEG.
+func (t *Thumbnails) create(ctx context.Context, original string, thumbnail string, width int) error {
+	in, err := t.images.Open(ctx, original)
+	if err != nil {
+		return err
+	}
+	defer aio.Close(in.Content)
+
+	img, err := Decode(in.Content)
+	if err != nil {
+		return err
+	}
+
+	b := img.Bounds()
+	if b.Dx() > width {
+		img = transform.Resize(img, width, max(1, b.Dy()*width/b.Dx()), transform.CatmullRom)
+	}
+
+	var buf bytes.Buffer
+	if err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+		return fmt.Errorf("failed to encode thumbnail %w", err)
+	}
+	if err = t.cache.Put(ctx, thumbnail, buf.Bytes(), FormatJPEG); err != nil {
+		return fmt.Errorf("failed to store thumbnail %w", err)
+	}
+
+	return nil
+}
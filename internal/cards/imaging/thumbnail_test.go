@@ -0,0 +1,103 @@
+package imaging_test
+
+import (
+	"context"
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/imaging"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThumbnails(t *testing.T) {
+	cacheDir := t.TempDir()
+	thumbnails := imaging.NewThumbnails(storage.NewLocalStore("testdata"), storage.NewLocalStore(cacheDir))
+
+	f, err := thumbnails.Open(context.Background(), "cards.jpg", cards.ListSize)
+	require.NoError(t, err)
+	cfg, _, err := image.DecodeConfig(f.Content)
+	require.NoError(t, err)
+	require.NoError(t, f.Content.Close())
+
+	assert.Equal(t, cards.ListSize.Width(), cfg.Width)
+	assert.Equal(t, imaging.FormatJPEG, f.ContentType)
+	assert.FileExists(t, filepath.Join(cacheDir, "list", "cards.jpg"))
+}
+
+func TestThumbnailsAreCached(t *testing.T) {
+	dir := t.TempDir()
+	data, err := os.ReadFile(filepath.Join("testdata", "cards.jpg"))
+	require.NoError(t, err)
+	original := filepath.Join(dir, "card.jpg")
+	require.NoError(t, os.WriteFile(original, data, 0o600))
+	thumbnails := imaging.NewThumbnails(storage.NewLocalStore(dir), storage.NewLocalStore(t.TempDir()))
+	ctx := context.Background()
+
+	first, err := thumbnails.Open(ctx, "card.jpg", cards.DetailSize)
+	require.NoError(t, err)
+	require.NoError(t, first.Content.Close())
+	cached, err := thumbnails.Open(ctx, "card.jpg", cards.DetailSize)
+	require.NoError(t, err)
+	require.NoError(t, cached.Content.Close())
+	later := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(original, later, later))
+	recreated, err := thumbnails.Open(ctx, "card.jpg", cards.DetailSize)
+	require.NoError(t, err)
+	require.NoError(t, recreated.Content.Close())
+
+	assert.Equal(t, first.ETag(), cached.ETag())
+	assert.NotEqual(t, first.ETag(), recreated.ETag())
+}
+
+func TestThumbnailsStat(t *testing.T) {
+	thumbnails := imaging.NewThumbnails(storage.NewLocalStore("testdata"), storage.NewLocalStore(t.TempDir()))
+	ctx := context.Background()
+
+	info, err := thumbnails.Stat(ctx, "cards.jpg", cards.ListSize)
+	require.NoError(t, err)
+	f, err := thumbnails.Open(ctx, "cards.jpg", cards.ListSize)
+	require.NoError(t, err)
+	require.NoError(t, f.Content.Close())
+
+	assert.Nil(t, info.Content)
+	assert.NotEmpty(t, info.ETag())
+	assert.Equal(t, f.ETag(), info.ETag())
+}
+
+func TestThumbnailsNotFound(t *testing.T) {
+	cases := []struct {
+		name string
+		path string
+		size cards.ImageSize
+	}{
+		{
+			name: "missing original",
+			path: "missing.jpg",
+			size: cards.OriginalSize,
+		},
+		{
+			name: "missing thumbnail source",
+			path: "missing.jpg",
+			size: cards.ListSize,
+		},
+		{
+			name: "outside of the image directory",
+			path: "../thumbnail.go",
+			size: cards.OriginalSize,
+		},
+	}
+	thumbnails := imaging.NewThumbnails(storage.NewLocalStore("testdata"), storage.NewLocalStore(t.TempDir()))
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := thumbnails.Open(context.Background(), tc.path, tc.size)
+
+			require.ErrorIs(t, err, cards.ErrImageNotFound)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package imaging
+
+import (
+	"image"
+
+	"github.com/anthonynsimon/bild/transform"
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+)
+
+// waveletLevels the number of haar transformations, each level halves the size of the low frequency band.
+const waveletLevels = 2
+
+// waveletHash computes a hash of the low frequency band of the haar wavelet transform, every bit is set
+// if the coefficient is above the mean. The mean is used instead of the median, because images with
+// large uniform areas have many coefficients close to the median, small changes would flip their bits.
+// The hash has size*size bits.
+func waveletHash(img image.Image, size int) cards.Hash {
+	n := size << waveletLevels
+	resized := transform.Resize(img, n, n, transform.Linear)
+
+	px := make([]float64, n*n)
+	for y := range n {
+		for x := range n {
+			c := resized.RGBAAt(x, y)
+			px[y*n+x] = (0.299*float64(c.R) + 0.587*float64(c.G) + 0.114*float64(c.B)) / 255
+		}
+	}
+
+	for level := range waveletLevels {
+		haar(px, n, n>>level)
+	}
+
+	band := make([]float64, 0, size*size)
+	for y := range size {
+		band = append(band, px[y*n:y*n+size]...)
+	}
+	mean := 0.0
+	for _, c := range band {
+		mean += c
+	}
+	mean /= float64(len(band))
+
+	value := make([]uint64, (len(band)+63)/64)
+	for i, c := range band {
+		if c > mean {
+			value[i/64] |= 1 << (63 - i%64)
+		}
+	}
+
+	return cards.Hash{
+		Algorithm: cards.WHash,
+		Value:     value,
+		Bits:      len(band),
+	}
+}
+
+// haar applies one level of the haar transform to the top left w*w pixels of the n*n image.
+// The averages are stored in the top left quarter, the differences in the other quarters.
+func haar(px []float64, n, w int) {
+	half := w / 2
+	tmp := make([]float64, w)
+	for y := range w {
+		row := px[y*n : y*n+w]
+		for x := range half {
+			a, b := row[2*x], row[2*x+1]
+			tmp[x], tmp[half+x] = (a+b)/2, (a-b)/2
+		}
+		copy(row, tmp)
+	}
+	for x := range w {
+		for y := range half {
+			a, b := px[2*y*n+x], px[(2*y+1)*n+x]
+			tmp[y], tmp[half+y] = (a+b)/2, (a-b)/2
+		}
+		for y := range w {
+			px[y*n+x] = tmp[y]
+		}
+	}
+}
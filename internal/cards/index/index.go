@@ -0,0 +1,291 @@
+package index
+
+import (
+	"cmp"
+	"math/bits"
+	"slices"
+	"sync"
+
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+)
+
+const (
+	// chunkBits the size of a substring, every 64 bit word of a hash is split into multiple substrings.
+	chunkBits     = 16
+	chunksPerWord = 64 / chunkBits
+	buckets       = 1 << chunkBits
+	// maxChunkDistance larger chunk distances need too many bucket lookups, a linear scan is faster.
+	maxChunkDistance = 3
+)
+
+// Entry the hash of a single card image, the algorithm is part of the hash.
+type Entry struct {
+	ID   cards.ID
+	Hash cards.Hash
+}
+
+// Distance the hamming distance between two hashes. Hashes with a different length have the maximal distance.
+func Distance(a, b cards.Hash) int {
+	if len(a.Value) != len(b.Value) {
+		return max(len(a.Value), len(b.Value)) * 64
+	}
+
+	d := 0
+	for i, v := range a.Value {
+		d += bits.OnesCount64(v ^ b.Value[i])
+	}
+
+	return d
+}
+
+// Index an in-memory multi-index hashing structure of all card image hashes.
+// Every hash is split into substrings of 16 bits and every substring position has its own bucket table.
+// If two hashes have a distance of d, at least one of their m substrings has a distance <= d/m,
+// so only the buckets within that distance of the query substrings have to be checked.
+// Load replaces the whole index, searches running at the same time still use the previous table.
+type Index struct {
+	t  *table
+	mu sync.RWMutex
+}
+
+func New() *Index {
+	return &Index{
+		t: newTable(nil),
+	}
+}
+
+// Load replaces the content of the index with the entries. All hashes must have the same algorithm and length,
+// entries with a different length than the first entry are ignored.
+func (i *Index) Load(entries []Entry) {
+	t := newTable(entries)
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.t = t
+}
+
+func (i *Index) Len() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return len(i.t.ids)
+}
+
+// Top returns up to limit cards with a distance up to maxDistance to any of the hashes, best match first.
+// A card with multiple images is only returned once with its best score.
+func (i *Index) Top(limit int, maxDistance int, hashes ...cards.Hash) cards.Scores {
+	if limit < 1 {
+		return cards.Scores{}
+	}
+
+	t := i.table()
+	top := make(cards.Scores, 0, limit+1)
+	for _, h := range hashes {
+		radius := maxDistance
+		if len(top) == limit {
+			radius = top[len(top)-1].Score
+		}
+
+		t.search(h.Value, radius, func(id cards.ID, distance int) int {
+			top = insert(top, cards.Score{ID: id, Score: distance}, limit)
+			if len(top) < limit {
+				return maxDistance
+			}
+
+			return top[len(top)-1].Score
+		})
+	}
+
+	return top
+}
+
+// Within returns the best distance of all cards with a distance up to maxDistance to any of the hashes.
+func (i *Index) Within(maxDistance int, hashes ...cards.Hash) map[cards.ID]int {
+	t := i.table()
+	result := make(map[cards.ID]int)
+	for _, h := range hashes {
+		t.search(h.Value, maxDistance, func(id cards.ID, distance int) int {
+			if d, ok := result[id]; !ok || distance < d {
+				result[id] = distance
+			}
+
+			return maxDistance
+		})
+	}
+
+	return result
+}
+
+func (i *Index) table() *table {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return i.t
+}
+
+type table struct {
+	ids []cards.ID
+	// hashes all hash values, words values per entry
+	hashes []uint64
+	words  int
+	chunks int
+	// positions[c][offsets[c][v]:offsets[c][v+1]] are the entries with the value v in chunk c
+	offsets   [][]int32
+	positions [][]int32
+}
+
+func newTable(entries []Entry) *table {
+	t := &table{}
+	if len(entries) == 0 {
+		return t
+	}
+
+	t.words = len(entries[0].Hash.Value)
+	t.chunks = t.words * chunksPerWord
+	for _, e := range entries {
+		if len(e.Hash.Value) != t.words {
+			continue
+		}
+		t.ids = append(t.ids, e.ID)
+		t.hashes = append(t.hashes, e.Hash.Value...)
+	}
+
+	if t.chunks == 0 {
+		return t
+	}
+
+	t.offsets = make([][]int32, t.chunks)
+	t.positions = make([][]int32, t.chunks)
+	for c := range t.chunks {
+		offsets := make([]int32, buckets+1)
+		for p := range t.ids {
+			offsets[int(t.chunk(p, c))+1]++
+		}
+		for v := range buckets {
+			offsets[v+1] += offsets[v]
+		}
+
+		positions := make([]int32, len(t.ids))
+		next := slices.Clone(offsets[:buckets])
+		for p := range t.ids {
+			v := t.chunk(p, c)
+			positions[next[v]] = int32(p) //nolint:gosec // the index is limited by the number of entries
+			next[v]++
+		}
+		t.offsets[c] = offsets
+		t.positions[c] = positions
+	}
+
+	return t
+}
+
+// masks all chunk variations up to maxChunkDistance, ordered by their bit count.
+//
+//nolint:gochecknoglobals
+var masks = func() []uint16 {
+	result := make([]uint16, 0)
+	for m := range buckets {
+		if bits.OnesCount16(uint16(m)) <= maxChunkDistance {
+			result = append(result, uint16(m))
+		}
+	}
+	slices.SortStableFunc(result, func(a, b uint16) int {
+		return cmp.Compare(bits.OnesCount16(a), bits.OnesCount16(b))
+	})
+
+	return result
+}()
+
+// search calls fn for every entry within the radius of the hash. The returned value of fn
+// becomes the new radius, that allows to narrow the search when enough good matches are found.
+func (t *table) search(hash []uint64, radius int, fn func(id cards.ID, distance int) int) {
+	if len(hash) != t.words || len(t.ids) == 0 {
+		return
+	}
+
+	if t.offsets == nil || radius/t.chunks > maxChunkDistance {
+		for p := range t.ids {
+			if d := t.distance(p, hash, radius); d <= radius {
+				radius = fn(t.ids[p], d)
+			}
+		}
+
+		return
+	}
+
+	seen := make([]uint64, (len(t.ids)+63)/64)
+	for c := range t.chunks {
+		v := uint16(hash[c/chunksPerWord] >> (c % chunksPerWord * chunkBits))
+		for _, m := range masks {
+			if bits.OnesCount16(m) > radius/t.chunks {
+				break
+			}
+
+			b := v ^ m
+			for _, p := range t.positions[c][t.offsets[c][b]:t.offsets[c][int(b)+1]] {
+				if seen[p/64]&(1<<(p%64)) != 0 {
+					continue
+				}
+				seen[p/64] |= 1 << (p % 64)
+
+				if d := t.distance(int(p), hash, radius); d <= radius {
+					radius = fn(t.ids[p], d)
+				}
+			}
+		}
+	}
+}
+
+// chunk returns the value of chunk c of the entry at position p.
+func (t *table) chunk(p, c int) uint16 {
+	return uint16(t.hashes[p*t.words+c/chunksPerWord] >> (c % chunksPerWord * chunkBits))
+}
+
+// distance returns the distance between the entry at position p and the hash,
+// stops counting as soon as the distance is higher than the limit.
+func (t *table) distance(p int, hash []uint64, limit int) int {
+	d := 0
+	for i, v := range t.hashes[p*t.words : (p+1)*t.words] {
+		d += bits.OnesCount64(v ^ hash[i])
+		if d > limit {
+			return d
+		}
+	}
+
+	return d
+}
+
+// insert adds the score to the sorted scores and keeps only the best limit scores.
+func insert(top cards.Scores, s cards.Score, limit int) cards.Scores {
+	for i, existing := range top {
+		if !existing.ID.Eq(s.ID) {
+			continue
+		}
+		if existing.Score <= s.Score {
+			return top
+		}
+		top = slices.Delete(top, i, i+1)
+
+		break
+	}
+
+	pos, _ := slices.BinarySearchFunc(top, s, compare)
+	top = slices.Insert(top, pos, s)
+	if len(top) > limit {
+		top = top[:limit]
+	}
+
+	return top
+}
+
+// compare orders by score and uses the id for a stable order of equal scores.
+func compare(a, b cards.Score) int {
+	if c := cmp.Compare(a.Score, b.Score); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.ID.CardID, b.ID.CardID); c != 0 {
+		return c
+	}
+
+	return cmp.Compare(a.ID.FaceID, b.ID.FaceID)
+}
@@ -0,0 +1,224 @@
+package index_test
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/index"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDistance(t *testing.T) {
+	cases := []struct {
+		name     string
+		a        cards.Hash
+		b        cards.Hash
+		expected int
+	}{
+		{
+			name:     "equal",
+			a:        cards.Hash{Value: []uint64{1, 2}},
+			b:        cards.Hash{Value: []uint64{1, 2}},
+			expected: 0,
+		},
+		{
+			name:     "different bits",
+			a:        cards.Hash{Value: []uint64{0b1011, 0}},
+			b:        cards.Hash{Value: []uint64{0b0001, 1}},
+			expected: 3,
+		},
+		{
+			name:     "different length",
+			a:        cards.Hash{Value: []uint64{1}},
+			b:        cards.Hash{Value: []uint64{1, 2}},
+			expected: 128,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, index.Distance(tc.a, tc.b))
+		})
+	}
+}
+
+func TestTop(t *testing.T) {
+	base := cards.Hash{Value: []uint64{0, 0, 0, 0}}
+	idx := index.New()
+	idx.Load([]index.Entry{
+		{ID: cards.NewID(1), Hash: withBits(base, 10)},
+		{ID: cards.NewID(2), Hash: withBits(base, 3)},
+		// second image of the same card
+		{ID: cards.NewID(2), Hash: withBits(base, 1)},
+		{ID: cards.NewID(3), Hash: withBits(base, 59)},
+		{ID: cards.NewID(4), Hash: withBits(base, 60)},
+		{ID: cards.NewID(5), Hash: withBits(base, 20)},
+		{ID: cards.NewID(6), Hash: withBits(base, 30)},
+	})
+
+	result := idx.Top(5, cards.DefaultMaxDistance, base)
+
+	assert.Equal(t, cards.Scores{
+		{ID: cards.NewID(2), Score: 1},
+		{ID: cards.NewID(1), Score: 10},
+		{ID: cards.NewID(5), Score: 20},
+		{ID: cards.NewID(6), Score: 30},
+		{ID: cards.NewID(3), Score: 59},
+	}, result)
+	assert.Equal(t, 7, idx.Len())
+}
+
+func TestTopUsesBestHash(t *testing.T) {
+	base := cards.Hash{Value: []uint64{0, 0, 0, 0}}
+	rotated := cards.Hash{Value: []uint64{^uint64(0), ^uint64(0), ^uint64(0), ^uint64(0)}}
+	idx := index.New()
+	idx.Load([]index.Entry{
+		{ID: cards.NewID(1), Hash: withBits(rotated, 2)},
+		{ID: cards.NewID(2), Hash: withBits(base, 5)},
+	})
+
+	result := idx.Top(5, cards.DefaultMaxDistance, base, rotated)
+
+	assert.Equal(t, cards.Scores{
+		{ID: cards.NewID(1), Score: 2},
+		{ID: cards.NewID(2), Score: 5},
+	}, result)
+}
+
+func TestTopEmpty(t *testing.T) {
+	idx := index.New()
+
+	assert.Empty(t, idx.Top(5, cards.DefaultMaxDistance, cards.Hash{Value: []uint64{1}}))
+	assert.Empty(t, idx.Top(0, cards.DefaultMaxDistance, cards.Hash{Value: []uint64{1}}))
+}
+
+func TestTopMatchesLinearSearch(t *testing.T) {
+	cases := []struct {
+		name  string
+		words int
+	}{
+		{
+			name:  "multi index",
+			words: 4,
+		},
+		{
+			name:  "linear scan",
+			words: 1,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rnd := rand.New(rand.NewSource(1))
+			entries := clusteredEntries(rnd, 5000, tc.words)
+			idx := index.New()
+			idx.Load(entries)
+
+			for range 50 {
+				query := withRandomBits(rnd, entries[rnd.Intn(len(entries))].Hash, rnd.Intn(40))
+
+				require.Equal(t, linearTop(entries, 5, query), idx.Top(5, cards.DefaultMaxDistance, query))
+			}
+		})
+	}
+}
+
+func BenchmarkTop(b *testing.B) {
+	rnd := rand.New(rand.NewSource(1))
+	entries := clusteredEntries(rnd, 100_000, 4)
+	idx := index.New()
+	idx.Load(entries)
+	queries := make([]cards.Hash, 100)
+	for i := range queries {
+		queries[i] = withRandomBits(rnd, entries[rnd.Intn(len(entries))].Hash, 8)
+	}
+
+	b.ResetTimer()
+	for i := range b.N {
+		idx.Top(5, cards.DefaultMaxDistance, queries[i%len(queries)])
+	}
+}
+
+func BenchmarkLinearSearch(b *testing.B) {
+	rnd := rand.New(rand.NewSource(1))
+	entries := clusteredEntries(rnd, 100_000, 4)
+	queries := make([]cards.Hash, 100)
+	for i := range queries {
+		queries[i] = withRandomBits(rnd, entries[rnd.Intn(len(entries))].Hash, 8)
+	}
+
+	b.ResetTimer()
+	for i := range b.N {
+		linearTop(entries, 5, queries[i%len(queries)])
+	}
+}
+
+func BenchmarkLoad(b *testing.B) {
+	rnd := rand.New(rand.NewSource(1))
+	entries := clusteredEntries(rnd, 100_000, 4)
+	idx := index.New()
+
+	b.ResetTimer()
+	for range b.N {
+		idx.Load(entries)
+	}
+}
+
+// clusteredEntries creates random hashes where every tenth hash is a variation of the previous one,
+// similar to multiple prints of the same card.
+func clusteredEntries(rnd *rand.Rand, n int, words int) []index.Entry {
+	entries := make([]index.Entry, n)
+	for i := range entries {
+		v := make([]uint64, words)
+		for w := range v {
+			v[w] = rnd.Uint64()
+		}
+		h := cards.Hash{Value: v, Bits: words * 64}
+		if i%10 != 0 {
+			h = withRandomBits(rnd, entries[i-1].Hash, 12)
+		}
+		entries[i] = index.Entry{ID: cards.NewID(i + 1), Hash: h}
+	}
+
+	return entries
+}
+
+// withBits flips the first n bits of the hash.
+func withBits(h cards.Hash, n int) cards.Hash {
+	v := slices.Clone(h.Value)
+	for i := range n {
+		v[i/64] ^= 1 << (i % 64)
+	}
+
+	return cards.Hash{Algorithm: h.Algorithm, Value: v, Bits: h.Bits}
+}
+
+// withRandomBits flips up to n random bits of the hash.
+func withRandomBits(rnd *rand.Rand, h cards.Hash, n int) cards.Hash {
+	v := slices.Clone(h.Value)
+	for range n {
+		bit := rnd.Intn(len(v) * 64)
+		v[bit/64] ^= 1 << (bit % 64)
+	}
+
+	return cards.Hash{Algorithm: h.Algorithm, Value: v, Bits: h.Bits}
+}
+
+func linearTop(entries []index.Entry, limit int, query cards.Hash) cards.Scores {
+	result := make(cards.Scores, 0)
+	for _, e := range entries {
+		if d := index.Distance(e.Hash, query); d <= cards.DefaultMaxDistance {
+			result = append(result, cards.Score{ID: e.ID, Score: d})
+		}
+	}
+	slices.SortFunc(result, func(a, b cards.Score) int {
+		if a.Score != b.Score {
+			return a.Score - b.Score
+		}
+
+		return a.ID.CardID - b.ID.CardID
+	})
+
+	return result[:min(limit, len(result))]
+}
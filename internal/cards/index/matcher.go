@@ -0,0 +1,114 @@
+package index
+
+import (
+	"slices"
+
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+)
+
+// Matcher keeps one index per configured hash algorithm and combines their scores
+// as defined by the configured scoring.
+type Matcher struct {
+	hashes  []cards.HashConfig
+	scoring cards.Scoring
+	indexes map[cards.HashAlgorithm]*Index
+}
+
+func NewMatcher(cfg cards.DetectConfig) *Matcher {
+	indexes := make(map[cards.HashAlgorithm]*Index)
+	for _, h := range cfg.HashConfigs() {
+		indexes[h.Algorithm] = New()
+	}
+
+	return &Matcher{
+		hashes:  cfg.HashConfigs(),
+		scoring: cfg.Scoring,
+		indexes: indexes,
+	}
+}
+
+// Load replaces the content of all indexes, entries of algorithms that are not configured are ignored.
+func (m *Matcher) Load(entries []Entry) {
+	grouped := make(map[cards.HashAlgorithm][]Entry)
+	for _, e := range entries {
+		if _, ok := m.indexes[e.Hash.Algorithm]; ok {
+			grouped[e.Hash.Algorithm] = append(grouped[e.Hash.Algorithm], e)
+		}
+	}
+
+	for alg, idx := range m.indexes {
+		idx.Load(grouped[alg])
+	}
+}
+
+// Len returns the number of entries of all indexes.
+func (m *Matcher) Len() int {
+	size := 0
+	for _, idx := range m.indexes {
+		size += idx.Len()
+	}
+
+	return size
+}
+
+// Top returns up to limit cards that match the hashes, best match first. Every hash is only compared with
+// the hashes of the same algorithm.
+func (m *Matcher) Top(limit int, hashes ...cards.Hash) cards.Scores {
+	if limit < 1 {
+		return cards.Scores{}
+	}
+
+	grouped := make(map[cards.HashAlgorithm][]cards.Hash)
+	for _, h := range hashes {
+		grouped[h.Algorithm] = append(grouped[h.Algorithm], h)
+	}
+
+	if m.scoring == cards.ScoringCombined {
+		return m.combined(limit, grouped)
+	}
+
+	top := make(cards.Scores, 0, limit+1)
+	for _, h := range m.hashes {
+		if len(grouped[h.Algorithm]) == 0 {
+			continue
+		}
+
+		for _, s := range m.indexes[h.Algorithm].Top(limit, h.Threshold(), grouped[h.Algorithm]...) {
+			top = insert(top, s, limit)
+		}
+	}
+
+	return top
+}
+
+// combined returns the cards that match with every algorithm, the score is the sum of all distances.
+func (m *Matcher) combined(limit int, grouped map[cards.HashAlgorithm][]cards.Hash) cards.Scores {
+	var total map[cards.ID]int
+	for _, h := range m.hashes {
+		if len(grouped[h.Algorithm]) == 0 {
+			continue
+		}
+
+		within := m.indexes[h.Algorithm].Within(h.Threshold(), grouped[h.Algorithm]...)
+		if total == nil {
+			total = within
+
+			continue
+		}
+		for id, d := range total {
+			if wd, ok := within[id]; ok {
+				total[id] = d + wd
+			} else {
+				delete(total, id)
+			}
+		}
+	}
+
+	result := make(cards.Scores, 0, len(total))
+	for id, d := range total {
+		result = append(result, cards.Score{ID: id, Score: d})
+	}
+	slices.SortFunc(result, compare)
+
+	return result[:min(limit, len(result))]
+}
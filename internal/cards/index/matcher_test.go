@@ -0,0 +1,77 @@
+package index_test
+
+import (
+	"testing"
+
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/index"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatcher(t *testing.T) {
+	phash := cards.Hash{Algorithm: cards.PHash, Value: []uint64{0, 0, 0, 0}}
+	dhash := cards.Hash{Algorithm: cards.DHash, Value: []uint64{0, 0, 0, 0}}
+	entries := []index.Entry{
+		// matches with both algorithms
+		{ID: cards.NewID(1), Hash: withBits(phash, 8)},
+		{ID: cards.NewID(1), Hash: withBits(dhash, 6)},
+		// only matches with phash
+		{ID: cards.NewID(2), Hash: withBits(phash, 2)},
+		{ID: cards.NewID(2), Hash: withBits(dhash, 40)},
+		// only matches with dhash
+		{ID: cards.NewID(3), Hash: withBits(phash, 50)},
+		{ID: cards.NewID(3), Hash: withBits(dhash, 1)},
+		// algorithm is not configured
+		{ID: cards.NewID(4), Hash: cards.Hash{Algorithm: cards.AHash, Value: []uint64{0, 0, 0, 0}}},
+	}
+	hashes := []cards.HashConfig{
+		{Algorithm: cards.PHash, MaxDistance: 20},
+		{Algorithm: cards.DHash, MaxDistance: 10},
+	}
+	cases := []struct {
+		name     string
+		scoring  cards.Scoring
+		expected cards.Scores
+	}{
+		{
+			name:    "any",
+			scoring: cards.ScoringAny,
+			expected: cards.Scores{
+				{ID: cards.NewID(3), Score: 1},
+				{ID: cards.NewID(2), Score: 2},
+				{ID: cards.NewID(1), Score: 6},
+			},
+		},
+		{
+			name:    "combined",
+			scoring: cards.ScoringCombined,
+			expected: cards.Scores{
+				{ID: cards.NewID(1), Score: 14},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := index.NewMatcher(cards.DetectConfig{Hashes: hashes, Scoring: tc.scoring})
+			m.Load(entries)
+
+			result := m.Top(5, phash, dhash, cards.Hash{Algorithm: cards.AHash, Value: []uint64{0, 0, 0, 0}})
+
+			assert.Equal(t, tc.expected, result)
+			assert.Equal(t, 6, m.Len())
+		})
+	}
+}
+
+func TestMatcherDefaultsToPHash(t *testing.T) {
+	phash := cards.Hash{Algorithm: cards.PHash, Value: []uint64{0, 0, 0, 0}}
+	m := index.NewMatcher(cards.DetectConfig{})
+	m.Load([]index.Entry{
+		{ID: cards.NewID(1), Hash: withBits(phash, cards.DefaultMaxDistance)},
+		{ID: cards.NewID(2), Hash: withBits(phash, cards.DefaultMaxDistance+1)},
+	})
+
+	result := m.Top(5, phash)
+
+	assert.Equal(t, cards.Scores{{ID: cards.NewID(1), Score: cards.DefaultMaxDistance}}, result)
+}
@@ -0,0 +1,167 @@
+package cards
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/konstantinfoerster/card-service-go/internal/aerrors"
+	"github.com/konstantinfoerster/card-service-go/internal/aio"
+	"golang.org/x/sync/errgroup"
+)
+
+type IngestRepository interface {
+	// Images returns up to limit card images with an id greater than afterID, ordered by id.
+	Images(ctx context.Context, afterID int, limit int) ([]CardImage, error)
+}
+
+type IngestConfig struct {
+	// Workers the number of images that are downloaded at the same time.
+	Workers int
+	// BatchSize the number of images that are read from the repository together.
+	BatchSize int
+	// AfterID skips all images with a lower or equal id, used to continue an aborted run.
+	AfterID int
+	// Overwrite replaces images that already exist in the target store.
+	Overwrite bool
+}
+
+type IngestResult struct {
+	Stored int
+	// Skipped images that already exist in the target store.
+	Skipped int
+	Failed  int
+	// LastID the id of the last processed image, a new run can continue after it.
+	LastID int
+}
+
+// ingestState the outcome of copying a single image.
+type ingestState int
+
+const (
+	ingestFailed ingestState = iota
+	ingestSkipped
+	ingestStored
+)
+
+// IngestService copies the images of all cards from a source, e.g. the image host of the catalog, into the
+// configured image store.
+type IngestService struct {
+	repo   IngestRepository
+	source ImageStore
+	target ImageStore
+}
+
+func NewIngestService(repo IngestRepository, source ImageStore, target ImageStore) *IngestService {
+	return &IngestService{
+		repo:   repo,
+		source: source,
+		target: target,
+	}
+}
+
+// Run copies the images batch by batch. Images that already exist in the target are skipped unless overwrite
+// is set, images that cannot be copied are logged and skipped.
+func (s *IngestService) Run(ctx context.Context, cfg IngestConfig) (IngestResult, error) {
+	slog.Info("image ingest started", slog.Int("afterID", cfg.AfterID))
+
+	result := IngestResult{LastID: cfg.AfterID}
+	for {
+		images, err := s.repo.Images(ctx, result.LastID, max(cfg.BatchSize, 1))
+		if err != nil {
+			return result, aerrors.NewUnknownError(err, "ingest-read-failed")
+		}
+		if len(images) == 0 {
+			break
+		}
+
+		states, err := s.copyAll(ctx, images, cfg)
+		if err != nil {
+			return result, aerrors.NewUnknownError(err, "ingest-aborted")
+		}
+		for _, state := range states {
+			switch state {
+			case ingestStored:
+				result.Stored++
+			case ingestSkipped:
+				result.Skipped++
+			case ingestFailed:
+				result.Failed++
+			}
+		}
+
+		result.LastID = images[len(images)-1].ID
+		slog.Info("image ingest progress",
+			slog.Int("stored", result.Stored),
+			slog.Int("skipped", result.Skipped),
+			slog.Int("failed", result.Failed),
+			slog.Int("lastID", result.LastID),
+		)
+	}
+
+	slog.Info("image ingest finished",
+		slog.Int("stored", result.Stored), slog.Int("skipped", result.Skipped), slog.Int("failed", result.Failed))
+
+	return result, nil
+}
+
+// copyAll copies the images with the configured number of workers. Only returns an error if the context
+// is canceled.
+func (s *IngestService) copyAll(ctx context.Context, images []CardImage, cfg IngestConfig) ([]ingestState, error) {
+	states := make([]ingestState, len(images))
+
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(max(cfg.Workers, 1))
+	for i, img := range images {
+		g.Go(func() error {
+			if err := gCtx.Err(); err != nil {
+				return err
+			}
+
+			state, err := s.copy(gCtx, img, cfg.Overwrite)
+			if err != nil {
+				slog.Warn("failed to ingest image",
+					slog.Int("id", img.ID), slog.String("path", img.Path), slog.Any("error", err))
+			}
+			states[i] = state
+
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return states, nil
+}
+
+// copy stores the image of the source in the target, an existing image is only replaced if overwrite is set.
+func (s *IngestService) copy(ctx context.Context, img CardImage, overwrite bool) (ingestState, error) {
+	if !overwrite {
+		_, err := s.target.Stat(ctx, img.Path)
+		if err == nil {
+			return ingestSkipped, nil
+		}
+		if !errors.Is(err, ErrImageNotFound) {
+			return ingestFailed, err
+		}
+	}
+
+	f, err := s.source.Open(ctx, img.Path)
+	if err != nil {
+		return ingestFailed, err
+	}
+	defer aio.Close(f.Content)
+
+	data, err := io.ReadAll(f.Content)
+	if err != nil {
+		return ingestFailed, fmt.Errorf("failed to read image %w", err)
+	}
+	if err := s.target.Put(ctx, img.Path, data, f.ContentType); err != nil {
+		return ingestFailed, err
+	}
+
+	return ingestStored, nil
+}
@@ -0,0 +1,148 @@
+package cards_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/johannesboyne/gofakes3"
+	"github.com/johannesboyne/gofakes3/backend/s3mem"
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIngest(t *testing.T) {
+	cases := []struct {
+		name   string
+		target cards.ImageStore
+	}{
+		{
+			name:   "local directory",
+			target: storage.NewLocalStore(t.TempDir()),
+		},
+		{
+			name:   "s3 bucket",
+			target: s3Store(t),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := fakeIngestRepository{
+				{ID: 1, Path: "front/1.jpg"},
+				{ID: 2, Path: "front/missing.jpg"},
+				{ID: 3, Path: "front/3.jpg"},
+				{ID: 4, Path: "front/4.jpg"},
+			}
+			require.NoError(t, tc.target.Put(ctx, "front/4.jpg", []byte("existing"), "image/jpeg"))
+			svc := cards.NewIngestService(repo, imageHost(t), tc.target)
+
+			result, err := svc.Run(ctx, cards.IngestConfig{Workers: 2, BatchSize: 2})
+
+			require.NoError(t, err)
+			assert.Equal(t, cards.IngestResult{Stored: 2, Skipped: 1, Failed: 1, LastID: 4}, result)
+			assertImage(t, tc.target, "front/1.jpg", "image 1")
+			assertImage(t, tc.target, "front/3.jpg", "image 3")
+			assertImage(t, tc.target, "front/4.jpg", "existing")
+		})
+	}
+}
+
+func TestIngestOverwrite(t *testing.T) {
+	ctx := context.Background()
+	target := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, target.Put(ctx, "front/1.jpg", []byte("existing"), "image/jpeg"))
+	svc := cards.NewIngestService(fakeIngestRepository{{ID: 1, Path: "front/1.jpg"}}, imageHost(t), target)
+
+	result, err := svc.Run(ctx, cards.IngestConfig{Workers: 1, BatchSize: 10, Overwrite: true})
+
+	require.NoError(t, err)
+	assert.Equal(t, cards.IngestResult{Stored: 1, LastID: 1}, result)
+	assertImage(t, target, "front/1.jpg", "image 1")
+}
+
+func TestIngestContinuesAfterID(t *testing.T) {
+	target := storage.NewLocalStore(t.TempDir())
+	repo := fakeIngestRepository{{ID: 1, Path: "front/1.jpg"}, {ID: 3, Path: "front/3.jpg"}}
+	svc := cards.NewIngestService(repo, imageHost(t), target)
+
+	result, err := svc.Run(context.Background(), cards.IngestConfig{Workers: 1, BatchSize: 10, AfterID: 1})
+
+	require.NoError(t, err)
+	assert.Equal(t, cards.IngestResult{Stored: 1, LastID: 3}, result)
+	_, err = target.Stat(context.Background(), "front/1.jpg")
+	require.ErrorIs(t, err, cards.ErrImageNotFound)
+}
+
+func assertImage(t *testing.T, store cards.ImageStore, path string, want string) {
+	t.Helper()
+
+	f, err := store.Open(context.Background(), path)
+	require.NoError(t, err)
+	defer f.Content.Close()
+	content, err := io.ReadAll(f.Content)
+	require.NoError(t, err)
+	assert.Equal(t, want, string(content))
+}
+
+// imageHost returns the http store of a host that serves the images 1, 3 and 4 of the directory front.
+func imageHost(t *testing.T) cards.ImageStore {
+	t.Helper()
+
+	images := map[string]string{"/front/1.jpg": "image 1", "/front/3.jpg": "image 3", "/front/4.jpg": "image 4"}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, ok := images[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+
+			return
+		}
+		w.Header().Set("Content-Type", "image/jpeg")
+		_, _ = io.WriteString(w, content)
+	}))
+	t.Cleanup(srv.Close)
+
+	return storage.NewHTTPStore(srv.URL)
+}
+
+// s3Store returns a store of an in-memory S3 server.
+func s3Store(t *testing.T) cards.ImageStore {
+	t.Helper()
+
+	backend := s3mem.New()
+	require.NoError(t, backend.CreateBucket("cards"))
+	srv := httptest.NewServer(gofakes3.New(backend).Server())
+	t.Cleanup(srv.Close)
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	store, err := storage.NewS3Store(storage.S3Config{
+		Endpoint:  u.Host,
+		Bucket:    "cards",
+		Region:    "us-east-1",
+		AccessKey: "access",
+		SecretKey: "secret",
+		Insecure:  true,
+	})
+	require.NoError(t, err)
+
+	return store
+}
+
+type fakeIngestRepository []cards.CardImage
+
+func (r fakeIngestRepository) Images(_ context.Context, afterID int, limit int) ([]cards.CardImage, error) {
+	result := make([]cards.CardImage, 0, limit)
+	for _, img := range r {
+		if img.ID > afterID && len(result) < limit {
+			result = append(result, img)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/konstantinfoerster/card-service-go/internal/auth"
+)
+
+type InMemAccountRepository struct {
+	accounts map[string]auth.LocalAccount
+	mu       sync.RWMutex
+}
+
+func NewAccountRepository() *InMemAccountRepository {
+	return &InMemAccountRepository{
+		accounts: make(map[string]auth.LocalAccount),
+	}
+}
+
+func (r *InMemAccountRepository) Create(_ context.Context, account auth.LocalAccount) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, a := range r.accounts {
+		if a.Username == account.Username {
+			return auth.ErrAccountExists
+		}
+	}
+	r.accounts[account.ID] = account
+
+	return nil
+}
+
+func (r *InMemAccountRepository) FindByUsername(_ context.Context, username string) (auth.LocalAccount, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, a := range r.accounts {
+		if a.Username == username {
+			return a, nil
+		}
+	}
+
+	return auth.LocalAccount{}, auth.ErrAccountNotFound
+}
+
+func (r *InMemAccountRepository) FindByID(_ context.Context, id string) (auth.LocalAccount, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	a, ok := r.accounts[id]
+	if !ok {
+		return auth.LocalAccount{}, auth.ErrAccountNotFound
+	}
+
+	return a, nil
+}
+
+func (r *InMemAccountRepository) UpdatePassword(_ context.Context, id string, hash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a, ok := r.accounts[id]
+	if !ok {
+		return auth.ErrAccountNotFound
+	}
+	a.PasswordHash = hash
+	r.accounts[id] = a
+
+	return nil
+}
@@ -1,88 +1,75 @@
 package memory
 
 import (
-	"cmp"
 	"context"
-	"os"
-	"path"
-	"slices"
 
-	"github.com/konstantinfoerster/card-service-go/internal/aio"
 	"github.com/konstantinfoerster/card-service-go/internal/cards"
 	"github.com/konstantinfoerster/card-service-go/internal/cards/imaging"
-	"github.com/konstantinfoerster/card-service-go/internal/cards/postgres"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/index"
 )
 
 type InMemDetectRepository struct {
-	cards []cards.Card
-	cfg   postgres.Images
+	matcher *index.Matcher
+	regions map[cards.ID][]cards.RegionHash
 }
 
-func NewDetectRepository(data []cards.Card, cfg postgres.Images) (*InMemDetectRepository, error) {
-	return &InMemDetectRepository{
-		cards: data,
-		cfg:   cfg,
-	}, nil
-}
+// NewDetectRepository hashes the images of all cards once with every configured algorithm
+// and keeps the hashes in an index. The image URLs of the cards are used as paths in the image store.
+// The card regions are hashed too if the re-ranking is enabled.
+func NewDetectRepository(
+	data []cards.Card, store cards.ImageStore, dCfg cards.DetectConfig,
+) (*InMemDetectRepository, error) {
+	r := &InMemDetectRepository{
+		matcher: index.NewMatcher(dCfg),
+		regions: make(map[cards.ID][]cards.RegionHash),
+	}
 
-func (r *InMemDetectRepository) Top5MatchesByHash(ctx context.Context, hashes ...cards.Hash) (cards.Scores, error) {
-	var result cards.Scores
-	for _, card := range r.cards {
+	hasher := imaging.NewHasher(store)
+	entries := make([]index.Entry, 0, len(data))
+	for _, card := range data {
 		if card.Image.URL == "" {
 			continue
 		}
 
-		cHash, err := r.hash(card)
+		hashes, err := hasher.Hash(context.Background(), card.Image.URL, dCfg.Algorithms()...)
 		if err != nil {
 			return nil, err
 		}
-
-		lowest := 1000
 		for _, h := range hashes {
-			d, err := imaging.Distance(cHash, h)
+			entries = append(entries, index.Entry{ID: card.ID, Hash: h})
+		}
+
+		if dCfg.Rerank.Enabled() {
+			regions, err := hasher.HashRegions(
+				context.Background(), card.Image.URL, dCfg.Rerank.HashAlgorithm(), dCfg.Rerank.Regions...)
 			if err != nil {
 				return nil, err
 			}
-			if d < lowest {
-				lowest = d
-			}
-		}
-
-		// only scores < 60 are allowed
-		minConfidence := 60
-		if lowest >= minConfidence {
-			continue
+			r.regions[card.ID] = regions
 		}
-
-		result = append(result, cards.Score{
-			ID:    card.ID,
-			Score: lowest,
-		})
 	}
+	r.matcher.Load(entries)
 
-	slices.SortStableFunc(result, func(a cards.Score, b cards.Score) int {
-		return cmp.Compare(a.Score, b.Score)
-	})
+	return r, nil
+}
 
+func (r *InMemDetectRepository) Top5MatchesByHash(_ context.Context, hashes ...cards.Hash) (cards.Scores, error) {
 	limit := 5
-	if len(result) > limit {
-		return result[:limit], nil
-	}
 
-	return result, nil
+	return r.matcher.Top(limit, hashes...), nil
 }
 
-func (r *InMemDetectRepository) hash(c cards.Card) (cards.Hash, error) {
-	fImg, err := os.Open(path.Join(r.cfg.Host, c.Image.URL))
-	if err != nil {
-		return cards.Hash{}, err
-	}
-	defer aio.Close(fImg)
-
-	img, err := imaging.NewImage(fImg)
-	if err != nil {
-		return cards.Hash{}, err
+func (r *InMemDetectRepository) RegionHashes(
+	_ context.Context, alg cards.HashAlgorithm, ids ...cards.ID,
+) (map[cards.ID][]cards.RegionHash, error) {
+	result := make(map[cards.ID][]cards.RegionHash, len(ids))
+	for _, id := range ids {
+		for _, h := range r.regions[id] {
+			if h.Hash.Algorithm == alg {
+				result[id] = append(result[id], h)
+			}
+		}
 	}
 
-	return img.Hash()
+	return result, nil
 }
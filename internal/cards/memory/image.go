@@ -0,0 +1,32 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+)
+
+type InMemImageRepository struct {
+	images map[int]cards.CardImage
+}
+
+func NewImageRepository(images []cards.CardImage) *InMemImageRepository {
+	byID := make(map[int]cards.CardImage, len(images))
+	for _, img := range images {
+		byID[img.ID] = img
+	}
+
+	return &InMemImageRepository{
+		images: byID,
+	}
+}
+
+func (r *InMemImageRepository) Image(_ context.Context, id int) (cards.CardImage, error) {
+	img, ok := r.images[id]
+	if !ok {
+		return cards.CardImage{}, fmt.Errorf("%d, %w", id, cards.ErrImageNotFound)
+	}
+
+	return img, nil
+}
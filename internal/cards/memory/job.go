@@ -0,0 +1,103 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/konstantinfoerster/card-service-go/internal/jobs"
+)
+
+type InMemJobRepository struct {
+	jobs map[string]jobs.Job
+	mu   sync.RWMutex
+}
+
+func NewJobRepository() *InMemJobRepository {
+	return &InMemJobRepository{
+		jobs: make(map[string]jobs.Job),
+	}
+}
+
+func (r *InMemJobRepository) Save(_ context.Context, job jobs.Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job.Result = slices.Clone(job.Result)
+	r.jobs[job.ID] = job
+
+	return nil
+}
+
+func (r *InMemJobRepository) Find(_ context.Context, id string) (jobs.Job, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	job, ok := r.jobs[id]
+	if !ok {
+		return jobs.Job{}, jobs.ErrJobNotFound
+	}
+	job.Result = slices.Clone(job.Result)
+
+	return job, nil
+}
+
+func (r *InMemJobRepository) FailUnfinished(
+	_ context.Context, before time.Time, at time.Time, errorKey string,
+) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	failed := 0
+	for id, job := range r.jobs {
+		if job.Status.Finished() || !job.UpdatedAt.Before(before) {
+			continue
+		}
+		job.Status = jobs.StatusFailed
+		job.ErrorKey = errorKey
+		job.UpdatedAt = at
+		r.jobs[id] = job
+		failed++
+	}
+
+	return failed, nil
+}
+
+// DeleteOwner removes all jobs of the owner.
+func (r *InMemJobRepository) DeleteOwner(owner string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, job := range r.jobs {
+		if job.Owner == owner {
+			delete(r.jobs, id)
+		}
+	}
+}
+
+// ChangeOwner assigns all jobs of the owner from to the owner to.
+func (r *InMemJobRepository) ChangeOwner(from string, to string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, job := range r.jobs {
+		if job.Owner == from {
+			job.Owner = to
+			r.jobs[id] = job
+		}
+	}
+}
+
+func (r *InMemJobRepository) DeleteBefore(_ context.Context, before time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, job := range r.jobs {
+		if job.UpdatedAt.Before(before) {
+			delete(r.jobs, id)
+		}
+	}
+
+	return nil
+}
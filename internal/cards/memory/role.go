@@ -0,0 +1,49 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/konstantinfoerster/card-service-go/internal/auth"
+)
+
+type InMemRoleRepository struct {
+	roles map[string][]auth.Role
+	mu    sync.RWMutex
+}
+
+func NewRoleRepository() *InMemRoleRepository {
+	return &InMemRoleRepository{
+		roles: make(map[string][]auth.Role),
+	}
+}
+
+func (r *InMemRoleRepository) Roles(_ context.Context, userID string) ([]auth.Role, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return slices.Clone(r.roles[userID]), nil
+}
+
+func (r *InMemRoleRepository) Assign(_ context.Context, userID string, role auth.Role) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !slices.Contains(r.roles[userID], role) {
+		r.roles[userID] = append(r.roles[userID], role)
+	}
+
+	return nil
+}
+
+func (r *InMemRoleRepository) Revoke(_ context.Context, userID string, role auth.Role) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.roles[userID] = slices.DeleteFunc(r.roles[userID], func(v auth.Role) bool {
+		return v == role
+	})
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/konstantinfoerster/card-service-go/internal/auth"
+)
+
+type InMemTokenRepository struct {
+	tokens []auth.PersonalToken
+	mu     sync.RWMutex
+}
+
+func NewTokenRepository() *InMemTokenRepository {
+	return &InMemTokenRepository{
+		tokens: make([]auth.PersonalToken, 0),
+	}
+}
+
+func (r *InMemTokenRepository) Save(_ context.Context, token auth.PersonalToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, t := range r.tokens {
+		if t.UserID == token.UserID && t.Name == token.Name {
+			return auth.ErrTokenExists
+		}
+	}
+
+	r.tokens = append(r.tokens, token)
+
+	return nil
+}
+
+func (r *InMemTokenRepository) FindByHash(_ context.Context, hash string) (auth.PersonalToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, t := range r.tokens {
+		if t.Hash == hash {
+			return t, nil
+		}
+	}
+
+	return auth.PersonalToken{}, auth.ErrTokenNotFound
+}
+
+func (r *InMemTokenRepository) List(_ context.Context, userID string) ([]auth.PersonalToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]auth.PersonalToken, 0)
+	for _, t := range r.tokens {
+		if t.UserID == userID {
+			result = append(result, t)
+		}
+	}
+
+	return result, nil
+}
+
+func (r *InMemTokenRepository) Delete(_ context.Context, userID string, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	idx := slices.IndexFunc(r.tokens, func(t auth.PersonalToken) bool {
+		return t.UserID == userID && t.ID == id
+	})
+	if idx == -1 {
+		return auth.ErrTokenNotFound
+	}
+
+	r.tokens = slices.Delete(r.tokens, idx, idx+1)
+
+	return nil
+}
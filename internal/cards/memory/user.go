@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/konstantinfoerster/card-service-go/internal/auth"
+)
+
+type InMemUserRepository struct {
+	users      map[string]auth.User
+	identities map[auth.Identity]string
+	jobs       *InMemJobRepository
+	mu         sync.RWMutex
+}
+
+type UserOption func(*InMemUserRepository)
+
+// WithJobs moves or removes the jobs of the user together with the user.
+func WithJobs(jobs *InMemJobRepository) UserOption {
+	return func(r *InMemUserRepository) {
+		r.jobs = jobs
+	}
+}
+
+func NewUserRepository(opts ...UserOption) *InMemUserRepository {
+	r := &InMemUserRepository{
+		users:      make(map[string]auth.User),
+		identities: make(map[auth.Identity]string),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+func (r *InMemUserRepository) Create(_ context.Context, u auth.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[u.ID]; ok {
+		return nil
+	}
+
+	r.users[u.ID] = u
+	identity := auth.Identity{Provider: u.Provider, Subject: u.Subject}
+	if _, ok := r.identities[identity]; !ok {
+		r.identities[identity] = u.ID
+	}
+
+	return nil
+}
+
+func (r *InMemUserRepository) FindByID(_ context.Context, id string) (auth.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	u, ok := r.users[id]
+	if !ok {
+		return auth.User{}, auth.ErrUserNotFound
+	}
+
+	return u, nil
+}
+
+func (r *InMemUserRepository) FindByIdentity(_ context.Context, identity auth.Identity) (auth.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	u, ok := r.users[r.identities[identity]]
+	if !ok {
+		return auth.User{}, auth.ErrUserNotFound
+	}
+
+	return u, nil
+}
+
+func (r *InMemUserRepository) Update(_ context.Context, u auth.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[u.ID]; !ok {
+		return auth.ErrUserNotFound
+	}
+	r.users[u.ID] = u
+
+	return nil
+}
+
+// Link only moves the identities and the jobs of the user from, other in-memory repositories are not affected.
+func (r *InMemUserRepository) Link(_ context.Context, userID string, identity auth.Identity, from string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if from != "" {
+		for i, id := range r.identities {
+			if id == from {
+				r.identities[i] = userID
+			}
+		}
+		delete(r.users, from)
+		if r.jobs != nil {
+			r.jobs.ChangeOwner(from, userID)
+		}
+	}
+	r.identities[identity] = userID
+
+	return nil
+}
+
+// Delete only removes the user and the jobs of the user, other in-memory repositories are not affected.
+func (r *InMemUserRepository) Delete(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.users, id)
+	for i, userID := range r.identities {
+		if userID == id {
+			delete(r.identities, i)
+		}
+	}
+	if r.jobs != nil {
+		r.jobs.DeleteOwner(id)
+	}
+
+	return nil
+}
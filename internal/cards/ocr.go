@@ -0,0 +1,129 @@
+package cards
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/konstantinfoerster/card-service-go/internal/aerrors"
+)
+
+// OCR reads the text of a region of an upright card.
+type OCR interface {
+	Text(ctx context.Context, d Detectable, region CardRegion) (string, error)
+}
+
+// CardText the text read from the title bar and the collector line of a detected card.
+type CardText struct {
+	Name   string
+	Number string
+}
+
+type DetectOption func(*DetectService)
+
+// WithOCR reads the name and the collector number of every detected card to filter and reorder the matches,
+// a nil OCR disables it.
+func WithOCR(ocr OCR) DetectOption {
+	return func(s *DetectService) {
+		s.ocr = ocr
+	}
+}
+
+// readText reads the name and the collector number of the upright card.
+func (s *DetectService) readText(ctx context.Context, upright Detectable) (CardText, error) {
+	name, err := s.ocr.Text(ctx, upright, NameRegion)
+	if err != nil {
+		return CardText{}, aerrors.NewUnknownError(err, "ocr-failed")
+	}
+	line, err := s.ocr.Text(ctx, upright, CollectorRegion)
+	if err != nil {
+		return CardText{}, aerrors.NewUnknownError(err, "ocr-failed")
+	}
+
+	return CardText{Name: normalize(name), Number: collectorNumber(line)}, nil
+}
+
+// applyText removes all matches with a different name, if at least one match has the read name,
+// and moves the match with the read collector number to the top. Nothing changes if no match
+// has the read name or number.
+func applyText(text CardText, matches []Match) []Match {
+	if text.Name != "" {
+		named := slices.DeleteFunc(slices.Clone(matches), func(m Match) bool {
+			return !similar(text.Name, normalize(m.Name))
+		})
+		if len(named) > 0 {
+			matches = named
+		}
+	}
+
+	if text.Number != "" {
+		i := slices.IndexFunc(matches, func(m Match) bool {
+			return strings.ToLower(strings.TrimLeft(m.Number, "0")) == text.Number
+		})
+		if i > 0 {
+			m := matches[i]
+			matches = slices.Insert(slices.Delete(slices.Clone(matches), i, i+1), 0, m)
+		}
+	}
+
+	return matches
+}
+
+// normalize returns the lower case letters and digits of the text.
+func normalize(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+// collectorNumber returns the first number of the collector line without leading zeros together with
+// its suffix, e.g. 12 for "012/280 R" or 104★ for "104★ M15".
+func collectorNumber(line string) string {
+	start := strings.IndexFunc(line, unicode.IsDigit)
+	if start < 0 {
+		return ""
+	}
+	end := strings.IndexFunc(line[start:], func(r rune) bool { return unicode.IsSpace(r) || r == '/' })
+	if end < 0 {
+		end = len(line) - start
+	}
+
+	return strings.ToLower(strings.TrimLeft(line[start:start+end], "0"))
+}
+
+// similar allows one wrong character per five characters, OCR often confuses similar looking characters.
+func similar(a string, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+
+	return levenshtein(a, b) <= max(len(a), len(b))/5
+}
+
+func levenshtein(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(rb)]
+}
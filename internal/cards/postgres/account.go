@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/konstantinfoerster/card-service-go/internal/auth"
+)
+
+type PostgresAccountRepository struct {
+	db *DBConnection
+}
+
+func NewAccountRepository(connection *DBConnection) *PostgresAccountRepository {
+	return &PostgresAccountRepository{
+		db: connection,
+	}
+}
+
+func (r *PostgresAccountRepository) Create(ctx context.Context, account auth.LocalAccount) error {
+	args := pgx.NamedArgs{
+		"id":        account.ID,
+		"username":  account.Username,
+		"hash":      account.PasswordHash,
+		"createdAt": account.CreatedAt,
+	}
+	query := `
+INSERT INTO
+  local_account (id, username, password_hash, created_at)
+VALUES
+  (@id, @username, @hash, @createdAt)`
+	if _, err := r.db.Conn.Exec(ctx, query, args); err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("account %s, %w", account.Username, auth.ErrAccountExists)
+		}
+
+		return fmt.Errorf("create account failed due to exec error %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresAccountRepository) FindByUsername(ctx context.Context, username string) (auth.LocalAccount, error) {
+	args := pgx.NamedArgs{
+		"username": username,
+	}
+	query := `
+SELECT
+  id, username, password_hash, created_at
+FROM
+  local_account
+WHERE
+  username = @username`
+
+	return r.findOne(ctx, query, args)
+}
+
+func (r *PostgresAccountRepository) FindByID(ctx context.Context, id string) (auth.LocalAccount, error) {
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+	query := `
+SELECT
+  id, username, password_hash, created_at
+FROM
+  local_account
+WHERE
+  id = @id`
+
+	return r.findOne(ctx, query, args)
+}
+
+func (r *PostgresAccountRepository) UpdatePassword(ctx context.Context, id string, hash string) error {
+	args := pgx.NamedArgs{
+		"id":   id,
+		"hash": hash,
+	}
+	query := `
+UPDATE
+  local_account
+SET
+  password_hash = @hash
+WHERE
+  id = @id`
+	tag, err := r.db.Conn.Exec(ctx, query, args)
+	if err != nil {
+		return fmt.Errorf("update password failed due to exec error %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return auth.ErrAccountNotFound
+	}
+
+	return nil
+}
+
+func (r *PostgresAccountRepository) findOne(ctx context.Context, query string,
+	args pgx.NamedArgs) (auth.LocalAccount, error) {
+	var account auth.LocalAccount
+	err := r.db.Conn.QueryRow(ctx, query, args).Scan(
+		&account.ID,
+		&account.Username,
+		&account.PasswordHash,
+		&account.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return auth.LocalAccount{}, auth.ErrAccountNotFound
+		}
+
+		return auth.LocalAccount{}, fmt.Errorf("find account failed during row scan %w", err)
+	}
+
+	return account, nil
+}
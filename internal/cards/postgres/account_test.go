@@ -0,0 +1,45 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/konstantinfoerster/card-service-go/internal/auth"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	repo := postgres.NewAccountRepository(connection)
+	account := auth.LocalAccount{
+		ID:           "8b1e0d38-7c3e-4b8e-9a51-6c4a0d0b7f10",
+		Username:     "localuser",
+		PasswordHash: "hash-0",
+		CreatedAt:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	require.NoError(t, repo.Create(ctx, account))
+	err := repo.Create(ctx, auth.LocalAccount{ID: "other", Username: "localuser", CreatedAt: time.Now()})
+	require.ErrorIs(t, err, auth.ErrAccountExists)
+
+	byName, err := repo.FindByUsername(ctx, "localuser")
+	require.NoError(t, err)
+	assert.Equal(t, account.ID, byName.ID)
+	assert.True(t, account.CreatedAt.Equal(byName.CreatedAt))
+
+	require.NoError(t, repo.UpdatePassword(ctx, account.ID, "hash-1"))
+	byID, err := repo.FindByID(ctx, account.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "hash-1", byID.PasswordHash)
+
+	_, err = repo.FindByUsername(ctx, "unknown")
+	require.ErrorIs(t, err, auth.ErrAccountNotFound)
+	require.ErrorIs(t, repo.UpdatePassword(ctx, "unknown", "hash"), auth.ErrAccountNotFound)
+}
@@ -33,11 +33,10 @@ func NewCardRepository(connection *DBConnection, cfg Images) *PostgresCardReposi
 
 func (r *PostgresCardRepository) Find(ctx context.Context, f cards.Filter, page cards.Page) (cards.Cards, error) {
 	queryArgs := pgx.NamedArgs{
-		"name":    f.Name,
-		"limit":   page.Size(),
-		"offset":  page.Offset(),
-		"baseURL": r.cfg.Host,
-		"lang":    f.Lang,
+		"name":   f.Name,
+		"limit":  page.Size(),
+		"offset": page.Offset(),
+		"lang":   f.Lang,
 	}
 
 	tplParams := make(map[string]any)
@@ -80,7 +79,7 @@ AS
     DISTINCT ON (face.name)
     row_number() over (partition by face.card_id) as rn,
     face.card_id, face.id, face.name, card.number, set.code, set.name,
-    NULLIF(CONCAT(@baseURL::text, image.image_path), @baseURL::text)
+    image.id, image.image_path
     {{if .user}}, coalesce(card_collection.amount, 0) {{else}}, 0 {{end}}
   FROM
     card AS card
@@ -162,13 +161,14 @@ OFFSET @offset`)
 			&entry.Number,
 			&entry.SetCode,
 			&entry.SetName,
-			&entry.ImageURL,
+			&entry.ImageID,
+			&entry.ImagePath,
 			&entry.Amount,
 		)
 		if err != nil {
 			return cards.Cards{}, fmt.Errorf("failed to execute card scan after select %w", err)
 		}
-		result = append(result, toCard(entry))
+		result = append(result, toCard(entry, r.cfg))
 	}
 	if rows.Err() != nil {
 		return cards.Cards{}, fmt.Errorf("failed to read next row %w", rows.Err())
@@ -0,0 +1,504 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/catalog"
+)
+
+type PostgresCatalogRepository struct {
+	db *DBConnection
+}
+
+func NewCatalogRepository(connection *DBConnection) *PostgresCatalogRepository {
+	return &PostgresCatalogRepository{
+		db: connection,
+	}
+}
+
+// Upsert writes all cards in one transaction. Cards are only counted as updated if any of their values changed.
+// The total count of every set of the batch is the number of its stored cards afterward.
+func (r *PostgresCatalogRepository) Upsert(ctx context.Context, batch []catalog.Card) (catalog.Result, error) {
+	var result catalog.Result
+	err := r.db.WithTransaction(ctx, func(tx *DBConnection) error {
+		w := &catalogWriter{
+			conn:  tx.Conn,
+			langs: make(map[string]struct{}),
+			types: make(map[string]int),
+		}
+		result = catalog.Result{}
+
+		codes := make([]string, 0)
+		for _, c := range batch {
+			if slices.Contains(codes, c.Set.Code) {
+				continue
+			}
+			if err := w.upsertSet(ctx, c.Set); err != nil {
+				return err
+			}
+			codes = append(codes, c.Set.Code)
+		}
+
+		for _, c := range batch {
+			state, err := w.upsertCard(ctx, c)
+			if err != nil {
+				return fmt.Errorf("%s %s, %w", c.Set.Code, c.Number, err)
+			}
+			switch state {
+			case added:
+				result.Added++
+			case updated:
+				result.Updated++
+			case unchanged:
+			}
+		}
+
+		return w.countSetCards(ctx, codes)
+	})
+
+	return result, err
+}
+
+// writeState is the outcome of writing a card.
+type writeState int
+
+const (
+	unchanged writeState = iota
+	updated
+	added
+)
+
+// catalogWriter writes the cards of a single transaction and remembers the known languages and types.
+type catalogWriter struct {
+	conn  DBConn
+	langs map[string]struct{}
+	// types the ids of the super, card and sub types by table and name
+	types map[string]int
+}
+
+func (w *catalogWriter) upsertSet(ctx context.Context, s catalog.Set) error {
+	var blockID *int
+	if s.Block != "" {
+		query := `
+INSERT INTO card_block (block)
+VALUES (@block)
+ON CONFLICT (block) DO UPDATE SET block = EXCLUDED.block
+RETURNING id`
+		var id int
+		if err := w.conn.QueryRow(ctx, query, pgx.NamedArgs{"block": s.Block}).Scan(&id); err != nil {
+			return fmt.Errorf("failed to upsert card block %w", err)
+		}
+		blockID = &id
+	}
+
+	args := pgx.NamedArgs{
+		"code":     s.Code,
+		"name":     s.Name,
+		"type":     s.Type,
+		"released": nullDate(s),
+		"blockID":  blockID,
+	}
+	query := `
+INSERT INTO card_set (code, name, type, released, total_count, card_block_id)
+VALUES (@code, @name, @type, @released, 0, @blockID)
+ON CONFLICT (code) DO UPDATE SET
+  name = EXCLUDED.name,
+  type = EXCLUDED.type,
+  released = COALESCE(EXCLUDED.released, card_set.released),
+  card_block_id = COALESCE(EXCLUDED.card_block_id, card_set.card_block_id)`
+	if _, err := w.conn.Exec(ctx, query, args); err != nil {
+		return fmt.Errorf("failed to upsert card set %w", err)
+	}
+
+	for _, t := range s.Translations {
+		if err := w.ensureLang(ctx, t.Lang); err != nil {
+			return err
+		}
+		query := `
+INSERT INTO card_set_translation (name, lang_lang, card_set_code)
+VALUES (@name, @lang, @code)
+ON CONFLICT (lang_lang, card_set_code) DO UPDATE SET name = EXCLUDED.name`
+		args := pgx.NamedArgs{"name": t.Name, "lang": t.Lang, "code": s.Code}
+		if _, err := w.conn.Exec(ctx, query, args); err != nil {
+			return fmt.Errorf("failed to upsert card set translation %w", err)
+		}
+	}
+
+	return nil
+}
+
+func nullDate(s catalog.Set) any {
+	if s.Released.IsZero() {
+		return nil
+	}
+
+	return s.Released
+}
+
+// upsertCard reports if the card was added, updated or unchanged. Prints in other languages than english only add
+// the card if it does not exist yet, the card and its faces are defined by the english print.
+func (w *catalogWriter) upsertCard(ctx context.Context, c catalog.Card) (writeState, error) {
+	args := pgx.NamedArgs{
+		"name":   c.Name,
+		"number": c.Number,
+		"rarity": c.Rarity,
+		"border": c.Border,
+		"layout": c.Layout,
+		"code":   c.Set.Code,
+	}
+	conflict := `DO UPDATE SET name = EXCLUDED.name, rarity = EXCLUDED.rarity, border = EXCLUDED.border,
+  layout = EXCLUDED.layout
+WHERE
+  (card.name, card.rarity, card.border, card.layout)
+  IS DISTINCT FROM (EXCLUDED.name, EXCLUDED.rarity, EXCLUDED.border, EXCLUDED.layout)`
+	if !c.English() {
+		conflict = `DO NOTHING`
+	}
+	query := `
+INSERT INTO card (name, number, rarity, border, layout, card_set_code)
+VALUES (@name, @number, @rarity, @border, @layout, @code)
+ON CONFLICT (card_set_code, number) ` + conflict + `
+RETURNING id, (xmax <> 0)`
+	var cardID int
+	var existed bool
+	state := added
+	err := w.conn.QueryRow(ctx, query, args).Scan(&cardID, &existed)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// the card exists and was left as it is
+		state = unchanged
+		err = w.conn.QueryRow(ctx, `SELECT id FROM card WHERE card_set_code = @code AND number = @number`, args).
+			Scan(&cardID)
+	} else if existed {
+		state = updated
+	}
+	if err != nil {
+		return unchanged, fmt.Errorf("failed to upsert card %w", err)
+	}
+
+	changed := false
+	faceIDs := make([]int, 0, len(c.Faces))
+	for _, f := range c.Faces {
+		id, faceChanged, err := w.upsertFace(ctx, cardID, f, state == added || c.English())
+		if err != nil {
+			return unchanged, err
+		}
+		changed = changed || faceChanged
+		faceIDs = append(faceIDs, id)
+	}
+
+	for _, img := range c.Images {
+		imgChanged, err := w.upsertImage(ctx, cardID, faceIDs[img.Face], img)
+		if err != nil {
+			return unchanged, err
+		}
+		changed = changed || imgChanged
+	}
+
+	if state == unchanged && changed {
+		return updated, nil
+	}
+
+	return state, nil
+}
+
+// upsertFace returns the id of the face and if anything changed, an existing face is only updated if overwrite is
+// set.
+func (w *catalogWriter) upsertFace(
+	ctx context.Context, cardID int, f catalog.Face, overwrite bool,
+) (int, bool, error) {
+	args := pgx.NamedArgs{
+		"cardID":       cardID,
+		"name":         f.Name,
+		"text":         nullString(f.Text, 800),
+		"flavorText":   nullString(f.FlavorText, 500),
+		"typeLine":     nullString(f.TypeLine, 255),
+		"cmc":          f.ConvertedManaCost,
+		"colors":       nullString(strings.Join(f.Colors, ","), 100),
+		"artist":       nullString(f.Artist, 100),
+		"hand":         nullString(f.HandModifier, 10),
+		"life":         nullString(f.LifeModifier, 10),
+		"loyalty":      nullString(f.Loyalty, 10),
+		"manaCost":     nullString(f.ManaCost, 255),
+		"multiverseID": nullInt(f.MultiverseID),
+		"power":        nullString(f.Power, 255),
+		"toughness":    nullString(f.Toughness, 255),
+	}
+
+	var faceID int
+	changed := true
+	err := w.conn.QueryRow(ctx,
+		`SELECT id FROM card_face WHERE card_id = @cardID AND name = @name ORDER BY id LIMIT 1`, args,
+	).Scan(&faceID)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		query := `
+INSERT INTO card_face (card_id, name, text, flavor_text, type_line, converted_mana_cost, colors, artist,
+  hand_modifier, life_modifier, loyalty, mana_cost, multiverse_id, power, toughness)
+VALUES (@cardID, @name, @text, @flavorText, @typeLine, @cmc, @colors, @artist,
+  @hand, @life, @loyalty, @manaCost, @multiverseID, @power, @toughness)
+RETURNING id`
+		if err := w.conn.QueryRow(ctx, query, args).Scan(&faceID); err != nil {
+			return 0, false, fmt.Errorf("failed to insert card face %w", err)
+		}
+	case err != nil:
+		return 0, false, fmt.Errorf("failed to find card face %w", err)
+	case overwrite:
+		args["id"] = faceID
+		query := `
+UPDATE card_face SET
+  text = @text, flavor_text = @flavorText, type_line = @typeLine, converted_mana_cost = @cmc, colors = @colors,
+  artist = @artist, hand_modifier = @hand, life_modifier = @life, loyalty = @loyalty, mana_cost = @manaCost,
+  multiverse_id = @multiverseID, power = @power, toughness = @toughness
+WHERE
+  id = @id
+  AND (text, flavor_text, type_line, converted_mana_cost, colors, artist, hand_modifier, life_modifier, loyalty,
+    mana_cost, multiverse_id, power, toughness)
+  IS DISTINCT FROM (@text, @flavorText, @typeLine, @cmc, @colors, @artist, @hand, @life, @loyalty,
+    @manaCost, @multiverseID, @power, @toughness)`
+		tag, err := w.conn.Exec(ctx, query, args)
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to update card face %w", err)
+		}
+		changed = tag.RowsAffected() > 0
+	default:
+		// the face of another language than english, only the translations are written
+		translated, err := w.upsertTranslations(ctx, faceID, f.Translations)
+
+		return faceID, translated, err
+	}
+
+	typesChanged, err := w.replaceTypes(ctx, faceID, f)
+	if err != nil {
+		return 0, false, err
+	}
+	translated, err := w.upsertTranslations(ctx, faceID, f.Translations)
+
+	return faceID, changed || typesChanged || translated, err
+}
+
+// upsertTranslations reports if any translation was added or changed.
+func (w *catalogWriter) upsertTranslations(
+	ctx context.Context, faceID int, translations []catalog.Translation,
+) (bool, error) {
+	changed := false
+	for _, t := range translations {
+		if err := w.ensureLang(ctx, t.Lang); err != nil {
+			return false, err
+		}
+		args := pgx.NamedArgs{
+			"name":         t.Name,
+			"multiverseID": nullInt(t.MultiverseID),
+			"text":         nullString(t.Text, 800),
+			"flavorText":   nullString(t.FlavorText, 500),
+			"typeLine":     nullString(t.TypeLine, 255),
+			"lang":         t.Lang,
+			"faceID":       faceID,
+		}
+		query := `
+INSERT INTO card_translation (name, multiverse_id, text, flavor_text, type_line, lang_lang, face_id)
+VALUES (@name, @multiverseID, @text, @flavorText, @typeLine, @lang, @faceID)
+ON CONFLICT (lang_lang, face_id) DO UPDATE SET
+  name = EXCLUDED.name,
+  multiverse_id = EXCLUDED.multiverse_id,
+  text = EXCLUDED.text,
+  flavor_text = EXCLUDED.flavor_text,
+  type_line = EXCLUDED.type_line
+WHERE
+  (card_translation.name, card_translation.multiverse_id, card_translation.text, card_translation.flavor_text,
+    card_translation.type_line)
+  IS DISTINCT FROM (EXCLUDED.name, EXCLUDED.multiverse_id, EXCLUDED.text, EXCLUDED.flavor_text, EXCLUDED.type_line)`
+		tag, err := w.conn.Exec(ctx, query, args)
+		if err != nil {
+			return false, fmt.Errorf("failed to upsert card translation %w", err)
+		}
+		changed = changed || tag.RowsAffected() > 0
+	}
+
+	return changed, nil
+}
+
+// replaceTypes links the face with exactly its super, card and sub types and reports if any link changed.
+func (w *catalogWriter) replaceTypes(ctx context.Context, faceID int, f catalog.Face) (bool, error) {
+	links := []struct {
+		typeTable string
+		linkTable string
+		names     []string
+	}{
+		{typeTable: "super_type", linkTable: "face_super_type", names: f.SuperTypes},
+		{typeTable: "card_type", linkTable: "face_card_type", names: f.Types},
+		{typeTable: "sub_type", linkTable: "face_sub_type", names: f.SubTypes},
+	}
+	changed := false
+	for _, l := range links {
+		typeIDs := make([]int, 0, len(l.names))
+		for _, name := range l.names {
+			typeID, err := w.typeID(ctx, l.typeTable, name)
+			if err != nil {
+				return false, err
+			}
+			typeIDs = append(typeIDs, typeID)
+		}
+
+		query := `DELETE FROM ` + l.linkTable + ` WHERE face_id = $1 AND NOT (type_id = ANY($2))`
+		tag, err := w.conn.Exec(ctx, query, faceID, typeIDs)
+		if err != nil {
+			return false, fmt.Errorf("failed to delete %s %w", l.linkTable, err)
+		}
+		changed = changed || tag.RowsAffected() > 0
+		query = `INSERT INTO ` + l.linkTable + ` (face_id, type_id) SELECT $1, unnest($2::int[]) ON CONFLICT DO NOTHING`
+		tag, err = w.conn.Exec(ctx, query, faceID, typeIDs)
+		if err != nil {
+			return false, fmt.Errorf("failed to insert %s %w", l.linkTable, err)
+		}
+		changed = changed || tag.RowsAffected() > 0
+	}
+
+	return changed, nil
+}
+
+func (w *catalogWriter) typeID(ctx context.Context, table string, name string) (int, error) {
+	key := table + ":" + name
+	if id, ok := w.types[key]; ok {
+		return id, nil
+	}
+
+	query := `INSERT INTO ` + table + ` (name) VALUES ($1)
+ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+RETURNING id`
+	var id int
+	if err := w.conn.QueryRow(ctx, query, name).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to upsert %s %w", table, err)
+	}
+	w.types[key] = id
+
+	return id, nil
+}
+
+// upsertImage reports if the image was added or changed.
+func (w *catalogWriter) upsertImage(ctx context.Context, cardID int, faceID int, img catalog.Image) (bool, error) {
+	if err := w.ensureLang(ctx, img.Lang); err != nil {
+		return false, err
+	}
+	args := pgx.NamedArgs{
+		"path":     img.Path,
+		"cardID":   cardID,
+		"faceID":   faceID,
+		"mimeType": img.MimeType,
+		"lang":     img.Lang,
+	}
+	query := `
+INSERT INTO card_image (image_path, card_id, face_id, mime_type, lang_lang)
+VALUES (@path, @cardID, @faceID, @mimeType, @lang)
+ON CONFLICT (image_path) DO UPDATE SET
+  card_id = EXCLUDED.card_id,
+  face_id = EXCLUDED.face_id,
+  mime_type = EXCLUDED.mime_type,
+  lang_lang = EXCLUDED.lang_lang
+WHERE
+  (card_image.card_id, card_image.face_id, card_image.mime_type, card_image.lang_lang)
+  IS DISTINCT FROM (EXCLUDED.card_id, EXCLUDED.face_id, EXCLUDED.mime_type, EXCLUDED.lang_lang)`
+	tag, err := w.conn.Exec(ctx, query, args)
+	if err != nil {
+		return false, fmt.Errorf("failed to upsert card image %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// ensureLang adds the language, the bulk files contain more languages than the initial ones.
+func (w *catalogWriter) ensureLang(ctx context.Context, lang string) error {
+	if _, ok := w.langs[lang]; ok {
+		return nil
+	}
+	if _, err := w.conn.Exec(ctx, `INSERT INTO lang (lang) VALUES ($1) ON CONFLICT DO NOTHING`, lang); err != nil {
+		return fmt.Errorf("failed to insert language %w", err)
+	}
+	w.langs[lang] = struct{}{}
+
+	return nil
+}
+
+func (w *catalogWriter) countSetCards(ctx context.Context, codes []string) error {
+	query := `
+UPDATE card_set SET
+  total_count = (SELECT count(*) FROM card WHERE card.card_set_code = card_set.code)
+WHERE
+  code = ANY($1)`
+	if _, err := w.conn.Exec(ctx, query, codes); err != nil {
+		return fmt.Errorf("failed to count set cards %w", err)
+	}
+
+	return nil
+}
+
+// nullString returns nil for an empty string and cuts the string to the size of the column.
+func nullString(v string, size int) any {
+	if v == "" {
+		return nil
+	}
+	if r := []rune(v); len(r) > size {
+		return string(r[:size])
+	}
+
+	return v
+}
+
+func nullInt(v int) any {
+	if v == 0 {
+		return nil
+	}
+
+	return v
+}
+
+// Entries returns the card images together with the shown face, images without a face show the card.
+func (r *PostgresCatalogRepository) Entries(ctx context.Context, afterID int, limit int) ([]catalog.Entry, error) {
+	args := pgx.NamedArgs{
+		"afterID": afterID,
+		"limit":   limit,
+	}
+	query := `
+SELECT
+  image.id, image.card_id, COALESCE(image.face_id, 0), COALESCE(face.name, card.name), card.number,
+  card_set.name, card_set.code, image.image_path
+FROM
+  card_image AS image
+JOIN
+  card ON card.id = image.card_id
+JOIN
+  card_set ON card_set.code = card.card_set_code
+LEFT JOIN
+  card_face AS face ON face.id = image.face_id
+WHERE
+  image.id > @afterID
+ORDER BY
+  image.id
+LIMIT @limit`
+	rows, err := r.db.Conn.Query(ctx, query, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute card image select %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]catalog.Entry, 0, limit)
+	for rows.Next() {
+		var e catalog.Entry
+		c := &e.Card
+		err := rows.Scan(&e.ImageID, &c.ID.CardID, &c.ID.FaceID, &c.Name, &c.Number, &c.Set.Name, &c.Set.Code,
+			&c.Image.URL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute card image scan after select %w", err)
+		}
+		result = append(result, e)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("failed to read next row %w", rows.Err())
+	}
+
+	return result, nil
+}
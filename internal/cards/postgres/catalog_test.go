@@ -0,0 +1,134 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/catalog"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatalogUpsert(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	catalogRepo := postgres.NewCatalogRepository(connection)
+	ctx := context.Background()
+	t.Cleanup(func() { deleteCatalogSet(t, "TCAT") })
+	english := catalogCard("eng", "Catalog Card")
+	german := catalogCard("deu", "Katalogkarte")
+
+	added, err := catalogRepo.Upsert(ctx, []catalog.Card{english})
+	require.NoError(t, err)
+	english.Faces[0].Text = "updated text"
+	updated, err := catalogRepo.Upsert(ctx, []catalog.Card{english, german})
+	require.NoError(t, err)
+	unchanged, err := catalogRepo.Upsert(ctx, []catalog.Card{english, german})
+	require.NoError(t, err)
+
+	assert.Equal(t, catalog.Result{Added: 1}, added)
+	assert.Equal(t, catalog.Result{Updated: 2}, updated)
+	assert.Equal(t, catalog.Result{}, unchanged)
+	var text, translated string
+	var totalCount, faces, images, types int
+	err = connection.Conn.QueryRow(ctx, `
+SELECT face.text, trans.name, cs.total_count,
+  (SELECT count(*) FROM card_face f JOIN card c ON c.id = f.card_id WHERE c.card_set_code = cs.code),
+  (SELECT count(*) FROM card_image i JOIN card c ON c.id = i.card_id WHERE c.card_set_code = cs.code),
+  (SELECT count(*) FROM face_sub_type st WHERE st.face_id = face.id)
+FROM card_set cs
+JOIN card c ON c.card_set_code = cs.code
+JOIN card_face face ON face.card_id = c.id
+JOIN card_translation trans ON trans.face_id = face.id AND trans.lang_lang = 'deu'
+WHERE cs.code = 'TCAT'`).Scan(&text, &translated, &totalCount, &faces, &images, &types)
+	require.NoError(t, err)
+	assert.Equal(t, "updated text", text)
+	assert.Equal(t, "Katalogkarte", translated)
+	assert.Equal(t, 1, totalCount)
+	assert.Equal(t, 1, faces)
+	assert.Equal(t, 2, images)
+	assert.Equal(t, 2, types)
+}
+
+func TestCatalogEntries(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	catalogRepo := postgres.NewCatalogRepository(connection)
+	ctx := context.Background()
+
+	first, err := catalogRepo.Entries(ctx, 0, 2)
+	require.NoError(t, err)
+	next, err := catalogRepo.Entries(ctx, first[1].ImageID, 1)
+	require.NoError(t, err)
+
+	require.Len(t, first, 2)
+	assert.Equal(t, cards.Card{
+		ID:     cards.NewID(1).WithFace(1),
+		Name:   "Dummy Card 1",
+		Number: "1",
+		Set:    cards.Set{Name: "Magic 2010", Code: "M10"},
+		Image:  cards.Image{URL: "images/dummyCard1.png"},
+	}, first[0].Card)
+	require.Len(t, next, 1)
+	assert.Equal(t, "images/dummyCard3.png", next[0].Card.Image.URL)
+}
+
+func catalogCard(lang string, printedName string) catalog.Card {
+	face := catalog.Face{
+		Name:              "Catalog Card",
+		Text:              "original text",
+		TypeLine:          "Creature — Elf Druid",
+		ConvertedManaCost: 1,
+		Colors:            []string{"G"},
+		SuperTypes:        []string{},
+		Types:             []string{"Creature"},
+		SubTypes:          []string{"Elf", "Druid"},
+	}
+	if lang != "eng" {
+		face.Translations = []catalog.Translation{{Lang: lang, Name: printedName}}
+	}
+
+	return catalog.Card{
+		Set: catalog.Set{
+			Code:         "TCAT",
+			Name:         "Catalog Set",
+			Type:         "EXPANSION",
+			Released:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			Block:        "Catalog Block",
+			Translations: []catalog.SetTranslation{{Lang: "deu", Name: "Katalog"}},
+		},
+		Name:   "Catalog Card",
+		Number: "1",
+		Rarity: "COMMON",
+		Border: "BLACK",
+		Layout: "NORMAL",
+		Lang:   lang,
+		Faces:  []catalog.Face{face},
+		Images: []catalog.Image{{Face: 0, Lang: lang, Path: "catalog/" + lang + ".jpg", MimeType: "image/jpeg"}},
+	}
+}
+
+func deleteCatalogSet(t *testing.T, code string) {
+	t.Helper()
+
+	ctx := context.Background()
+	faces := `SELECT f.id FROM card_face f JOIN card c ON c.id = f.card_id WHERE c.card_set_code = $1`
+	cardIDs := `SELECT id FROM card WHERE card_set_code = $1`
+	queries := []string{
+		`DELETE FROM face_super_type WHERE face_id IN (` + faces + `)`,
+		`DELETE FROM face_card_type WHERE face_id IN (` + faces + `)`,
+		`DELETE FROM face_sub_type WHERE face_id IN (` + faces + `)`,
+		`DELETE FROM card_image WHERE card_id IN (` + cardIDs + `)`,
+		`DELETE FROM card WHERE card_set_code = $1`,
+		`DELETE FROM card_set WHERE code = $1`,
+	}
+	for _, q := range queries {
+		_, err := connection.Conn.Exec(ctx, q, code)
+		require.NoError(t, err)
+	}
+}
@@ -3,6 +3,10 @@ package postgres
 import (
 	"fmt"
 	"net"
+	"strconv"
+
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/storage"
 )
 
 type Config struct {
@@ -12,6 +16,8 @@ type Config struct {
 	Username string `yaml:"username"`
 	Password string `yaml:"password"`
 	MaxConns int32  `yaml:"max_conns"`
+	// AutoMigrate applies the pending migrations on startup.
+	AutoMigrate bool `yaml:"auto_migrate"`
 }
 
 func (d Config) ConnectionURL() string {
@@ -19,5 +25,40 @@ func (d Config) ConnectionURL() string {
 }
 
 type Images struct {
+	// Host the base URL of the card images, either a local directory or a http(s) URL.
 	Host string `yaml:"host"`
+	// Dir the local directory with the card images, if set the images are served by the /images endpoint.
+	Dir string `yaml:"dir"`
+	// CacheDir the directory where the generated thumbnails are stored.
+	CacheDir string `yaml:"cache_dir"`
+	// S3 a bucket with the card images, replaces the directory and the host if set. The images are served
+	// by the /images endpoint.
+	S3 storage.S3Config `yaml:"s3"`
+}
+
+// Served returns true if the card images are served by the /images endpoint.
+func (i Images) Served() bool {
+	return i.Dir != "" || i.S3.Enabled()
+}
+
+// Store returns the store of the card images, either the S3 bucket, the local directory or the host.
+func (i Images) Store() (cards.ImageStore, error) {
+	location := i.Host
+	if i.Dir != "" {
+		location = i.Dir
+	}
+
+	return storage.New(location, i.S3)
+}
+
+// URL returns the URL of the card image, an empty string if the card has no image.
+func (i Images) URL(id int, path string) string {
+	if path == "" {
+		return ""
+	}
+	if i.Served() {
+		return "/images/" + strconv.Itoa(id)
+	}
+
+	return i.Host + path
 }
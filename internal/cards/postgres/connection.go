@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -66,6 +67,13 @@ func (d *DBConnection) WithTransaction(ctx context.Context, f func(conn *DBConne
 	return nil
 }
 
+// isUniqueViolation returns true if the error was caused by a unique constraint violation.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
 // DBConn implemented by pgx.Conn and pgx.Tx.
 type DBConn interface {
 	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
@@ -3,79 +3,241 @@ package postgres
 import (
 	"context"
 	"fmt"
-	"strings"
+	"log/slog"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/konstantinfoerster/card-service-go/internal/cards"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/index"
 )
 
+// PostgresDetectRepository searches the card image hashes of the configured algorithms in an in-memory index.
+// The index is filled with Load and kept up to date with Refresh or Watch.
 type PostgresDetectRepository struct {
 	db  *DBConnection
 	cfg Images
+	// algs the configured hash algorithms, only hashes of them are loaded
+	algs    []string
+	matcher *index.Matcher
+	// version identifies the loaded card images, the index is only reloaded if it changes
+	version imagesVersion
+	mu      sync.Mutex
 }
 
-func NewDetectRepository(connection *DBConnection, cfg Images) *PostgresDetectRepository {
+// imagesVersion the number of hashes detects removed hashes, the latest update added and updated hashes.
+type imagesVersion struct {
+	count     int
+	updatedAt time.Time
+}
+
+func (v imagesVersion) equal(o imagesVersion) bool {
+	return v.count == o.count && v.updatedAt.Equal(o.updatedAt)
+}
+
+func NewDetectRepository(connection *DBConnection, cfg Images, dCfg cards.DetectConfig) *PostgresDetectRepository {
 	return &PostgresDetectRepository{
-		db:  connection,
-		cfg: cfg,
+		db:      connection,
+		cfg:     cfg,
+		algs:    algorithmNames(dCfg.Algorithms()),
+		matcher: index.NewMatcher(dCfg),
 	}
 }
 
-func (r *PostgresDetectRepository) Top5MatchesByHash(ctx context.Context, hashes ...cards.Hash) (cards.Scores, error) {
-	defer cards.TimeTracker(time.Now(), "Top5MatchesByHash")
-	if len(hashes) == 0 {
-		return cards.Scores{}, nil
+// Load reads all card image hashes into the index.
+func (r *PostgresDetectRepository) Load(ctx context.Context) error {
+	defer cards.TimeTracker(time.Now(), "LoadHashIndex")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	version, err := r.currentVersion(ctx)
+	if err != nil {
+		return err
 	}
 
-	limit := 5
-	queryArgs := []any{limit}
-	var sb strings.Builder
-	sb.WriteString("LEAST(")
-	for i, hash := range hashes {
-		if i > 0 {
-			sb.WriteString(",")
-		}
-		sb.WriteString("(")
-		for x, v := range hash.AsBase2() {
-			if x > 0 {
-				sb.WriteString("+")
+	return r.load(ctx, version)
+}
+
+// Refresh reloads the index if hashes were added, updated or removed since the last load.
+func (r *PostgresDetectRepository) Refresh(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	version, err := r.currentVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if version.equal(r.version) {
+		return nil
+	}
+
+	return r.load(ctx, version)
+}
+
+// Watch refreshes the index every interval until the context is canceled. An interval of zero
+// disables the refresh.
+func (r *PostgresDetectRepository) Watch(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := r.Refresh(ctx); err != nil {
+				slog.Warn("failed to refresh hash index", slog.Any("error", err))
 			}
-			queryArgs = append(queryArgs, v)
-			sb.WriteString(fmt.Sprintf("BIT_COUNT(image.phash%d # $%d)", x+1, len(queryArgs)))
 		}
-		sb.WriteString(")")
 	}
-	sb.WriteString(")")
+}
+
+func (r *PostgresDetectRepository) currentVersion(ctx context.Context) (imagesVersion, error) {
+	query := `
+SELECT count(*), COALESCE(max(updated_at), 'epoch'::timestamptz) FROM card_image_hash WHERE algorithm = ANY($1)`
+
+	var v imagesVersion
+	if err := r.db.Conn.QueryRow(ctx, query, r.algs).Scan(&v.count, &v.updatedAt); err != nil {
+		return imagesVersion{}, fmt.Errorf("failed to read card image version %w", err)
+	}
+
+	return v, nil
+}
 
-	query := fmt.Sprintf(`
+func (r *PostgresDetectRepository) load(ctx context.Context, version imagesVersion) error {
+	query := `
 SELECT
-  image.card_id, image.face_id, %s
+  image.card_id, COALESCE(image.face_id, 0), hash.algorithm,
+  hash.hash1::bigint, hash.hash2::bigint, hash.hash3::bigint, hash.hash4::bigint
 FROM
-  card_image as image
+  card_image_hash as hash
+JOIN
+  card_image as image ON image.id = hash.card_image_id
 WHERE
-  CAST(%s as int) < 60
-GROUP BY
-  image.card_id, image.face_id, image.phash1, image.phash2, image.phash3, image.phash4
-ORDER BY
-  image.face_id, %s
-LIMIT $1`, sb.String(), sb.String(), sb.String())
-	rows, err := r.db.Conn.Query(ctx, query, queryArgs...)
+  hash.algorithm = ANY($1)`
+	rows, err := r.db.Conn.Query(ctx, query, r.algs)
 	if err != nil {
-		return cards.Scores{}, fmt.Errorf("failed to execute top 5 phash select %w", err)
+		return fmt.Errorf("failed to execute card image hash select %w", err)
 	}
 	defer rows.Close()
 
-	var result cards.Scores
+	entries := make([]index.Entry, 0, version.count)
 	for rows.Next() {
-		entry := cards.Score{}
-		if err = rows.Scan(&entry.ID.CardID, &entry.ID.FaceID, &entry.Score); err != nil {
-			return cards.Scores{}, fmt.Errorf("failed to execute top 5 phash result scan %w", err)
+		var id cards.ID
+		var alg string
+		var p1, p2, p3, p4 int64
+		if err = rows.Scan(&id.CardID, &id.FaceID, &alg, &p1, &p2, &p3, &p4); err != nil {
+			return fmt.Errorf("failed to execute card image hash result scan %w", err)
 		}
-		result = append(result, entry)
+
+		entries = append(entries, index.Entry{
+			ID: id,
+			Hash: cards.Hash{
+				Algorithm: cards.HashAlgorithm(alg),
+				//nolint:gosec // the bit strings are read as signed bigint, the conversion keeps the bits
+				Value: []uint64{uint64(p1), uint64(p2), uint64(p3), uint64(p4)},
+				Bits:  hashParts * 64,
+			},
+		})
 	}
 	if rows.Err() != nil {
-		return cards.Scores{}, fmt.Errorf("failed to read next top 5 hash row %w", rows.Err())
+		return fmt.Errorf("failed to read next card image hash row %w", rows.Err())
+	}
+
+	r.matcher.Load(entries)
+	r.version = version
+	slog.Info("hash index loaded", slog.Int("hashes", r.matcher.Len()))
+
+	return nil
+}
+
+func (r *PostgresDetectRepository) Top5MatchesByHash(_ context.Context, hashes ...cards.Hash) (cards.Scores, error) {
+	defer cards.TimeTracker(time.Now(), "Top5MatchesByHash")
+
+	limit := 5
+
+	return r.matcher.Top(limit, hashes...), nil
+}
+
+// RegionHashes reads the region hashes of the cards, they are only needed for a few candidates
+// and therefore not part of the index.
+func (r *PostgresDetectRepository) RegionHashes(
+	ctx context.Context, alg cards.HashAlgorithm, ids ...cards.ID,
+) (map[cards.ID][]cards.RegionHash, error) {
+	result := make(map[cards.ID][]cards.RegionHash, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	cardIDs := make([]int, 0, len(ids))
+	for _, id := range ids {
+		cardIDs = append(cardIDs, id.CardID)
+	}
+
+	query := `
+SELECT
+  image.card_id, COALESCE(image.face_id, 0), hash.region,
+  hash.hash1::bigint, hash.hash2::bigint, hash.hash3::bigint, hash.hash4::bigint
+FROM
+  card_image_region_hash as hash
+JOIN
+  card_image as image ON image.id = hash.card_image_id
+WHERE
+  hash.algorithm = $1 AND image.card_id = ANY($2)`
+	rows, err := r.db.Conn.Query(ctx, query, string(alg), cardIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute card region hash select %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id cards.ID
+		var region string
+		var p1, p2, p3, p4 int64
+		if err = rows.Scan(&id.CardID, &id.FaceID, &region, &p1, &p2, &p3, &p4); err != nil {
+			return nil, fmt.Errorf("failed to execute card region hash result scan %w", err)
+		}
+
+		i := slices.IndexFunc(ids, id.Eq)
+		if i < 0 {
+			continue
+		}
+		result[ids[i]] = append(result[ids[i]], cards.RegionHash{
+			Region: cards.CardRegion(region),
+			Hash: cards.Hash{
+				Algorithm: alg,
+				//nolint:gosec // the bit strings are read as signed bigint, the conversion keeps the bits
+				Value: []uint64{uint64(p1), uint64(p2), uint64(p3), uint64(p4)},
+				Bits:  hashParts * 64,
+			},
+		})
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("failed to read next card region hash row %w", rows.Err())
+	}
+
+	return result, nil
+}
+
+func algorithmNames(algs []cards.HashAlgorithm) []string {
+	names := make([]string, 0, len(algs))
+	for _, alg := range algs {
+		names = append(names, string(alg))
+	}
+
+	return names
+}
+
+func regionNames(regions []cards.CardRegion) []string {
+	names := make([]string, 0, len(regions))
+	for _, r := range regions {
+		names = append(names, string(r))
 	}
 
-	return result, err
+	return names
 }
@@ -2,10 +2,15 @@ package postgres_test
 
 import (
 	"context"
+	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/konstantinfoerster/card-service-go/internal/cards"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/imaging"
 	"github.com/konstantinfoerster/card-service-go/internal/cards/postgres"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -15,9 +20,11 @@ func TestTop5MatchesByHash(t *testing.T) {
 		t.Skip("skipping integration test")
 	}
 
-	detectRepo := postgres.NewDetectRepository(connection, postgres.Images{})
-	unknownHash := cards.Hash{Value: []uint64{1, 2, 3, 4}}
+	detectRepo := postgres.NewDetectRepository(connection, postgres.Images{}, cards.DetectConfig{})
+	require.NoError(t, detectRepo.Load(context.Background()))
+	unknownHash := cards.Hash{Algorithm: cards.PHash, Value: []uint64{1, 2, 3, 4}}
 	hash := cards.Hash{
+		Algorithm: cards.PHash,
 		Value: []uint64{
 			9223372036854775807,
 			8828676655832293646,
@@ -42,8 +49,9 @@ func Top5MatchesByHashNoResult(t *testing.T) {
 		t.Skip("skipping integration test")
 	}
 	cfg := postgres.Images{}
-	detectRepo := postgres.NewDetectRepository(connection, cfg)
-	unknownHash := cards.Hash{Value: []uint64{1, 2, 3, 4}}
+	detectRepo := postgres.NewDetectRepository(connection, cfg, cards.DetectConfig{})
+	require.NoError(t, detectRepo.Load(context.Background()))
+	unknownHash := cards.Hash{Algorithm: cards.PHash, Value: []uint64{1, 2, 3, 4}}
 
 	ctx := context.Background()
 	result, err := detectRepo.Top5MatchesByHash(ctx, unknownHash)
@@ -51,3 +59,176 @@ func Top5MatchesByHashNoResult(t *testing.T) {
 	require.NoError(t, err)
 	require.Empty(t, result)
 }
+
+func TestRefreshLoadsNewImages(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	detectRepo := postgres.NewDetectRepository(connection, postgres.Images{}, cards.DetectConfig{})
+	ctx := context.Background()
+	require.NoError(t, detectRepo.Load(ctx))
+	newHash := cards.Hash{Algorithm: cards.PHash, Value: []uint64{5, 6, 7, 8}}
+	result, err := detectRepo.Top5MatchesByHash(ctx, newHash)
+	require.NoError(t, err)
+	require.Empty(t, result)
+
+	query := `INSERT INTO card_image(face_id, card_id, image_path, lang_lang, mime_type)
+VALUES (1, 1, 'images/refresh.png', 'eng', 'png') RETURNING id`
+	var imageID int
+	require.NoError(t, connection.Conn.QueryRow(ctx, query).Scan(&imageID))
+	t.Cleanup(func() {
+		_, err := connection.Conn.Exec(ctx, "DELETE FROM card_image WHERE id = $1", imageID)
+		require.NoError(t, err)
+	})
+	err = postgres.NewHashRepository(connection).UpdateHashes(ctx, []cards.ImageHash{{ImageID: imageID, Hash: newHash}})
+	require.NoError(t, err)
+
+	require.NoError(t, detectRepo.Refresh(ctx))
+	result, err = detectRepo.Top5MatchesByHash(ctx, newHash)
+
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Equal(t, cards.Score{ID: cards.ID{CardID: 1, FaceID: 1}, Score: 0}, result[0])
+}
+
+func TestRefreshLoadsUpdatedHashes(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	hashRepo := postgres.NewHashRepository(connection)
+	oldHash := cards.Hash{Algorithm: cards.PHash, Value: []uint64{9, 10, 11, 12}}
+	newHash := cards.Hash{Algorithm: cards.PHash, Value: []uint64{13, 14, 15, 16}}
+	query := `INSERT INTO card_image(face_id, card_id, image_path, lang_lang, mime_type)
+VALUES (1, 1, 'images/update.png', 'eng', 'png') RETURNING id`
+	var imageID int
+	require.NoError(t, connection.Conn.QueryRow(ctx, query).Scan(&imageID))
+	t.Cleanup(func() {
+		_, err := connection.Conn.Exec(ctx, "DELETE FROM card_image WHERE id = $1", imageID)
+		require.NoError(t, err)
+	})
+	require.NoError(t, hashRepo.UpdateHashes(ctx, []cards.ImageHash{{ImageID: imageID, Hash: oldHash}}))
+	detectRepo := postgres.NewDetectRepository(connection, postgres.Images{}, cards.DetectConfig{})
+	require.NoError(t, detectRepo.Load(ctx))
+
+	require.NoError(t, hashRepo.UpdateHashes(ctx, []cards.ImageHash{{ImageID: imageID, Hash: newHash}}))
+	require.NoError(t, detectRepo.Refresh(ctx))
+	result, err := detectRepo.Top5MatchesByHash(ctx, newHash)
+
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Equal(t, cards.Score{ID: cards.ID{CardID: 1, FaceID: 1}, Score: 0}, result[0])
+}
+
+func TestRegionHashes(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	detectRepo := postgres.NewDetectRepository(connection, postgres.Images{}, cards.DetectConfig{})
+	withHash := cards.NewID(11)
+	withoutHash := cards.NewID(12)
+
+	result, err := detectRepo.RegionHashes(context.Background(), cards.PHash, withHash, withoutHash)
+
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Equal(t, []cards.RegionHash{{
+		Region: cards.ArtRegion,
+		Hash:   cards.Hash{Algorithm: cards.PHash, Value: []uint64{1, 2, 3, 4}, Bits: 256},
+	}}, result[withHash])
+}
+
+func TestDetectReranksPrintsWithBackfilledRegionHashes(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	rerank := cards.RerankConfig{Regions: []cards.CardRegion{cards.ArtRegion}}
+	hasher := imaging.NewHasher(storage.NewLocalStore(testdataDir()))
+	hashRepo := postgres.NewHashRepository(connection)
+	// the photo shows the art of the first print
+	artPrint, artImageID := insertPrint(t, "M15", "images/rerankArt.jpg")
+	otherPrint, otherImageID := insertPrint(t, "M16", "images/rerankOther.jpg")
+	backfill := cards.NewBackfillService(hashRepo, hasher)
+	_, err := backfill.Run(ctx, cards.BackfillConfig{Rerank: rerank, Workers: 1, BatchSize: 10, AfterID: artImageID - 1})
+	require.NoError(t, err)
+	// the whole image of the other print becomes the better match
+	photo, err := hasher.Hash(ctx, "images/rerankArt.jpg", cards.PHash)
+	require.NoError(t, err)
+	worse := cards.Hash{Algorithm: cards.PHash, Value: slices.Clone(photo[0].Value), Bits: photo[0].Bits}
+	worse.Value[0] ^= 0xF
+	require.NoError(t, hashRepo.UpdateHashes(ctx, []cards.ImageHash{
+		{ImageID: otherImageID, Hash: photo[0]},
+		{ImageID: artImageID, Hash: worse},
+	}))
+
+	cases := []struct {
+		name     string
+		rerank   cards.RerankConfig
+		expected []cards.ID
+	}{
+		{
+			name:     "disabled",
+			expected: []cards.ID{otherPrint, artPrint},
+		},
+		{
+			name:     "prints ordered by art",
+			rerank:   rerank,
+			expected: []cards.ID{artPrint, otherPrint},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := cards.DetectConfig{Rerank: tc.rerank}
+			detectRepo := postgres.NewDetectRepository(connection, postgres.Images{}, cfg)
+			require.NoError(t, detectRepo.Load(ctx))
+			cardRepo := postgres.NewCardRepository(connection, postgres.Images{})
+			svc := cards.NewDetectService(cfg, cardRepo, detectRepo, cardRepo, imaging.NewFakeDetector())
+			img, err := os.Open(filepath.Join(testdataDir(), "images", "rerankArt.jpg"))
+			require.NoError(t, err)
+			defer img.Close()
+
+			result, err := svc.Detect(ctx, collector, img)
+
+			require.NoError(t, err)
+			require.Len(t, result.Regions, 1)
+			ids := make([]cards.ID, 0)
+			for _, m := range result.Regions[0].Matches.Result {
+				if m.ID == artPrint || m.ID == otherPrint {
+					ids = append(ids, m.ID)
+				}
+			}
+			assert.Equal(t, tc.expected, ids)
+		})
+	}
+}
+
+// insertPrint inserts a print of the card "Rerank Card" with the image path, returns the id of the print and
+// the id of its image. The print is removed after the test.
+func insertPrint(t *testing.T, set string, path string) (cards.ID, int) {
+	t.Helper()
+
+	ctx := context.Background()
+	var id cards.ID
+	var imageID int
+	query := `INSERT INTO card(name, number, rarity, border, layout, card_set_code)
+VALUES ('Rerank Card', '99', 'COMMON', 'WHITE', 'NORMAL', $1) RETURNING id`
+	require.NoError(t, connection.Conn.QueryRow(ctx, query, set).Scan(&id.CardID))
+	query = `INSERT INTO card_face(card_id, name, converted_mana_cost) VALUES ($1, 'Rerank Card', 0) RETURNING id`
+	require.NoError(t, connection.Conn.QueryRow(ctx, query, id.CardID).Scan(&id.FaceID))
+	query = `INSERT INTO card_image(face_id, card_id, image_path, lang_lang, mime_type)
+VALUES ($1, $2, $3, 'eng', 'jpg') RETURNING id`
+	require.NoError(t, connection.Conn.QueryRow(ctx, query, id.FaceID, id.CardID, path).Scan(&imageID))
+	t.Cleanup(func() {
+		for _, q := range []string{
+			"DELETE FROM card_image WHERE card_id = $1",
+			"DELETE FROM card_face WHERE card_id = $1",
+			"DELETE FROM card WHERE id = $1",
+		} {
+			_, err := connection.Conn.Exec(ctx, q, id.CardID)
+			require.NoError(t, err)
+		}
+	})
+
+	return id, imageID
+}
@@ -0,0 +1,172 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+)
+
+var ErrInvalidHash = errors.New("hash must consist of 4 values and an algorithm")
+
+// hashParts the number of 64 bit columns of a card image hash.
+const hashParts = 4
+
+// missingHashCondition matches all images that have no hash for at least one of the algorithms in $1 or no
+// region hash with the algorithm $2 for at least one of the regions in $3.
+const missingHashCondition = `
+  ((SELECT count(*) FROM card_image_hash as hash
+    WHERE hash.card_image_id = image.id AND hash.algorithm = ANY($1)) < cardinality($1)
+  OR
+  (SELECT count(*) FROM card_image_region_hash as hash
+   WHERE hash.card_image_id = image.id AND hash.algorithm = $2 AND hash.region = ANY($3)) < cardinality($3))`
+
+type PostgresHashRepository struct {
+	db *DBConnection
+}
+
+func NewHashRepository(connection *DBConnection) *PostgresHashRepository {
+	return &PostgresHashRepository{
+		db: connection,
+	}
+}
+
+func (r *PostgresHashRepository) CountMissingHashes(
+	ctx context.Context, algs []cards.HashAlgorithm, rerank cards.RerankConfig,
+) (int, error) {
+	query := `SELECT count(*) FROM card_image as image WHERE` + missingHashCondition
+
+	var count int
+	args := []any{algorithmNames(algs), string(rerank.HashAlgorithm()), regionNames(rerank.Regions)}
+	if err := r.db.Conn.QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count missing hashes %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *PostgresHashRepository) MissingHashes(
+	ctx context.Context, algs []cards.HashAlgorithm, rerank cards.RerankConfig, afterID int, limit int,
+) ([]cards.CardImage, error) {
+	query := `
+SELECT
+  image.id, image.image_path
+FROM
+  card_image as image
+WHERE
+  image.id > $4 AND` + missingHashCondition + `
+ORDER BY
+  image.id
+LIMIT $5`
+	rows, err := r.db.Conn.Query(ctx, query,
+		algorithmNames(algs), string(rerank.HashAlgorithm()), regionNames(rerank.Regions), afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute missing hashes select %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]cards.CardImage, 0, limit)
+	for rows.Next() {
+		var img cards.CardImage
+		if err = rows.Scan(&img.ID, &img.Path); err != nil {
+			return nil, fmt.Errorf("failed to execute missing hashes result scan %w", err)
+		}
+		result = append(result, img)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("failed to read next missing hash row %w", rows.Err())
+	}
+
+	return result, nil
+}
+
+// UpdateHashes writes the image hashes and the region hashes with one statement each within one transaction,
+// existing hashes of the same algorithm and region are replaced.
+func (r *PostgresHashRepository) UpdateHashes(ctx context.Context, hashes []cards.ImageHash) error {
+	if len(hashes) == 0 {
+		return nil
+	}
+
+	var images, regions hashColumns
+	for _, h := range hashes {
+		if len(h.Hash.Value) != hashParts || h.Hash.Algorithm == "" {
+			return fmt.Errorf("invalid hash for image %d, %w", h.ImageID, ErrInvalidHash)
+		}
+		if h.Region == "" {
+			images.add(h)
+		} else {
+			regions.add(h)
+		}
+	}
+
+	return r.db.WithTransaction(ctx, func(tx *DBConnection) error {
+		if err := updateImageHashes(ctx, tx, images); err != nil {
+			return err
+		}
+
+		return updateRegionHashes(ctx, tx, regions)
+	})
+}
+
+// hashColumns the hashes as one array per column, used to write all hashes with a single statement.
+type hashColumns struct {
+	ids     []int
+	algs    []string
+	regions []string
+	parts   [hashParts][]string
+}
+
+func (c *hashColumns) add(h cards.ImageHash) {
+	c.ids = append(c.ids, h.ImageID)
+	c.algs = append(c.algs, string(h.Hash.Algorithm))
+	c.regions = append(c.regions, string(h.Region))
+	for i, v := range h.Hash.AsBase2() {
+		c.parts[i] = append(c.parts[i], v)
+	}
+}
+
+func updateImageHashes(ctx context.Context, tx *DBConnection, c hashColumns) error {
+	if len(c.ids) == 0 {
+		return nil
+	}
+
+	query := `
+INSERT INTO card_image_hash (card_image_id, algorithm, hash1, hash2, hash3, hash4)
+SELECT
+  v.id, v.algorithm,
+  CAST(v.hash1 as BIT(64)), CAST(v.hash2 as BIT(64)), CAST(v.hash3 as BIT(64)), CAST(v.hash4 as BIT(64))
+FROM
+  unnest($1::int[], $2::text[], $3::text[], $4::text[], $5::text[], $6::text[])
+    as v(id, algorithm, hash1, hash2, hash3, hash4)
+ON CONFLICT (card_image_id, algorithm) DO UPDATE SET
+  hash1 = EXCLUDED.hash1, hash2 = EXCLUDED.hash2, hash3 = EXCLUDED.hash3, hash4 = EXCLUDED.hash4, updated_at = now()`
+	if _, err := tx.Conn.Exec(ctx, query, c.ids, c.algs, c.parts[0], c.parts[1], c.parts[2], c.parts[3]); err != nil {
+		return fmt.Errorf("failed to update hashes %w", err)
+	}
+
+	return nil
+}
+
+func updateRegionHashes(ctx context.Context, tx *DBConnection, c hashColumns) error {
+	if len(c.ids) == 0 {
+		return nil
+	}
+
+	query := `
+INSERT INTO card_image_region_hash (card_image_id, region, algorithm, hash1, hash2, hash3, hash4)
+SELECT
+  v.id, v.region, v.algorithm,
+  CAST(v.hash1 as BIT(64)), CAST(v.hash2 as BIT(64)), CAST(v.hash3 as BIT(64)), CAST(v.hash4 as BIT(64))
+FROM
+  unnest($1::int[], $2::text[], $3::text[], $4::text[], $5::text[], $6::text[], $7::text[])
+    as v(id, region, algorithm, hash1, hash2, hash3, hash4)
+ON CONFLICT (card_image_id, region, algorithm) DO UPDATE SET
+  hash1 = EXCLUDED.hash1, hash2 = EXCLUDED.hash2, hash3 = EXCLUDED.hash3, hash4 = EXCLUDED.hash4`
+	_, err := tx.Conn.Exec(ctx, query, c.ids, c.regions, c.algs, c.parts[0], c.parts[1], c.parts[2], c.parts[3])
+	if err != nil {
+		return fmt.Errorf("failed to update region hashes %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMissingHashes(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	hashRepo := postgres.NewHashRepository(connection)
+	ctx := context.Background()
+	algs := []cards.HashAlgorithm{cards.PHash}
+
+	first, err := hashRepo.MissingHashes(ctx, algs, cards.RerankConfig{}, 0, 2)
+	require.NoError(t, err)
+	next, err := hashRepo.MissingHashes(ctx, algs, cards.RerankConfig{}, first[1].ID, 1)
+	require.NoError(t, err)
+
+	require.Len(t, first, 2)
+	assert.Equal(t, "images/dummyCard1.png", first[0].Path)
+	assert.Equal(t, "images/dummyCard2.png", first[1].Path)
+	require.Len(t, next, 1)
+	assert.Equal(t, "images/dummyCard3.png", next[0].Path)
+}
+
+func TestUpdateHashes(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	hashRepo := postgres.NewHashRepository(connection)
+	dCfg := cards.DetectConfig{Hashes: []cards.HashConfig{{Algorithm: cards.PHash}, {Algorithm: cards.DHash}}}
+	detectRepo := postgres.NewDetectRepository(connection, postgres.Images{}, dCfg)
+	ctx := context.Background()
+	algs := dCfg.Algorithms()
+	missing, err := hashRepo.MissingHashes(ctx, algs, cards.RerankConfig{}, 0, 1)
+	require.NoError(t, err)
+	require.Len(t, missing, 1)
+	before, err := hashRepo.CountMissingHashes(ctx, algs, cards.RerankConfig{})
+	require.NoError(t, err)
+	phash := cards.Hash{Algorithm: cards.PHash, Value: []uint64{11, 12, 13, 1 << 63}, Bits: 256}
+	dhash := cards.Hash{Algorithm: cards.DHash, Value: []uint64{21, 22, 23, 1 << 62}, Bits: 256}
+	t.Cleanup(func() {
+		_, err := connection.Conn.Exec(ctx, "DELETE FROM card_image_hash WHERE card_image_id = $1", missing[0].ID)
+		require.NoError(t, err)
+	})
+
+	err = hashRepo.UpdateHashes(ctx, []cards.ImageHash{{ImageID: missing[0].ID, Hash: phash}})
+	require.NoError(t, err)
+	stillMissing, err := hashRepo.CountMissingHashes(ctx, algs, cards.RerankConfig{})
+	require.NoError(t, err)
+	err = hashRepo.UpdateHashes(ctx, []cards.ImageHash{{ImageID: missing[0].ID, Hash: dhash}})
+
+	require.NoError(t, err)
+	assert.Equal(t, before, stillMissing)
+	after, err := hashRepo.CountMissingHashes(ctx, algs, cards.RerankConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, before-1, after)
+	require.NoError(t, detectRepo.Load(ctx))
+	result, err := detectRepo.Top5MatchesByHash(ctx, phash, dhash)
+	require.NoError(t, err)
+	require.NotEmpty(t, result)
+	assert.Equal(t, 0, result[0].Score)
+}
+
+func TestUpdateRegionHashes(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	hashRepo := postgres.NewHashRepository(connection)
+	rerank := cards.RerankConfig{Regions: []cards.CardRegion{cards.ArtRegion, cards.SymbolRegion}}
+	detectRepo := postgres.NewDetectRepository(connection, postgres.Images{}, cards.DetectConfig{Rerank: rerank})
+	ctx := context.Background()
+	algs := []cards.HashAlgorithm{cards.PHash}
+	var imageID int
+	query := `SELECT id FROM card_image WHERE image_path = 'images/card11Hash.png'`
+	require.NoError(t, connection.Conn.QueryRow(ctx, query).Scan(&imageID))
+	before, err := hashRepo.CountMissingHashes(ctx, algs, rerank)
+	require.NoError(t, err)
+	symbol := cards.Hash{Algorithm: cards.PHash, Value: []uint64{5, 6, 7, 8}, Bits: 256}
+	t.Cleanup(func() {
+		_, err := connection.Conn.Exec(ctx,
+			"DELETE FROM card_image_region_hash WHERE card_image_id = $1 AND region = 'symbol'", imageID)
+		require.NoError(t, err)
+	})
+
+	err = hashRepo.UpdateHashes(ctx, []cards.ImageHash{{ImageID: imageID, Hash: symbol, Region: cards.SymbolRegion}})
+
+	require.NoError(t, err)
+	after, err := hashRepo.CountMissingHashes(ctx, algs, rerank)
+	require.NoError(t, err)
+	assert.Equal(t, before-1, after)
+	result, err := detectRepo.RegionHashes(ctx, cards.PHash, cards.NewID(11))
+	require.NoError(t, err)
+	assert.Contains(t, result[cards.NewID(11)], cards.RegionHash{Region: cards.SymbolRegion, Hash: symbol})
+}
+
+func TestUpdateHashesInvalidHash(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	hashRepo := postgres.NewHashRepository(connection)
+
+	err := hashRepo.UpdateHashes(context.Background(), []cards.ImageHash{
+		{ImageID: 1, Hash: cards.Hash{Algorithm: cards.PHash, Value: []uint64{1}}},
+	})
+
+	require.ErrorIs(t, err, postgres.ErrInvalidHash)
+}
@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+)
+
+type PostgresImageRepository struct {
+	db *DBConnection
+}
+
+func NewImageRepository(connection *DBConnection) *PostgresImageRepository {
+	return &PostgresImageRepository{
+		db: connection,
+	}
+}
+
+func (r *PostgresImageRepository) Image(ctx context.Context, id int) (cards.CardImage, error) {
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+	query := `
+SELECT
+  image.id, image.image_path
+FROM
+  card_image AS image
+WHERE
+  image.id = @id`
+
+	var img cards.CardImage
+	if err := r.db.Conn.QueryRow(ctx, query, args).Scan(&img.ID, &img.Path); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return cards.CardImage{}, fmt.Errorf("%d, %w", id, cards.ErrImageNotFound)
+		}
+
+		return cards.CardImage{}, fmt.Errorf("find image failed during row scan %w", err)
+	}
+
+	return img, nil
+}
+
+// Images returns the card images with an id greater than afterID ordered by their id.
+func (r *PostgresImageRepository) Images(ctx context.Context, afterID int, limit int) ([]cards.CardImage, error) {
+	args := pgx.NamedArgs{
+		"afterID": afterID,
+		"limit":   limit,
+	}
+	query := `
+SELECT
+  image.id, image.image_path
+FROM
+  card_image AS image
+WHERE
+  image.id > @afterID
+ORDER BY
+  image.id
+LIMIT @limit`
+	rows, err := r.db.Conn.Query(ctx, query, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute card image select %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]cards.CardImage, 0, limit)
+	for rows.Next() {
+		var img cards.CardImage
+		if err := rows.Scan(&img.ID, &img.Path); err != nil {
+			return nil, fmt.Errorf("failed to execute card image scan after select %w", err)
+		}
+		result = append(result, img)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("failed to read next row %w", rows.Err())
+	}
+
+	return result, nil
+}
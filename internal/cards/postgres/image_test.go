@@ -0,0 +1,52 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	imageRepo := postgres.NewImageRepository(connection)
+
+	img, err := imageRepo.Image(context.Background(), 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, cards.CardImage{ID: 1, Path: "images/dummyCard1.png"}, img)
+}
+
+func TestImageNotFound(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	imageRepo := postgres.NewImageRepository(connection)
+
+	_, err := imageRepo.Image(context.Background(), 99999)
+
+	require.ErrorIs(t, err, cards.ErrImageNotFound)
+}
+
+func TestImages(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	imageRepo := postgres.NewImageRepository(connection)
+	ctx := context.Background()
+
+	first, err := imageRepo.Images(ctx, 0, 2)
+	require.NoError(t, err)
+	next, err := imageRepo.Images(ctx, first[1].ID, 1)
+	require.NoError(t, err)
+
+	require.Len(t, first, 2)
+	assert.Equal(t, cards.CardImage{ID: 1, Path: "images/dummyCard1.png"}, first[0])
+	require.Len(t, next, 1)
+	assert.Equal(t, "images/dummyCard3.png", next[0].Path)
+}
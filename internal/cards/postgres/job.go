@@ -0,0 +1,128 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/konstantinfoerster/card-service-go/internal/jobs"
+)
+
+type PostgresJobRepository struct {
+	db *DBConnection
+}
+
+func NewJobRepository(connection *DBConnection) *PostgresJobRepository {
+	return &PostgresJobRepository{
+		db: connection,
+	}
+}
+
+func (r *PostgresJobRepository) Save(ctx context.Context, job jobs.Job) error {
+	var result *string
+	if job.Result != nil {
+		v := string(job.Result)
+		result = &v
+	}
+	args := pgx.NamedArgs{
+		"id":        job.ID,
+		"owner":     job.Owner,
+		"status":    string(job.Status),
+		"result":    result,
+		"errorKey":  job.ErrorKey,
+		"errorMsg":  job.ErrorMsg,
+		"createdAt": job.CreatedAt,
+		"updatedAt": job.UpdatedAt,
+	}
+	query := `
+INSERT INTO
+  job (id, owner, status, result, error_key, error_msg, created_at, updated_at)
+VALUES
+  (@id, @owner, @status, @result::jsonb, @errorKey, @errorMsg, @createdAt, @updatedAt)
+ON CONFLICT
+  (id)
+DO UPDATE SET
+  status = EXCLUDED.status,
+  result = EXCLUDED.result,
+  error_key = EXCLUDED.error_key,
+  error_msg = EXCLUDED.error_msg,
+  updated_at = EXCLUDED.updated_at`
+	if _, err := r.db.Conn.Exec(ctx, query, args); err != nil {
+		return fmt.Errorf("save job failed due to exec error %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresJobRepository) Find(ctx context.Context, id string) (jobs.Job, error) {
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+	query := `
+SELECT
+  id, owner, status, result::text, error_key, error_msg, created_at, updated_at
+FROM
+  job
+WHERE
+  id = @id`
+
+	var job jobs.Job
+	var status string
+	var result *string
+	err := r.db.Conn.QueryRow(ctx, query, args).Scan(
+		&job.ID, &job.Owner, &status, &result, &job.ErrorKey, &job.ErrorMsg, &job.CreatedAt, &job.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return jobs.Job{}, fmt.Errorf("%s, %w", id, jobs.ErrJobNotFound)
+		}
+
+		return jobs.Job{}, fmt.Errorf("find job failed during row scan %w", err)
+	}
+	job.Status = jobs.Status(status)
+	if result != nil {
+		job.Result = []byte(*result)
+	}
+
+	return job, nil
+}
+
+func (r *PostgresJobRepository) DeleteBefore(ctx context.Context, before time.Time) error {
+	args := pgx.NamedArgs{
+		"before": before,
+	}
+	query := `DELETE FROM job WHERE updated_at < @before`
+	if _, err := r.db.Conn.Exec(ctx, query, args); err != nil {
+		return fmt.Errorf("delete jobs failed due to exec error %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresJobRepository) FailUnfinished(
+	ctx context.Context, before time.Time, at time.Time, errorKey string,
+) (int, error) {
+	args := pgx.NamedArgs{
+		"before":   before,
+		"at":       at,
+		"errorKey": errorKey,
+		"failed":   string(jobs.StatusFailed),
+		"pending":  string(jobs.StatusPending),
+		"running":  string(jobs.StatusRunning),
+	}
+	query := `
+UPDATE
+  job
+SET
+  status = @failed, error_key = @errorKey, updated_at = @at
+WHERE
+  status IN (@pending, @running) AND updated_at < @before`
+	tag, err := r.db.Conn.Exec(ctx, query, args)
+	if err != nil {
+		return 0, fmt.Errorf("fail unfinished jobs failed due to exec error %w", err)
+	}
+
+	return int(tag.RowsAffected()), nil
+}
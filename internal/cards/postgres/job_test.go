@@ -0,0 +1,84 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/konstantinfoerster/card-service-go/internal/cards/postgres"
+	"github.com/konstantinfoerster/card-service-go/internal/jobs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	repo := postgres.NewJobRepository(connection)
+	now := time.Now().UTC().Truncate(time.Second)
+	job := jobs.Job{
+		ID:        "6f1b1f0e-0000-4000-8000-000000000010",
+		Owner:     "jobUser",
+		Status:    jobs.StatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	require.NoError(t, repo.Save(ctx, job))
+	found, err := repo.Find(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, jobs.StatusPending, found.Status)
+	assert.Nil(t, found.Result)
+
+	job.Status = jobs.StatusDone
+	job.Result = []byte(`{"regions":[]}`)
+	job.UpdatedAt = now.Add(time.Minute)
+	require.NoError(t, repo.Save(ctx, job))
+	found, err = repo.Find(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, jobs.StatusDone, found.Status)
+	assert.Equal(t, "jobUser", found.Owner)
+	assert.JSONEq(t, `{"regions":[]}`, string(found.Result))
+	assert.True(t, job.UpdatedAt.Equal(found.UpdatedAt))
+
+	require.NoError(t, repo.DeleteBefore(ctx, now.Add(2*time.Minute)))
+	_, err = repo.Find(ctx, job.ID)
+	require.ErrorIs(t, err, jobs.ErrJobNotFound)
+}
+
+func TestJobRepositoryFailUnfinished(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	repo := postgres.NewJobRepository(connection)
+	now := time.Now().UTC().Truncate(time.Second)
+	old := now.Add(-time.Hour)
+	saved := []jobs.Job{
+		{ID: "6f1b1f0e-0000-4000-8000-000000000020", Status: jobs.StatusPending, CreatedAt: old, UpdatedAt: old},
+		{ID: "6f1b1f0e-0000-4000-8000-000000000021", Status: jobs.StatusRunning, CreatedAt: old, UpdatedAt: old},
+		{ID: "6f1b1f0e-0000-4000-8000-000000000022", Status: jobs.StatusDone, CreatedAt: old, UpdatedAt: old},
+		{ID: "6f1b1f0e-0000-4000-8000-000000000023", Status: jobs.StatusRunning, CreatedAt: now, UpdatedAt: now},
+	}
+	for _, j := range saved {
+		require.NoError(t, repo.Save(ctx, j))
+	}
+	t.Cleanup(func() {
+		require.NoError(t, repo.DeleteBefore(ctx, now.Add(time.Hour)))
+	})
+
+	failed, err := repo.FailUnfinished(ctx, now.Add(-time.Minute), now, "interrupted")
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, failed)
+	expected := []jobs.Status{jobs.StatusFailed, jobs.StatusFailed, jobs.StatusDone, jobs.StatusRunning}
+	for i, j := range saved {
+		found, err := repo.Find(ctx, j.ID)
+		require.NoError(t, err)
+		assert.Equal(t, expected[i], found.Status, j.ID)
+	}
+}
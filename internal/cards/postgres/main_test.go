@@ -34,6 +34,9 @@ func TestMain(m *testing.M) {
 		if err != nil {
 			panic(err)
 		}
+		if err = migrateWithData(ctx); err != nil {
+			panic(err)
+		}
 	}
 
 	code := m.Run()
@@ -45,6 +48,30 @@ func TestMain(m *testing.M) {
 	os.Exit(code)
 }
 
+// migrateWithData creates the schema with the migrations of the service and inserts the test data.
+func migrateWithData(ctx context.Context) error {
+	if _, err := postgres.NewMigrator(connection).Up(ctx); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(filepath.Join(testdataDir(), "db", "03-data.sql"))
+	if err != nil {
+		return err
+	}
+	_, err = connection.Conn.Exec(ctx, string(data))
+
+	return err
+}
+
+func testdataDir() string {
+	_, file, _, ok := runtime.Caller(0)
+	if !ok {
+		panic("failed to get current dir")
+	}
+
+	return filepath.Join(filepath.Dir(file), "testdata")
+}
+
 type logConsumer struct{}
 
 func (lc *logConsumer) Accept(l testcontainers.Log) {
@@ -62,12 +89,7 @@ func newRunner() *databaseRunner {
 }
 
 func (r *databaseRunner) Start(ctx context.Context) error {
-	_, file, _, ok := runtime.Caller(0)
-	if !ok {
-		panic("failed to get current dir")
-	}
-
-	dbDir, err := filepath.EvalSymlinks(filepath.Join(filepath.Dir(file), "testdata", "db"))
+	dbDir, err := filepath.EvalSymlinks(filepath.Join(testdataDir(), "db"))
 	if err != nil {
 		return err
 	}
@@ -87,16 +109,6 @@ func (r *databaseRunner) Start(ctx context.Context) error {
 				ContainerFilePath: "/docker-entrypoint-initdb.d/01-init.sh",
 				FileMode:          initScriptDirPermissions,
 			},
-			{
-				HostFilePath:      filepath.Join(dbDir, "02-create-tables.sql"),
-				ContainerFilePath: "/docker-entrypoint-initdb.d/02-create-tables.sql",
-				FileMode:          initScriptDirPermissions,
-			},
-			{
-				HostFilePath:      filepath.Join(dbDir, "03-data.sql"),
-				ContainerFilePath: "/docker-entrypoint-initdb.d/03-data.sql",
-				FileMode:          initScriptDirPermissions,
-			},
 		},
 		Env: map[string]string{
 			"POSTGRES_DB":       "postgres",
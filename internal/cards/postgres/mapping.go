@@ -7,24 +7,25 @@ import (
 )
 
 type dbCard struct {
-	Row      sql.NullInt64
-	Name     string
-	Number   string
-	SetName  string
-	SetCode  string
-	ImageURL sql.NullString
-	CardID   int
-	FaceID   int
-	Amount   int
+	Row       sql.NullInt64
+	Name      string
+	Number    string
+	SetName   string
+	SetCode   string
+	ImagePath sql.NullString
+	ImageID   sql.NullInt64
+	CardID    int
+	FaceID    int
+	Amount    int
 }
 
-func toCard(dbCard dbCard) cards.Card {
+func toCard(dbCard dbCard, images Images) cards.Card {
 	return cards.Card{
 		ID:     cards.NewID(dbCard.CardID).WithFace(dbCard.FaceID),
 		Name:   dbCard.Name,
 		Number: dbCard.Number,
 		Image: cards.Image{
-			URL: dbCard.ImageURL.String,
+			URL: images.URL(int(dbCard.ImageID.Int64), dbCard.ImagePath.String),
 		},
 		Amount: dbCard.Amount,
 		Set: cards.Set{
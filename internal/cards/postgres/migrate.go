@@ -0,0 +1,290 @@
+package postgres
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+var ErrInvalidMigration = errors.New("invalid migration")
+
+// migrationLock the key of the advisory lock, only one instance migrates the database at the same time.
+const migrationLock = 7_310_001
+
+// Migration a versioned change of the database schema, the files are named <version>_<name>.<up|down>.sql.
+type Migration struct {
+	Version int
+	Name    string
+	up      string
+	down    string
+}
+
+// MigrationStatus the migration and the time it was applied, the time is zero if the migration is pending.
+type MigrationStatus struct {
+	Migration
+	AppliedAt time.Time
+}
+
+func (s MigrationStatus) Applied() bool {
+	return !s.AppliedAt.IsZero()
+}
+
+// Migrations returns all embedded migrations ordered by their version.
+func Migrations() ([]Migration, error) {
+	return readMigrations(migrationFiles, "migrations")
+}
+
+func readMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, e := range entries {
+		base, direction, ok := strings.Cut(strings.TrimSuffix(e.Name(), ".sql"), ".")
+		if !ok || (direction != "up" && direction != "down") {
+			return nil, fmt.Errorf("%w, unexpected file %s", ErrInvalidMigration, e.Name())
+		}
+		v, name, _ := strings.Cut(base, "_")
+		version, err := strconv.Atoi(v)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("%w, invalid version of %s", ErrInvalidMigration, e.Name())
+		}
+		content, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s %w", e.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+		if m.Name != name {
+			return nil, fmt.Errorf("%w, version %d used by %s and %s", ErrInvalidMigration, version, m.Name, name)
+		}
+		if direction == "up" {
+			m.up = string(content)
+		} else {
+			m.down = string(content)
+		}
+	}
+
+	result := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.up == "" || m.down == "" {
+			return nil, fmt.Errorf("%w, version %d needs an up and a down file", ErrInvalidMigration, m.Version)
+		}
+		result = append(result, *m)
+	}
+	slices.SortFunc(result, func(a, b Migration) int { return a.Version - b.Version })
+
+	return result, nil
+}
+
+// Migrator applies the embedded migrations, the applied versions are stored in the table schema_migration.
+type Migrator struct {
+	db *DBConnection
+}
+
+func NewMigrator(connection *DBConnection) *Migrator {
+	return &Migrator{
+		db: connection,
+	}
+}
+
+// Up applies all pending migrations and returns the number of applied migrations. Every migration runs in its
+// own transaction.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	for _, mig := range migrations {
+		done := false
+		err := m.locked(ctx, func(tx *DBConnection) error {
+			versions, err := appliedVersions(ctx, tx)
+			if err != nil {
+				return err
+			}
+			if _, ok := versions[mig.Version]; ok {
+				return nil
+			}
+
+			existing, err := existingSchema(ctx, tx, mig, versions)
+			if err != nil {
+				return err
+			}
+			if existing {
+				slog.Info("existing schema kept as migration", slog.Int("version", mig.Version))
+			} else if _, err := tx.Conn.Exec(ctx, mig.up); err != nil {
+				return fmt.Errorf("failed to apply migration %d %w", mig.Version, err)
+			}
+			query := `INSERT INTO schema_migration (version, name, applied_at) VALUES ($1, $2, now())`
+			if _, err := tx.Conn.Exec(ctx, query, mig.Version, mig.Name); err != nil {
+				return fmt.Errorf("failed to store migration %d %w", mig.Version, err)
+			}
+			done = true
+
+			return nil
+		})
+		if err != nil {
+			return applied, err
+		}
+		if done {
+			applied++
+			slog.Info("migration applied", slog.Int("version", mig.Version), slog.String("name", mig.Name))
+		}
+	}
+
+	return applied, nil
+}
+
+// Down reverts the given number of applied migrations, starting with the latest one. Returns the number of
+// reverted migrations.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return 0, err
+	}
+
+	reverted := 0
+	for reverted < steps {
+		var mig Migration
+		err := m.locked(ctx, func(tx *DBConnection) error {
+			var version int
+			err := tx.Conn.QueryRow(ctx, `SELECT version FROM schema_migration ORDER BY version DESC LIMIT 1`).
+				Scan(&version)
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to find latest migration %w", err)
+			}
+
+			i := slices.IndexFunc(migrations, func(m Migration) bool { return m.Version == version })
+			if i < 0 {
+				return fmt.Errorf("%w, unknown applied version %d", ErrInvalidMigration, version)
+			}
+			if _, err := tx.Conn.Exec(ctx, migrations[i].down); err != nil {
+				return fmt.Errorf("failed to revert migration %d %w", version, err)
+			}
+			if _, err := tx.Conn.Exec(ctx, `DELETE FROM schema_migration WHERE version = $1`, version); err != nil {
+				return fmt.Errorf("failed to remove migration %d %w", version, err)
+			}
+			mig = migrations[i]
+
+			return nil
+		})
+		if err != nil {
+			return reverted, err
+		}
+		if mig.Version == 0 {
+			break
+		}
+		reverted++
+		slog.Info("migration reverted", slog.Int("version", mig.Version), slog.String("name", mig.Name))
+	}
+
+	return reverted, nil
+}
+
+// Status returns all migrations together with the time they were applied.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var versions map[int]time.Time
+	err = m.locked(ctx, func(tx *DBConnection) error {
+		versions, err = appliedVersions(ctx, tx)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]MigrationStatus, 0, len(migrations))
+	for _, mig := range migrations {
+		result = append(result, MigrationStatus{Migration: mig, AppliedAt: versions[mig.Version]})
+	}
+
+	return result, nil
+}
+
+// locked runs f in a transaction that holds the migration lock, the migration table is created if missing.
+func (m *Migrator) locked(ctx context.Context, f func(tx *DBConnection) error) error {
+	return m.db.WithTransaction(ctx, func(tx *DBConnection) error {
+		if _, err := tx.Conn.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, migrationLock); err != nil {
+			return fmt.Errorf("failed to lock migrations %w", err)
+		}
+		query := `
+CREATE TABLE IF NOT EXISTS schema_migration
+(
+    version    INTEGER PRIMARY KEY NOT NULL,
+    name       VARCHAR(255)        NOT NULL,
+    applied_at TIMESTAMPTZ         NOT NULL
+)`
+		if _, err := tx.Conn.Exec(ctx, query); err != nil {
+			return fmt.Errorf("failed to create migration table %w", err)
+		}
+
+		return f(tx)
+	})
+}
+
+// existingSchema returns true if the database was created before the migrations existed. The first migration is
+// the schema of such a database, it is only stored as applied.
+func existingSchema(ctx context.Context, tx *DBConnection, mig Migration, versions map[int]time.Time) (bool, error) {
+	if mig.Version != 1 || len(versions) > 0 {
+		return false, nil
+	}
+
+	var exists bool
+	if err := tx.Conn.QueryRow(ctx, `SELECT to_regclass('card_image') IS NOT NULL`).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check existing schema %w", err)
+	}
+
+	return exists, nil
+}
+
+func appliedVersions(ctx context.Context, tx *DBConnection) (map[int]time.Time, error) {
+	rows, err := tx.Conn.Query(ctx, `SELECT version, applied_at FROM schema_migration`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query migrations %w", err)
+	}
+	defer rows.Close()
+
+	versions := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to read migration %w", err)
+		}
+		versions[version] = appliedAt
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read migrations %w", err)
+	}
+
+	return versions, nil
+}
@@ -0,0 +1,63 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/konstantinfoerster/card-service-go/internal/cards/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrations(t *testing.T) {
+	migrations, err := postgres.Migrations()
+
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+	for i, m := range migrations {
+		assert.Equal(t, i+1, m.Version)
+		assert.NotEmpty(t, m.Name)
+	}
+}
+
+func TestMigrationStatus(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	migrator := postgres.NewMigrator(connection)
+
+	status, err := migrator.Status(context.Background())
+
+	require.NoError(t, err)
+	migrations, err := postgres.Migrations()
+	require.NoError(t, err)
+	require.Len(t, status, len(migrations))
+	for _, s := range status {
+		assert.True(t, s.Applied(), "version %d", s.Version)
+	}
+}
+
+func TestMigrateDownAndUp(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	migrator := postgres.NewMigrator(connection)
+	ctx := context.Background()
+	migrations, err := postgres.Migrations()
+	require.NoError(t, err)
+	latest := migrations[len(migrations)-1]
+
+	reverted, err := migrator.Down(ctx, 1)
+	require.NoError(t, err)
+	status, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	applied, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	again, err := migrator.Up(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, reverted)
+	assert.False(t, status[len(status)-1].Applied(), "version %d", latest.Version)
+	assert.Equal(t, 1, applied)
+	assert.Equal(t, 0, again)
+}
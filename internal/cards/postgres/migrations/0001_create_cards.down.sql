@@ -0,0 +1,23 @@
+DROP TABLE card_collection;
+DROP TABLE card_image;
+DROP TABLE face_card_type;
+DROP TABLE face_sub_type;
+DROP TABLE face_super_type;
+DROP TABLE card_translation;
+DROP TABLE card_face;
+DROP TABLE card;
+DROP TABLE card_set_translation;
+DROP TABLE card_set;
+DROP TABLE card_block_translation;
+DROP TABLE card_block;
+DROP TABLE card_type_translation;
+DROP TABLE card_type;
+DROP TABLE super_type_translation;
+DROP TABLE super_type;
+DROP TABLE sub_type_translation;
+DROP TABLE sub_type;
+DROP TYPE rarity;
+DROP TYPE layout;
+DROP TYPE card_set_type;
+DROP TYPE border;
+DROP TABLE lang;
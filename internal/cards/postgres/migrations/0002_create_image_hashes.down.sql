@@ -0,0 +1,19 @@
+ALTER TABLE card_image
+    ADD COLUMN phash1 BIT(64),
+    ADD COLUMN phash2 BIT(64),
+    ADD COLUMN phash3 BIT(64),
+    ADD COLUMN phash4 BIT(64);
+
+UPDATE card_image
+SET phash1 = hash.hash1,
+    phash2 = hash.hash2,
+    phash3 = hash.hash3,
+    phash4 = hash.hash4
+FROM card_image_hash AS hash
+WHERE hash.card_image_id = card_image.id
+  AND hash.algorithm = 'phash';
+
+CREATE INDEX idx_card_image_hashes on card_image (phash1, phash2, phash3, phash4);
+
+DROP TABLE card_image_region_hash;
+DROP TABLE card_image_hash;
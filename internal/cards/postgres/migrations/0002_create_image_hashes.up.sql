@@ -0,0 +1,40 @@
+CREATE TABLE card_image_hash
+(
+    card_image_id INTEGER     NOT NULL REFERENCES card_image (id) ON DELETE CASCADE,
+    algorithm     VARCHAR(20) NOT NULL CHECK ( algorithm <> '' ),
+    hash1         BIT(64)     NOT NULL,
+    hash2         BIT(64)     NOT NULL,
+    hash3         BIT(64)     NOT NULL,
+    hash4         BIT(64)     NOT NULL,
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (card_image_id, algorithm)
+);
+
+CREATE TABLE card_image_region_hash
+(
+    card_image_id INTEGER     NOT NULL REFERENCES card_image (id) ON DELETE CASCADE,
+    region        VARCHAR(20) NOT NULL CHECK ( region <> '' ),
+    algorithm     VARCHAR(20) NOT NULL CHECK ( algorithm <> '' ),
+    hash1         BIT(64)     NOT NULL,
+    hash2         BIT(64)     NOT NULL,
+    hash3         BIT(64)     NOT NULL,
+    hash4         BIT(64)     NOT NULL,
+    PRIMARY KEY (card_image_id, region, algorithm)
+);
+
+-- The pHash values of the former card_image columns are kept as hashes of the algorithm phash.
+INSERT INTO card_image_hash (card_image_id, algorithm, hash1, hash2, hash3, hash4)
+SELECT id, 'phash', phash1, phash2, phash3, phash4
+FROM card_image
+WHERE phash1 IS NOT NULL
+  AND phash2 IS NOT NULL
+  AND phash3 IS NOT NULL
+  AND phash4 IS NOT NULL;
+
+DROP INDEX idx_card_image_hashes;
+
+ALTER TABLE card_image
+    DROP COLUMN phash1,
+    DROP COLUMN phash2,
+    DROP COLUMN phash3,
+    DROP COLUMN phash4;
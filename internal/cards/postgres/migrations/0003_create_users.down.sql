@@ -0,0 +1,5 @@
+DROP TABLE user_identity;
+DROP TABLE users;
+DROP TABLE local_account;
+DROP TABLE user_role;
+DROP TABLE personal_access_token;
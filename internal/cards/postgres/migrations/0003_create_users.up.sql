@@ -0,0 +1,50 @@
+CREATE TABLE personal_access_token
+(
+    id         VARCHAR(36)  PRIMARY KEY NOT NULL,
+    user_id    VARCHAR(100) NOT NULL CHECK (user_id <> ''),
+    email      VARCHAR(255) NOT NULL DEFAULT '',
+    name       VARCHAR(100) NOT NULL CHECK (name <> ''),
+    token_hash CHAR(64)     NOT NULL UNIQUE,
+    scopes     VARCHAR(100) NOT NULL, -- List of Strings with ',' as separator
+    created_at TIMESTAMPTZ  NOT NULL,
+    expires_at TIMESTAMPTZ  NOT NULL,
+    UNIQUE (user_id, name)
+);
+
+CREATE TABLE user_role
+(
+    id      INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id VARCHAR(100) NOT NULL CHECK (user_id <> ''),
+    role    VARCHAR(20)  NOT NULL CHECK (role IN ('user', 'admin')),
+    UNIQUE (user_id, role)
+);
+
+CREATE TABLE local_account
+(
+    id            VARCHAR(36)  PRIMARY KEY NOT NULL,
+    username      VARCHAR(50)  NOT NULL UNIQUE CHECK (username <> ''),
+    password_hash VARCHAR(255) NOT NULL,
+    created_at    TIMESTAMPTZ  NOT NULL
+);
+
+CREATE TABLE users
+(
+    id           VARCHAR(100) PRIMARY KEY NOT NULL CHECK (id <> ''),
+    provider     VARCHAR(50)  NOT NULL CHECK (provider <> ''),
+    subject      VARCHAR(100) NOT NULL CHECK (subject <> ''),
+    email        VARCHAR(255) NOT NULL DEFAULT '',
+    display_name VARCHAR(100) NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ  NOT NULL,
+    updated_at   TIMESTAMPTZ  NOT NULL,
+    UNIQUE (provider, subject)
+);
+
+CREATE TABLE user_identity
+(
+    provider VARCHAR(50)  NOT NULL CHECK (provider <> ''),
+    subject  VARCHAR(100) NOT NULL CHECK (subject <> ''),
+    user_id  VARCHAR(100) NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    PRIMARY KEY (provider, subject)
+);
+
+CREATE INDEX idx_user_identity_user_id ON user_identity (user_id);
@@ -0,0 +1,13 @@
+CREATE TABLE job
+(
+    id         VARCHAR(36)  PRIMARY KEY NOT NULL,
+    owner      VARCHAR(100) NOT NULL DEFAULT '',
+    status     VARCHAR(20)  NOT NULL CHECK (status IN ('pending', 'running', 'done', 'failed', 'canceled')),
+    result     JSONB,
+    error_key  VARCHAR(100) NOT NULL DEFAULT '',
+    error_msg  TEXT         NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ  NOT NULL,
+    updated_at TIMESTAMPTZ  NOT NULL
+);
+
+CREATE INDEX idx_job_updated_at ON job (updated_at);
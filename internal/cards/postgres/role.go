@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/konstantinfoerster/card-service-go/internal/auth"
+)
+
+type PostgresRoleRepository struct {
+	db *DBConnection
+}
+
+func NewRoleRepository(connection *DBConnection) *PostgresRoleRepository {
+	return &PostgresRoleRepository{
+		db: connection,
+	}
+}
+
+func (r *PostgresRoleRepository) Roles(ctx context.Context, userID string) ([]auth.Role, error) {
+	args := pgx.NamedArgs{
+		"userID": userID,
+	}
+	query := `
+SELECT
+  role
+FROM
+  user_role
+WHERE
+  user_id = @userID
+ORDER BY
+  role`
+	rows, err := r.db.Conn.Query(ctx, query, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute role select %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]auth.Role, 0)
+	for rows.Next() {
+		var role string
+		if err := rows.Scan(&role); err != nil {
+			return nil, fmt.Errorf("failed to execute role scan after select %w", err)
+		}
+		result = append(result, auth.Role(role))
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("failed to read next row %w", rows.Err())
+	}
+
+	return result, nil
+}
+
+func (r *PostgresRoleRepository) Assign(ctx context.Context, userID string, role auth.Role) error {
+	args := pgx.NamedArgs{
+		"userID": userID,
+		"role":   string(role),
+	}
+	query := `
+INSERT INTO
+  user_role (user_id, role)
+VALUES
+  (@userID, @role)
+ON CONFLICT
+  (user_id, role)
+DO NOTHING`
+	if _, err := r.db.Conn.Exec(ctx, query, args); err != nil {
+		return fmt.Errorf("assign role failed due to exec error %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRoleRepository) Revoke(ctx context.Context, userID string, role auth.Role) error {
+	args := pgx.NamedArgs{
+		"userID": userID,
+		"role":   string(role),
+	}
+	query := `
+DELETE FROM
+  user_role
+WHERE
+  user_id = @userID AND role = @role`
+	if _, err := r.db.Conn.Exec(ctx, query, args); err != nil {
+		return fmt.Errorf("revoke role failed due to exec error %w", err)
+	}
+
+	return nil
+}
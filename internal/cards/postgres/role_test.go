@@ -0,0 +1,38 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/konstantinfoerster/card-service-go/internal/auth"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoleRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	repo := postgres.NewRoleRepository(connection)
+
+	roles, err := repo.Roles(ctx, "roleUser")
+	require.NoError(t, err)
+	assert.Empty(t, roles)
+
+	require.NoError(t, repo.Assign(ctx, "roleUser", auth.RoleAdmin))
+	require.NoError(t, repo.Assign(ctx, "roleUser", auth.RoleAdmin))
+
+	roles, err = repo.Roles(ctx, "roleUser")
+	require.NoError(t, err)
+	assert.Equal(t, []auth.Role{auth.RoleAdmin}, roles)
+
+	require.NoError(t, repo.Revoke(ctx, "roleUser", auth.RoleAdmin))
+	require.NoError(t, repo.Revoke(ctx, "roleUser", auth.RoleAdmin))
+
+	roles, err = repo.Roles(ctx, "roleUser")
+	require.NoError(t, err)
+	assert.Empty(t, roles)
+}
@@ -1,7 +1,7 @@
 #!/bin/bash
 set -e
 
-export PGPASSWORD=$APP_DB_PASS;
+# the schema is created by the migrations of the service
 psql -v ON_ERROR_STOP=1 --username "$POSTGRES_USER" <<-EOSQL
   CREATE USER $APP_DB_USER WITH PASSWORD '$APP_DB_PASS';
   CREATE DATABASE $APP_DB_NAME;
@@ -9,6 +9,3 @@ psql -v ON_ERROR_STOP=1 --username "$POSTGRES_USER" <<-EOSQL
   \c $APP_DB_NAME
   GRANT ALL ON SCHEMA public TO $APP_DB_USER;
 EOSQL
-
-psql --username "$APP_DB_USER" --dbname "$APP_DB_NAME" -f  /docker-entrypoint-initdb.d/02-create-tables.sql
-psql --username "$APP_DB_USER" --dbname "$APP_DB_NAME" -f  /docker-entrypoint-initdb.d/03-data.sql
@@ -0,0 +1,173 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/konstantinfoerster/card-service-go/internal/auth"
+)
+
+type PostgresTokenRepository struct {
+	db *DBConnection
+}
+
+func NewTokenRepository(connection *DBConnection) *PostgresTokenRepository {
+	return &PostgresTokenRepository{
+		db: connection,
+	}
+}
+
+func (r *PostgresTokenRepository) Save(ctx context.Context, token auth.PersonalToken) error {
+	args := pgx.NamedArgs{
+		"id":        token.ID,
+		"userID":    token.UserID,
+		"email":     token.Email,
+		"name":      token.Name,
+		"hash":      token.Hash,
+		"scopes":    joinScopes(token.Scopes),
+		"createdAt": token.CreatedAt,
+		"expiresAt": token.ExpiresAt,
+	}
+	query := `
+INSERT INTO
+  personal_access_token (id, user_id, email, name, token_hash, scopes, created_at, expires_at)
+VALUES
+  (@id, @userID, @email, @name, @hash, @scopes, @createdAt, @expiresAt)`
+	if _, err := r.db.Conn.Exec(ctx, query, args); err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("token %s, %w", token.Name, auth.ErrTokenExists)
+		}
+
+		return fmt.Errorf("save token failed due to exec error %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresTokenRepository) FindByHash(ctx context.Context, hash string) (auth.PersonalToken, error) {
+	args := pgx.NamedArgs{
+		"hash": hash,
+	}
+	query := `
+SELECT
+  id, user_id, email, name, token_hash, scopes, created_at, expires_at
+FROM
+  personal_access_token
+WHERE
+  token_hash = @hash`
+	row := r.db.Conn.QueryRow(ctx, query, args)
+
+	token, err := scanToken(row)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return auth.PersonalToken{}, auth.ErrTokenNotFound
+		}
+
+		return auth.PersonalToken{}, fmt.Errorf("find token failed during row scan %w", err)
+	}
+
+	return token, nil
+}
+
+func (r *PostgresTokenRepository) List(ctx context.Context, userID string) ([]auth.PersonalToken, error) {
+	args := pgx.NamedArgs{
+		"userID": userID,
+	}
+	query := `
+SELECT
+  id, user_id, email, name, token_hash, scopes, created_at, expires_at
+FROM
+  personal_access_token
+WHERE
+  user_id = @userID
+ORDER BY
+  created_at, name`
+	rows, err := r.db.Conn.Query(ctx, query, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute token select %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]auth.PersonalToken, 0)
+	for rows.Next() {
+		token, err := scanToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute token scan after select %w", err)
+		}
+		result = append(result, token)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("failed to read next row %w", rows.Err())
+	}
+
+	return result, nil
+}
+
+func (r *PostgresTokenRepository) Delete(ctx context.Context, userID string, id string) error {
+	args := pgx.NamedArgs{
+		"id":     id,
+		"userID": userID,
+	}
+	query := `
+DELETE FROM
+  personal_access_token
+WHERE
+  id = @id
+AND
+  user_id = @userID`
+	tag, err := r.db.Conn.Exec(ctx, query, args)
+	if err != nil {
+		return fmt.Errorf("delete token failed due to exec error %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return auth.ErrTokenNotFound
+	}
+
+	return nil
+}
+
+func scanToken(row pgx.Row) (auth.PersonalToken, error) {
+	var token auth.PersonalToken
+	var scopes string
+	err := row.Scan(
+		&token.ID,
+		&token.UserID,
+		&token.Email,
+		&token.Name,
+		&token.Hash,
+		&scopes,
+		&token.CreatedAt,
+		&token.ExpiresAt,
+	)
+	if err != nil {
+		return auth.PersonalToken{}, err
+	}
+
+	token.Scopes = splitScopes(scopes)
+
+	return token, nil
+}
+
+func joinScopes(scopes []auth.Scope) string {
+	values := make([]string, 0, len(scopes))
+	for _, s := range scopes {
+		values = append(values, string(s))
+	}
+
+	return strings.Join(values, ",")
+}
+
+func splitScopes(value string) []auth.Scope {
+	scopes := make([]auth.Scope, 0)
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			scopes = append(scopes, auth.Scope(v))
+		}
+	}
+
+	return scopes
+}
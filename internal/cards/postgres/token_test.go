@@ -0,0 +1,53 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/konstantinfoerster/card-service-go/internal/auth"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	repo := postgres.NewTokenRepository(connection)
+	now := time.Now().UTC().Truncate(time.Second)
+	token := auth.PersonalToken{
+		ID:        "6f1b1f0e-0000-4000-8000-000000000001",
+		UserID:    "tokenUser",
+		Email:     "token@localhost",
+		Name:      "script",
+		Hash:      "0000000000000000000000000000000000000000000000000000000000000001",
+		Scopes:    []auth.Scope{auth.ScopeRead, auth.ScopeWrite},
+		CreatedAt: now,
+		ExpiresAt: now.Add(time.Hour),
+	}
+
+	require.NoError(t, repo.Save(ctx, token))
+
+	err := repo.Save(ctx, token)
+	require.ErrorIs(t, err, auth.ErrTokenExists)
+
+	found, err := repo.FindByHash(ctx, token.Hash)
+	require.NoError(t, err)
+	assert.Equal(t, token.ID, found.ID)
+	assert.Equal(t, token.Scopes, found.Scopes)
+	assert.True(t, token.ExpiresAt.Equal(found.ExpiresAt))
+
+	tokens, err := repo.List(ctx, "tokenUser")
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+
+	require.ErrorIs(t, repo.Delete(ctx, "otherUser", token.ID), auth.ErrTokenNotFound)
+	require.NoError(t, repo.Delete(ctx, "tokenUser", token.ID))
+
+	_, err = repo.FindByHash(ctx, token.Hash)
+	require.ErrorIs(t, err, auth.ErrTokenNotFound)
+}
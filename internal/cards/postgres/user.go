@@ -0,0 +1,253 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/konstantinfoerster/card-service-go/internal/auth"
+)
+
+type PostgresUserRepository struct {
+	db *DBConnection
+}
+
+func NewUserRepository(connection *DBConnection) *PostgresUserRepository {
+	return &PostgresUserRepository{
+		db: connection,
+	}
+}
+
+func (r *PostgresUserRepository) Create(ctx context.Context, u auth.User) error {
+	args := pgx.NamedArgs{
+		"id":          u.ID,
+		"provider":    u.Provider,
+		"subject":     u.Subject,
+		"email":       u.Email,
+		"displayName": u.DisplayName,
+		"createdAt":   u.CreatedAt,
+		"updatedAt":   u.UpdatedAt,
+	}
+	userQuery := `
+INSERT INTO
+  users (id, provider, subject, email, display_name, created_at, updated_at)
+VALUES
+  (@id, @provider, @subject, @email, @displayName, @createdAt, @updatedAt)
+ON CONFLICT
+  (id)
+DO NOTHING`
+	identityQuery := `
+INSERT INTO
+  user_identity (provider, subject, user_id)
+VALUES
+  (@provider, @subject, @id)
+ON CONFLICT
+  (provider, subject)
+DO NOTHING`
+
+	return r.db.WithTransaction(ctx, func(conn *DBConnection) error {
+		if _, err := conn.Conn.Exec(ctx, userQuery, args); err != nil {
+			return fmt.Errorf("create user failed due to exec error %w", err)
+		}
+
+		if _, err := conn.Conn.Exec(ctx, identityQuery, args); err != nil {
+			return fmt.Errorf("create user identity failed due to exec error %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *PostgresUserRepository) FindByID(ctx context.Context, id string) (auth.User, error) {
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+	query := `
+SELECT
+  id, provider, subject, email, display_name, created_at, updated_at
+FROM
+  users
+WHERE
+  id = @id`
+
+	return r.findOne(ctx, query, args)
+}
+
+func (r *PostgresUserRepository) FindByIdentity(ctx context.Context, identity auth.Identity) (auth.User, error) {
+	args := pgx.NamedArgs{
+		"provider": identity.Provider,
+		"subject":  identity.Subject,
+	}
+	query := `
+SELECT
+  u.id, u.provider, u.subject, u.email, u.display_name, u.created_at, u.updated_at
+FROM
+  users AS u
+JOIN
+  user_identity AS i ON i.user_id = u.id
+WHERE
+  i.provider = @provider
+AND
+  i.subject = @subject`
+
+	return r.findOne(ctx, query, args)
+}
+
+func (r *PostgresUserRepository) Update(ctx context.Context, u auth.User) error {
+	args := pgx.NamedArgs{
+		"id":          u.ID,
+		"email":       u.Email,
+		"displayName": u.DisplayName,
+		"updatedAt":   u.UpdatedAt,
+	}
+	query := `
+UPDATE
+  users
+SET
+  email = @email, display_name = @displayName, updated_at = @updatedAt
+WHERE
+  id = @id`
+	tag, err := r.db.Conn.Exec(ctx, query, args)
+	if err != nil {
+		return fmt.Errorf("update user failed due to exec error %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return auth.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// Link assigns the identity to the user and moves all data of the user from within one transaction.
+// Collected cards that exist for both users keep the higher amount, conflicting token names get a suffix.
+func (r *PostgresUserRepository) Link(ctx context.Context, userID string, identity auth.Identity,
+	from string) error {
+	args := pgx.NamedArgs{
+		"userID":   userID,
+		"from":     from,
+		"provider": identity.Provider,
+		"subject":  identity.Subject,
+	}
+	queries := []string{
+		`
+UPDATE
+  card_collection AS c
+SET
+  amount = GREATEST(c.amount, o.amount)
+FROM
+  card_collection AS o
+WHERE
+  c.user_id = @userID AND o.user_id = @from AND c.card_id = o.card_id`,
+		`
+DELETE FROM
+  card_collection
+WHERE
+  user_id = @from
+AND
+  card_id IN (SELECT card_id FROM card_collection WHERE user_id = @userID)`,
+		`UPDATE card_collection SET user_id = @userID WHERE user_id = @from`,
+		`
+UPDATE
+  personal_access_token AS t
+SET
+  user_id = @userID,
+  name = CASE
+    WHEN EXISTS (SELECT 1 FROM personal_access_token WHERE user_id = @userID AND name = t.name)
+    THEN LEFT(t.name, 91) || '-' || LEFT(t.id, 8)
+    ELSE t.name
+  END
+WHERE
+  t.user_id = @from`,
+		`
+INSERT INTO
+  user_role (user_id, role)
+SELECT
+  @userID, role
+FROM
+  user_role
+WHERE
+  user_id = @from
+ON CONFLICT
+  (user_id, role)
+DO NOTHING`,
+		`DELETE FROM user_role WHERE user_id = @from`,
+		`UPDATE job SET owner = @userID WHERE owner = @from`,
+		`UPDATE user_identity SET user_id = @userID WHERE user_id = @from`,
+		`DELETE FROM users WHERE id = @from`,
+		`
+INSERT INTO
+  user_identity (provider, subject, user_id)
+VALUES
+  (@provider, @subject, @userID)
+ON CONFLICT
+  (provider, subject)
+DO UPDATE SET
+  user_id = excluded.user_id`,
+	}
+	if from == "" {
+		queries = queries[len(queries)-1:]
+	}
+
+	return r.db.WithTransaction(ctx, func(conn *DBConnection) error {
+		for _, query := range queries {
+			if _, err := conn.Conn.Exec(ctx, query, args); err != nil {
+				return fmt.Errorf("link identity failed due to exec error %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Delete removes the user and all data of the user within one transaction.
+func (r *PostgresUserRepository) Delete(ctx context.Context, id string) error {
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+	queries := []string{
+		`DELETE FROM card_collection WHERE user_id = @id`,
+		`DELETE FROM personal_access_token WHERE user_id = @id`,
+		`DELETE FROM user_role WHERE user_id = @id`,
+		`DELETE FROM job WHERE owner = @id`,
+		`
+DELETE FROM
+  local_account
+WHERE
+  id IN (SELECT subject FROM user_identity WHERE user_id = @id AND provider = 'local')`,
+		`DELETE FROM users WHERE id = @id`,
+	}
+
+	return r.db.WithTransaction(ctx, func(conn *DBConnection) error {
+		for _, query := range queries {
+			if _, err := conn.Conn.Exec(ctx, query, args); err != nil {
+				return fmt.Errorf("delete user failed due to exec error %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *PostgresUserRepository) findOne(ctx context.Context, query string, args pgx.NamedArgs) (auth.User, error) {
+	var u auth.User
+	err := r.db.Conn.QueryRow(ctx, query, args).Scan(
+		&u.ID,
+		&u.Provider,
+		&u.Subject,
+		&u.Email,
+		&u.DisplayName,
+		&u.CreatedAt,
+		&u.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return auth.User{}, auth.ErrUserNotFound
+		}
+
+		return auth.User{}, fmt.Errorf("find user failed during row scan %w", err)
+	}
+
+	return u, nil
+}
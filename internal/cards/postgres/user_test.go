@@ -0,0 +1,152 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/konstantinfoerster/card-service-go/internal/auth"
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/postgres"
+	"github.com/konstantinfoerster/card-service-go/internal/jobs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	repo := postgres.NewUserRepository(connection)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	u := auth.User{
+		ID:        "profileUser",
+		Provider:  "google",
+		Subject:   "profileUser",
+		Email:     "profile@localhost",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	require.NoError(t, repo.Create(ctx, u))
+	require.NoError(t, repo.Create(ctx, u))
+
+	u.DisplayName = "Profile"
+	u.UpdatedAt = now.Add(time.Hour)
+	require.NoError(t, repo.Update(ctx, u))
+
+	found, err := repo.FindByID(ctx, "profileUser")
+	require.NoError(t, err)
+	assert.Equal(t, "Profile", found.DisplayName)
+	assert.Equal(t, "google", found.Provider)
+	assert.True(t, u.UpdatedAt.Equal(found.UpdatedAt))
+
+	require.ErrorIs(t, repo.Update(ctx, auth.User{ID: "unknown"}), auth.ErrUserNotFound)
+}
+
+func TestDeleteUserRemovesRelatedData(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	now := time.Now()
+	userID := "deletedUser"
+	users := postgres.NewUserRepository(connection)
+	require.NoError(t, users.Create(ctx, auth.User{
+		ID: userID, Provider: "google", Subject: userID, CreatedAt: now, UpdatedAt: now,
+	}))
+	collection := postgres.NewCollectionRepository(connection, postgres.Images{})
+	item, err := cards.NewCollectable(cards.NewID(1), 2)
+	require.NoError(t, err)
+	require.NoError(t, collection.Collect(ctx, item, cards.NewCollector(userID)))
+	roles := postgres.NewRoleRepository(connection)
+	require.NoError(t, roles.Assign(ctx, userID, auth.RoleAdmin))
+	tokens := postgres.NewTokenRepository(connection)
+	require.NoError(t, tokens.Save(ctx, auth.PersonalToken{
+		ID: "4c1d3a2e-5f60-4b7a-8c9d-0e1f2a3b4c5d", UserID: userID, Name: "script",
+		Hash: "1111111111111111111111111111111111111111111111111111111111111111", CreatedAt: now, ExpiresAt: now,
+	}))
+
+	jobRepo := postgres.NewJobRepository(connection)
+	job := jobs.Job{
+		ID: "6f1b1f0e-0000-4000-8000-000000000030", Owner: userID, Status: jobs.StatusDone,
+		CreatedAt: now, UpdatedAt: now,
+	}
+	require.NoError(t, jobRepo.Save(ctx, job))
+
+	require.NoError(t, users.Delete(ctx, userID))
+
+	_, err = users.FindByID(ctx, userID)
+	require.ErrorIs(t, err, auth.ErrUserNotFound)
+	r, err := roles.Roles(ctx, userID)
+	require.NoError(t, err)
+	assert.Empty(t, r)
+	tt, err := tokens.List(ctx, userID)
+	require.NoError(t, err)
+	assert.Empty(t, tt)
+	_, err = jobRepo.Find(ctx, job.ID)
+	require.ErrorIs(t, err, jobs.ErrJobNotFound)
+	f := cards.NewFilter().WithCollector(cards.NewCollector(userID)).WithOnlyCollected()
+	result, err := collection.Find(ctx, f, cards.NewPage(1, 10))
+	require.NoError(t, err)
+	assert.Empty(t, result.Result)
+}
+
+func TestLinkMergesUsers(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	now := time.Now()
+	users := postgres.NewUserRepository(connection)
+	collection := postgres.NewCollectionRepository(connection, postgres.Images{})
+	roles := postgres.NewRoleRepository(connection)
+	require.NoError(t, users.Create(ctx, auth.User{
+		ID: "linkTarget", Provider: "google", Subject: "linkTarget", CreatedAt: now, UpdatedAt: now,
+	}))
+	require.NoError(t, users.Create(ctx, auth.User{
+		ID: "linkSource", Provider: "local", Subject: "linkSource", CreatedAt: now, UpdatedAt: now,
+	}))
+	collect := func(userID string, cardID, amount int) {
+		item, err := cards.NewCollectable(cards.NewID(cardID), amount)
+		require.NoError(t, err)
+		require.NoError(t, collection.Collect(ctx, item, cards.NewCollector(userID)))
+	}
+	collect("linkTarget", 1, 1)
+	collect("linkSource", 1, 3)
+	collect("linkSource", 2, 1)
+	require.NoError(t, roles.Assign(ctx, "linkSource", auth.RoleAdmin))
+	jobRepo := postgres.NewJobRepository(connection)
+	job := jobs.Job{
+		ID: "6f1b1f0e-0000-4000-8000-000000000031", Owner: "linkSource", Status: jobs.StatusDone,
+		CreatedAt: now, UpdatedAt: now,
+	}
+	require.NoError(t, jobRepo.Save(ctx, job))
+
+	identity := auth.Identity{Provider: "local", Subject: "linkSource"}
+	require.NoError(t, users.Link(ctx, "linkTarget", identity, "linkSource"))
+
+	linked, err := users.FindByIdentity(ctx, identity)
+	require.NoError(t, err)
+	assert.Equal(t, "linkTarget", linked.ID)
+	_, err = users.FindByID(ctx, "linkSource")
+	require.ErrorIs(t, err, auth.ErrUserNotFound)
+	r, err := roles.Roles(ctx, "linkTarget")
+	require.NoError(t, err)
+	assert.Equal(t, []auth.Role{auth.RoleAdmin}, r)
+	movedJob, err := jobRepo.Find(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "linkTarget", movedJob.Owner)
+	f := cards.NewFilter().WithCollector(cards.NewCollector("linkTarget")).WithOnlyCollected()
+	result, err := collection.Find(ctx, f, cards.NewPage(1, 10))
+	require.NoError(t, err)
+	amounts := make(map[int]int)
+	for _, c := range result.Result {
+		amounts[c.ID.CardID] = c.Amount
+	}
+	assert.Equal(t, map[int]int{1: 3, 2: 1}, amounts)
+}
@@ -0,0 +1,190 @@
+package cards
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math/bits"
+	"slices"
+
+	"github.com/konstantinfoerster/card-service-go/internal/aerrors"
+)
+
+var ErrUnknownCardRegion = errors.New("unknown card region")
+
+// CardRegion a part of the card, used to tell prints of the same card apart or to read its text.
+type CardRegion string
+
+const (
+	// ArtRegion the illustration of the card.
+	ArtRegion CardRegion = "art"
+	// SymbolRegion the set symbol on the right side of the type line.
+	SymbolRegion CardRegion = "symbol"
+	// NameRegion the title bar with the card name.
+	NameRegion CardRegion = "name"
+	// CollectorRegion the collector line with the collector number in the bottom left corner.
+	CollectorRegion CardRegion = "collector"
+)
+
+// CardRegions returns all card regions that can be used for the re-ranking.
+func CardRegions() []CardRegion {
+	return []CardRegion{ArtRegion, SymbolRegion}
+}
+
+// Box returns the position of the region relative to an upright card with a modern frame.
+func (r CardRegion) Box() Box {
+	switch r {
+	case ArtRegion:
+		return Box{X: 0.08, Y: 0.11, Width: 0.84, Height: 0.44}
+	case SymbolRegion:
+		return Box{X: 0.82, Y: 0.555, Width: 0.11, Height: 0.06}
+	case NameRegion:
+		return Box{X: 0.06, Y: 0.04, Width: 0.7, Height: 0.06}
+	case CollectorRegion:
+		return Box{X: 0.04, Y: 0.905, Width: 0.45, Height: 0.05}
+	default:
+		return FullBox()
+	}
+}
+
+// RerankConfig the second stage of the detection that compares card regions to tell the prints of
+// the same card apart.
+type RerankConfig struct {
+	// Regions the compared card regions, the re-ranking is disabled if empty.
+	Regions []CardRegion `yaml:"regions"`
+	// Algorithm used to hash the regions, pHash if empty.
+	Algorithm HashAlgorithm `yaml:"algorithm"`
+}
+
+// Enabled returns true if at least one region is configured.
+func (c RerankConfig) Enabled() bool {
+	return len(c.Regions) > 0
+}
+
+// HashAlgorithm returns the configured algorithm or pHash if none is configured.
+func (c RerankConfig) HashAlgorithm() HashAlgorithm {
+	if c.Algorithm == "" {
+		return PHash
+	}
+
+	return c.Algorithm
+}
+
+func (c RerankConfig) Validate() error {
+	for _, r := range c.Regions {
+		if !slices.Contains(CardRegions(), r) {
+			return fmt.Errorf("%w %q", ErrUnknownCardRegion, r)
+		}
+	}
+	if !slices.Contains(HashAlgorithms(), c.HashAlgorithm()) {
+		return fmt.Errorf("%w %q", ErrUnknownHashAlgorithm, c.HashAlgorithm())
+	}
+
+	return nil
+}
+
+// RegionHash the hash of a single region of a card image.
+type RegionHash struct {
+	Region CardRegion
+	Hash   Hash
+}
+
+// Distance returns the number of different bits, hashes of different size have the max distance
+// of the longer hash.
+func (h Hash) Distance(o Hash) int {
+	if len(h.Value) != len(o.Value) {
+		return max(len(h.Value), len(o.Value)) * 64
+	}
+
+	d := 0
+	for i := range h.Value {
+		d += bits.OnesCount64(h.Value[i] ^ o.Value[i])
+	}
+
+	return d
+}
+
+// rerank reorders the prints of the same card by the distance of the card regions of the upright card.
+// The order of different cards and the confidence of the matches are not changed.
+func (s *DetectService) rerank(ctx context.Context, upright Detectable, matches []Match) ([]Match, error) {
+	if !hasPrints(matches) {
+		return matches, nil
+	}
+
+	alg := s.cfg.Rerank.HashAlgorithm()
+	candidate := make([]RegionHash, 0, len(s.cfg.Rerank.Regions))
+	for _, r := range s.cfg.Rerank.Regions {
+		h, err := upright.Crop(r.Box()).Hash(alg)
+		if err != nil {
+			return nil, aerrors.NewUnknownError(err, "region-hashing-failed")
+		}
+		candidate = append(candidate, RegionHash{Region: r, Hash: h})
+	}
+
+	ids := make([]ID, 0, len(matches))
+	for _, m := range matches {
+		ids = append(ids, m.ID)
+	}
+	stored, err := s.dRepo.RegionHashes(ctx, alg, ids...)
+	if err != nil {
+		return nil, aerrors.NewUnknownError(err, "unable-to-execute-region-hash-search")
+	}
+
+	distances := make(map[ID]int, len(matches))
+	for _, m := range matches {
+		distances[m.ID] = regionDistance(candidate, stored[m.ID])
+	}
+
+	// every print keeps one of the positions of the prints of its card
+	result := slices.Clone(matches)
+	positions := make(map[string][]int)
+	for i, m := range matches {
+		positions[m.Name] = append(positions[m.Name], i)
+	}
+	for _, pos := range positions {
+		prints := make([]Match, 0, len(pos))
+		for _, p := range pos {
+			prints = append(prints, matches[p])
+		}
+		slices.SortStableFunc(prints, func(a, b Match) int {
+			return cmp.Or(cmp.Compare(distances[a.ID], distances[b.ID]), cmp.Compare(a.Confidence, b.Confidence))
+		})
+		for i, p := range pos {
+			result[p] = prints[i]
+		}
+	}
+
+	return result, nil
+}
+
+// hasPrints returns true if at least two matches are prints of the same card.
+func hasPrints(matches []Match) bool {
+	names := make(map[string]struct{}, len(matches))
+	for _, m := range matches {
+		if _, ok := names[m.Name]; ok {
+			return true
+		}
+		names[m.Name] = struct{}{}
+	}
+
+	return false
+}
+
+// regionDistance sums the distances of all regions, a missing stored region counts with the max distance.
+func regionDistance(candidate []RegionHash, stored []RegionHash) int {
+	total := 0
+	for _, c := range candidate {
+		i := slices.IndexFunc(stored, func(s RegionHash) bool {
+			return s.Region == c.Region && s.Hash.Algorithm == c.Hash.Algorithm
+		})
+		if i < 0 {
+			total += max(c.Hash.Bits, len(c.Hash.Value)*64)
+
+			continue
+		}
+		total += c.Hash.Distance(stored[i].Hash)
+	}
+
+	return total
+}
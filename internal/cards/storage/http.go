@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/konstantinfoerster/card-service-go/internal/aio"
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+)
+
+// HTTPStore reads the images from a http(s) host, images cannot be stored.
+type HTTPStore struct {
+	host   string
+	client *http.Client
+}
+
+func NewHTTPStore(host string) *HTTPStore {
+	timeout := 30 * time.Second
+
+	return &HTTPStore{
+		host:   host,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (s *HTTPStore) Open(ctx context.Context, path string) (cards.ImageFile, error) {
+	resp, err := s.do(ctx, http.MethodGet, path)
+	if err != nil {
+		return cards.ImageFile{}, err
+	}
+
+	f := toImageFile(resp)
+	f.Content = resp.Body
+
+	return f, nil
+}
+
+func (s *HTTPStore) Stat(ctx context.Context, path string) (cards.ImageFile, error) {
+	resp, err := s.do(ctx, http.MethodHead, path)
+	if err != nil {
+		return cards.ImageFile{}, err
+	}
+	aio.Close(resp.Body)
+
+	return toImageFile(resp), nil
+}
+
+func (s *HTTPStore) Put(_ context.Context, path string, _ []byte, _ string) error {
+	return fmt.Errorf("%s, %w", path, ErrReadOnly)
+}
+
+func (s *HTTPStore) do(ctx context.Context, method string, path string) (*http.Response, error) {
+	u, err := url.JoinPath(s.host, cleanPath(path))
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		aio.Close(resp.Body)
+
+		err = fmt.Errorf("%s returned status %d", u, resp.StatusCode)
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, errors.Join(err, cards.ErrImageNotFound)
+		}
+
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func toImageFile(resp *http.Response) cards.ImageFile {
+	modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+
+	return cards.ImageFile{
+		ContentType: resp.Header.Get("Content-Type"),
+		Size:        resp.ContentLength,
+		ModTime:     modTime,
+		Tag:         strings.Trim(strings.TrimPrefix(resp.Header.Get("ETag"), "W/"), `"`),
+	}
+}
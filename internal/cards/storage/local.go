@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/konstantinfoerster/card-service-go/internal/aio"
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+)
+
+// LocalStore stores the images in a local directory.
+type LocalStore struct {
+	dir string
+}
+
+func NewLocalStore(dir string) *LocalStore {
+	return &LocalStore{
+		dir: dir,
+	}
+}
+
+func (s *LocalStore) Open(_ context.Context, path string) (cards.ImageFile, error) {
+	f, err := os.Open(s.file(path))
+	if err != nil {
+		return cards.ImageFile{}, notFound(err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		aio.Close(f)
+
+		return cards.ImageFile{}, fmt.Errorf("failed to read file info %w", err)
+	}
+	if info.IsDir() {
+		aio.Close(f)
+
+		return cards.ImageFile{}, fmt.Errorf("%s is a directory, %w", path, cards.ErrImageNotFound)
+	}
+
+	return cards.ImageFile{
+		Content:     f,
+		ContentType: contentType(path),
+		Size:        info.Size(),
+		ModTime:     info.ModTime(),
+	}, nil
+}
+
+func (s *LocalStore) Stat(_ context.Context, path string) (cards.ImageFile, error) {
+	info, err := os.Stat(s.file(path))
+	if err != nil {
+		return cards.ImageFile{}, notFound(err)
+	}
+	if info.IsDir() {
+		return cards.ImageFile{}, fmt.Errorf("%s is a directory, %w", path, cards.ErrImageNotFound)
+	}
+
+	return cards.ImageFile{
+		ContentType: contentType(path),
+		Size:        info.Size(),
+		ModTime:     info.ModTime(),
+	}, nil
+}
+
+// Put writes the image to a temporary file first, concurrent readers never see a partially written image.
+func (s *LocalStore) Put(_ context.Context, path string, data []byte, _ string) error {
+	target := s.file(path)
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return fmt.Errorf("failed to create image directory %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".image-*")
+	if err != nil {
+		return fmt.Errorf("failed to create image %w", err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck // already renamed on success
+
+	if _, err = tmp.Write(data); err != nil {
+		aio.Close(tmp)
+
+		return fmt.Errorf("failed to write image %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write image %w", err)
+	}
+	if err = os.Rename(tmp.Name(), target); err != nil {
+		return fmt.Errorf("failed to move image %w", err)
+	}
+
+	return nil
+}
+
+func (s *LocalStore) file(path string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(cleanPath(path)))
+}
+
+func notFound(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return errors.Join(err, cards.ErrImageNotFound)
+	}
+
+	return err
+}
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/konstantinfoerster/card-service-go/internal/aio"
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Store stores the images in a bucket of an S3 compatible object storage.
+type S3Store struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+func NewS3Store(cfg S3Config) (*S3Store, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: !cfg.Insecure,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create s3 client %w", err)
+	}
+
+	return &S3Store{
+		client: client,
+		bucket: cfg.Bucket,
+		prefix: cfg.Prefix,
+	}, nil
+}
+
+func (s *S3Store) Open(ctx context.Context, path string) (cards.ImageFile, error) {
+	obj, err := s.client.GetObject(ctx, s.bucket, s.key(path), minio.GetObjectOptions{})
+	if err != nil {
+		return cards.ImageFile{}, notFoundObject(err)
+	}
+	// the object is requested lazily, stat sends the request and returns the response header
+	info, err := obj.Stat()
+	if err != nil {
+		aio.Close(obj)
+
+		return cards.ImageFile{}, notFoundObject(err)
+	}
+
+	f := toObjectFile(info)
+	f.Content = obj
+
+	return f, nil
+}
+
+func (s *S3Store) Stat(ctx context.Context, path string) (cards.ImageFile, error) {
+	info, err := s.client.StatObject(ctx, s.bucket, s.key(path), minio.StatObjectOptions{})
+	if err != nil {
+		return cards.ImageFile{}, notFoundObject(err)
+	}
+
+	return toObjectFile(info), nil
+}
+
+func (s *S3Store) Put(ctx context.Context, path string, data []byte, contentType string) error {
+	_, err := s.client.PutObject(ctx, s.bucket, s.key(path), bytes.NewReader(data), int64(len(data)),
+		minio.PutObjectOptions{ContentType: contentType})
+	if err != nil {
+		return fmt.Errorf("failed to put object %w", err)
+	}
+
+	return nil
+}
+
+func (s *S3Store) key(path string) string {
+	return s.prefix + cleanPath(path)
+}
+
+func toObjectFile(info minio.ObjectInfo) cards.ImageFile {
+	return cards.ImageFile{
+		ContentType: info.ContentType,
+		Size:        info.Size,
+		ModTime:     info.LastModified,
+		Tag:         strings.Trim(info.ETag, `"`),
+	}
+}
+
+func notFoundObject(err error) error {
+	if minio.ToErrorResponse(err).StatusCode == http.StatusNotFound {
+		return errors.Join(err, cards.ErrImageNotFound)
+	}
+
+	return err
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"errors"
+	"mime"
+	"path"
+	"strings"
+
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+)
+
+var ErrReadOnly = errors.New("image store is read only")
+
+// S3Config an S3 compatible object storage, e.g. AWS S3 or MinIO.
+type S3Config struct {
+	// Endpoint the host and port of the storage without scheme, e.g. s3.amazonaws.com or localhost:9000.
+	Endpoint string `yaml:"endpoint"`
+	Bucket   string `yaml:"bucket"`
+	Region   string `yaml:"region"`
+	// Prefix added to the path of every image, e.g. cards/.
+	Prefix    string `yaml:"prefix"`
+	AccessKey string `yaml:"access_key"`
+	SecretKey string `yaml:"secret_key"`
+	// Insecure uses http instead of https.
+	Insecure bool `yaml:"insecure"`
+}
+
+// Enabled returns true if a bucket is configured.
+func (c S3Config) Enabled() bool {
+	return c.Bucket != ""
+}
+
+// New returns the S3 store if a bucket is configured, otherwise the http store for a http(s) URL or
+// the local store for a directory.
+func New(location string, s3 S3Config) (cards.ImageStore, error) {
+	if s3.Enabled() {
+		return NewS3Store(s3)
+	}
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		return NewHTTPStore(location), nil
+	}
+
+	return NewLocalStore(location), nil
+}
+
+// cleanPath removes all parent directory elements, a path never leaves the root of the store.
+func cleanPath(p string) string {
+	return strings.TrimPrefix(path.Clean("/"+p), "/")
+}
+
+func contentType(p string) string {
+	if t := mime.TypeByExtension(path.Ext(p)); t != "" {
+		return t
+	}
+
+	return "application/octet-stream"
+}
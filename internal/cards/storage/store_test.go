@@ -0,0 +1,235 @@
+package storage_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/johannesboyne/gofakes3"
+	"github.com/johannesboyne/gofakes3/backend/s3mem"
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	cases := []struct {
+		name  string
+		store cards.ImageStore
+	}{
+		{
+			name:  "local directory",
+			store: storage.NewLocalStore(t.TempDir()),
+		},
+		{
+			name:  "s3 bucket",
+			store: s3Store(t, ""),
+		},
+		{
+			name:  "s3 bucket with prefix",
+			store: s3Store(t, "cards/"),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			err := tc.store.Put(ctx, "images/card.jpg", []byte("card"), "image/jpeg")
+			require.NoError(t, err)
+			info, err := tc.store.Stat(ctx, "images/card.jpg")
+			require.NoError(t, err)
+			f, err := tc.store.Open(ctx, "images/card.jpg")
+			require.NoError(t, err)
+			defer f.Content.Close()
+			content, err := io.ReadAll(f.Content)
+			require.NoError(t, err)
+
+			assert.Equal(t, "card", string(content))
+			assert.Equal(t, "image/jpeg", f.ContentType)
+			assert.Equal(t, int64(4), f.Size)
+			assert.Equal(t, f.ETag(), info.ETag())
+		})
+	}
+}
+
+func TestStoreReplacesImage(t *testing.T) {
+	cases := []struct {
+		name  string
+		store cards.ImageStore
+	}{
+		{
+			name:  "local directory",
+			store: storage.NewLocalStore(t.TempDir()),
+		},
+		{
+			name:  "s3 bucket",
+			store: s3Store(t, ""),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			require.NoError(t, tc.store.Put(ctx, "card.jpg", []byte("old"), "image/jpeg"))
+			require.NoError(t, tc.store.Put(ctx, "card.jpg", []byte("new card"), "image/jpeg"))
+			f, err := tc.store.Open(ctx, "card.jpg")
+			require.NoError(t, err)
+			defer f.Content.Close()
+			content, err := io.ReadAll(f.Content)
+
+			require.NoError(t, err)
+			assert.Equal(t, "new card", string(content))
+		})
+	}
+}
+
+func TestStoreImageNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.FileServer(http.Dir(t.TempDir())))
+	defer srv.Close()
+	cases := []struct {
+		name  string
+		store cards.ImageStore
+	}{
+		{
+			name:  "local directory",
+			store: storage.NewLocalStore(t.TempDir()),
+		},
+		{
+			name:  "http host",
+			store: storage.NewHTTPStore(srv.URL),
+		},
+		{
+			name:  "s3 bucket",
+			store: s3Store(t, ""),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			_, openErr := tc.store.Open(ctx, "unknown.jpg")
+			_, statErr := tc.store.Stat(ctx, "unknown.jpg")
+
+			require.ErrorIs(t, openErr, cards.ErrImageNotFound)
+			require.ErrorIs(t, statErr, cards.ErrImageNotFound)
+		})
+	}
+}
+
+func TestLocalStoreStaysInsideDirectory(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "images")
+	require.NoError(t, os.WriteFile(filepath.Join(root, "secret.txt"), []byte("secret"), 0o600))
+	store := storage.NewLocalStore(dir)
+
+	_, err := store.Open(context.Background(), "../secret.txt")
+
+	require.ErrorIs(t, err, cards.ErrImageNotFound)
+}
+
+func TestHTTPStore(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "card.jpg"), []byte("card"), 0o600))
+	srv := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer srv.Close()
+	store := storage.NewHTTPStore(srv.URL)
+	ctx := context.Background()
+
+	f, err := store.Open(ctx, "card.jpg")
+	require.NoError(t, err)
+	defer f.Content.Close()
+	content, err := io.ReadAll(f.Content)
+	require.NoError(t, err)
+	info, err := store.Stat(ctx, "card.jpg")
+	require.NoError(t, err)
+	err = store.Put(ctx, "card.jpg", []byte("new"), "image/jpeg")
+
+	assert.Equal(t, "card", string(content))
+	assert.Equal(t, "image/jpeg", info.ContentType)
+	assert.Equal(t, int64(4), info.Size)
+	require.ErrorIs(t, err, storage.ErrReadOnly)
+}
+
+func TestHTTPStoreETag(t *testing.T) {
+	cases := []struct {
+		name     string
+		header   map[string]string
+		expected string
+	}{
+		{
+			name:     "etag of the host",
+			header:   map[string]string{"ETag": `W/"abc"`, "Last-Modified": "Wed, 21 Oct 2015 07:28:00 GMT"},
+			expected: `"abc"`,
+		},
+		{
+			name:     "last modified and content length",
+			header:   map[string]string{"Last-Modified": "Wed, 21 Oct 2015 07:28:00 GMT"},
+			expected: `"140f22fe10510000-4"`,
+		},
+		{
+			name:     "without last modified",
+			header:   map[string]string{},
+			expected: "",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				for k, v := range tc.header {
+					w.Header().Set(k, v)
+				}
+				_, _ = w.Write([]byte("card"))
+			}))
+			defer srv.Close()
+
+			info, err := storage.NewHTTPStore(srv.URL).Stat(context.Background(), "card.jpg")
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, info.ETag())
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	local, err := storage.New("testdata", storage.S3Config{})
+	require.NoError(t, err)
+	remote, err := storage.New("https://localhost/images", storage.S3Config{})
+	require.NoError(t, err)
+	bucket, err := storage.New("testdata", storage.S3Config{Endpoint: "localhost:9000", Bucket: "cards"})
+	require.NoError(t, err)
+
+	assert.IsType(t, &storage.LocalStore{}, local)
+	assert.IsType(t, &storage.HTTPStore{}, remote)
+	assert.IsType(t, &storage.S3Store{}, bucket)
+}
+
+// s3Store returns a store of an in-memory S3 server.
+func s3Store(t *testing.T, prefix string) *storage.S3Store {
+	t.Helper()
+
+	backend := s3mem.New()
+	require.NoError(t, backend.CreateBucket("cards"))
+	srv := httptest.NewServer(gofakes3.New(backend).Server())
+	t.Cleanup(srv.Close)
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	store, err := storage.NewS3Store(storage.S3Config{
+		Endpoint:  u.Host,
+		Bucket:    "cards",
+		Region:    "us-east-1",
+		Prefix:    prefix,
+		AccessKey: "access",
+		SecretKey: "secret",
+		Insecure:  true,
+	})
+	require.NoError(t, err)
+
+	return store
+}
@@ -9,7 +9,9 @@ import (
 
 	"github.com/konstantinfoerster/card-service-go/internal/api/web"
 	"github.com/konstantinfoerster/card-service-go/internal/auth"
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
 	"github.com/konstantinfoerster/card-service-go/internal/cards/postgres"
+	"github.com/konstantinfoerster/card-service-go/internal/jobs"
 	"gopkg.in/yaml.v3"
 )
 
@@ -19,12 +21,14 @@ var (
 )
 
 type Config struct {
-	Database postgres.Config `yaml:"database"`
-	Logging  Logging         `yaml:"logging"`
-	Images   postgres.Images `yaml:"images"`
-	Server   web.Config      `yaml:"server"`
-	Probes   web.Config      `yaml:"probes"`
-	Oidc     auth.Config     `yaml:"oidc"`
+	Database  postgres.Config    `yaml:"database"`
+	Logging   Logging            `yaml:"logging"`
+	Images    postgres.Images    `yaml:"images"`
+	Server    web.Config         `yaml:"server"`
+	Probes    web.Config         `yaml:"probes"`
+	Oidc      auth.Config        `yaml:"oidc"`
+	Detection cards.DetectConfig `yaml:"detection"`
+	Jobs      jobs.Config        `yaml:"jobs"`
 }
 
 type Logging struct {
@@ -40,6 +44,9 @@ func NewConfig(path string) (Config, error) {
 	}
 
 	defaultTimeoutSec := 5
+	defaultCollectMaxScore := 10
+	defaultJobWorkers := 2
+	defaultJobQueueSize := 100
 	defaultConfig := Config{
 		Logging: Logging{
 			Level: "info",
@@ -56,6 +63,20 @@ func NewConfig(path string) (Config, error) {
 			StateCookieAge:    time.Minute,
 			ClientTimeout:     time.Duration(defaultTimeoutSec) * time.Second,
 		},
+		Images: postgres.Images{
+			CacheDir: filepath.Join(os.TempDir(), "card-service", "thumbnails"),
+		},
+		Detection: cards.DetectConfig{
+			CollectMaxScore: defaultCollectMaxScore,
+			IndexRefresh:    time.Minute,
+		},
+		Jobs: jobs.Config{
+			Workers:   defaultJobWorkers,
+			QueueSize: defaultJobQueueSize,
+			Timeout:   time.Minute,
+			Retention: time.Hour,
+			Store:     jobs.StoreMemory,
+		},
 	}
 
 	err = yaml.Unmarshal(data, &defaultConfig)
@@ -64,6 +85,12 @@ func NewConfig(path string) (Config, error) {
 	}
 
 	// TODO: validate config content
+	if err = defaultConfig.Detection.Validate(); err != nil {
+		return Config{}, errors.Join(err, ErrInvalidContent)
+	}
+	if err = defaultConfig.Jobs.Validate(); err != nil {
+		return Config{}, errors.Join(err, ErrInvalidContent)
+	}
 
 	if strings.HasSuffix(defaultConfig.Images.Host, "") {
 		defaultConfig.Images.Host += "/"
@@ -4,7 +4,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/konstantinfoerster/card-service-go/internal/cards"
 	"github.com/konstantinfoerster/card-service-go/internal/config"
+	"github.com/konstantinfoerster/card-service-go/internal/jobs"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -19,6 +21,12 @@ func TestNewConfig_Defaults(t *testing.T) {
 	assert.NotEmpty(t, cfg.Logging.Level)
 	assert.NotEmpty(t, cfg.Oidc.SessionCookieName)
 	assert.Greater(t, cfg.Oidc.StateCookieAge, time.Second)
+	assert.Positive(t, cfg.Detection.CollectMaxScore)
+	assert.Equal(t, time.Minute, cfg.Detection.IndexRefresh)
+	assert.Positive(t, cfg.Jobs.Workers)
+	assert.Positive(t, cfg.Jobs.QueueSize)
+	assert.Equal(t, jobs.StoreMemory, cfg.Jobs.Store)
+	assert.NotEmpty(t, cfg.Images.CacheDir)
 }
 
 func TestNewConfig_OverwriteDefaults(t *testing.T) {
@@ -28,6 +36,19 @@ func TestNewConfig_OverwriteDefaults(t *testing.T) {
 	assert.Equal(t, "trace", cfg.Logging.Level)
 	assert.Equal(t, "SESSION_TEST", cfg.Oidc.SessionCookieName)
 	assert.Equal(t, time.Hour*2, cfg.Oidc.StateCookieAge)
+	assert.Equal(t, 5, cfg.Detection.CollectMaxScore)
+	assert.Equal(t, time.Second*30, cfg.Detection.IndexRefresh)
+	assert.Equal(t, cards.ScoringCombined, cfg.Detection.Scoring)
+	assert.Equal(t, []cards.HashConfig{
+		{Algorithm: cards.PHash, MaxDistance: 40},
+		{Algorithm: cards.WHash},
+	}, cfg.Detection.Hashes)
+	assert.Equal(t, []cards.CardRegion{cards.ArtRegion, cards.SymbolRegion}, cfg.Detection.Rerank.Regions)
+	assert.Equal(t, 4, cfg.Jobs.Workers)
+	assert.Equal(t, 100, cfg.Jobs.QueueSize)
+	assert.Equal(t, jobs.StorePostgres, cfg.Jobs.Store)
+	assert.Equal(t, "/var/card-images", cfg.Images.Dir)
+	assert.Equal(t, "/var/cache/card-images", cfg.Images.CacheDir)
 }
 
 func TestNewConfig_NotAFile(t *testing.T) {
@@ -52,3 +73,17 @@ func TestNewConfig_NotAFile(t *testing.T) {
 		})
 	}
 }
+
+func TestNewConfig_InvalidDetection(t *testing.T) {
+	_, err := config.NewConfig("testdata/invalid-detection.yaml")
+
+	require.ErrorIs(t, err, config.ErrInvalidContent)
+	require.ErrorIs(t, err, cards.ErrUnknownHashAlgorithm)
+}
+
+func TestNewConfig_InvalidJobs(t *testing.T) {
+	_, err := config.NewConfig("testdata/invalid-jobs.yaml")
+
+	require.ErrorIs(t, err, config.ErrInvalidContent)
+	require.ErrorIs(t, err, jobs.ErrUnknownJobStore)
+}
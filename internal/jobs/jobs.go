@@ -0,0 +1,94 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrJobNotFound     = errors.New("job not found")
+	ErrQueueFull       = errors.New("job queue is full")
+	ErrJobPanic        = errors.New("job panicked")
+	ErrUnknownJobStore = errors.New("unknown job store")
+)
+
+// Status the state of a job, a job is finished once it is done, failed or canceled.
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusRunning  Status = "running"
+	StatusDone     Status = "done"
+	StatusFailed   Status = "failed"
+	StatusCanceled Status = "canceled"
+)
+
+// Finished returns true if the status will not change anymore.
+func (s Status) Finished() bool {
+	return s == StatusDone || s == StatusFailed || s == StatusCanceled
+}
+
+// Job a unit of work that is executed in the background.
+type Job struct {
+	ID string
+	// Owner the id of the user that submitted the job, empty for anonymous users.
+	Owner  string
+	Status Status
+	// Result the json encoded result, only set for done jobs.
+	Result []byte
+	// ErrorKey and ErrorMsg describe why the job failed.
+	ErrorKey  string
+	ErrorMsg  string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Func the work of a job, the result must be json serializable. The context is canceled
+// if the job is canceled or runs into the timeout.
+type Func func(ctx context.Context) (any, error)
+
+type Repository interface {
+	// Save creates the job or replaces an existing job with the same id.
+	Save(ctx context.Context, job Job) error
+	// Find returns ErrJobNotFound if there is no job with the id.
+	Find(ctx context.Context, id string) (Job, error)
+	// DeleteBefore removes all jobs that were not updated since the given time.
+	DeleteBefore(ctx context.Context, before time.Time) error
+	// FailUnfinished marks all pending and running jobs that were not updated since before as failed with
+	// the error key and the update time at. Returns the number of failed jobs.
+	FailUnfinished(ctx context.Context, before time.Time, at time.Time, errorKey string) (int, error)
+}
+
+const (
+	StoreMemory   = "memory"
+	StorePostgres = "postgres"
+)
+
+type Config struct {
+	// Workers the number of jobs that are executed at the same time.
+	Workers int `yaml:"workers"`
+	// QueueSize the number of jobs that can wait for a worker, further jobs are rejected.
+	QueueSize int `yaml:"queue_size"`
+	// Timeout of a single job, no timeout if zero.
+	Timeout time.Duration `yaml:"timeout"`
+	// Retention how long jobs are kept after their last update.
+	Retention time.Duration `yaml:"retention"`
+	// Store memory or postgres, memory if empty.
+	Store string `yaml:"store"`
+}
+
+func (c Config) Validate() error {
+	switch c.Store {
+	case "", StoreMemory, StorePostgres:
+		return nil
+	default:
+		return fmt.Errorf("%w %q", ErrUnknownJobStore, c.Store)
+	}
+}
+
+// WorkerCount returns the configured workers, at least one.
+func (c Config) WorkerCount() int {
+	return max(c.Workers, 1)
+}
@@ -0,0 +1,292 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/konstantinfoerster/card-service-go/internal/aerrors"
+)
+
+// cleanupInterval how often jobs older than the retention are removed.
+const cleanupInterval = time.Minute
+
+type task struct {
+	id string
+	fn Func
+}
+
+// Queue executes jobs with a bounded number of workers. The repository is the source of truth for the
+// status of a job, that allows to cancel a job that runs on another instance with a shared repository.
+type Queue struct {
+	cfg     Config
+	repo    Repository
+	tasks   chan task
+	mu      sync.Mutex
+	running map[string]context.CancelFunc
+	created time.Time
+}
+
+func NewQueue(cfg Config, repo Repository) *Queue {
+	return &Queue{
+		cfg:     cfg,
+		repo:    repo,
+		tasks:   make(chan task, max(cfg.QueueSize, 0)),
+		running: make(map[string]context.CancelFunc),
+		created: now(),
+	}
+}
+
+// Run starts the workers and removes old jobs until the context is done. Pending and running jobs of a
+// former run are lost with the tasks of the previous process, they are marked as failed first.
+func (q *Queue) Run(ctx context.Context) error {
+	q.failInterrupted(ctx)
+
+	var wg sync.WaitGroup
+	for range q.cfg.WorkerCount() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case t := <-q.tasks:
+					q.execute(ctx, t)
+				}
+			}
+		}()
+	}
+	defer wg.Wait()
+
+	if q.cfg.Retention <= 0 {
+		<-ctx.Done()
+
+		return nil
+	}
+
+	ticker := time.NewTicker(min(q.cfg.Retention, cleanupInterval))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := q.repo.DeleteBefore(ctx, now().Add(-q.cfg.Retention)); err != nil {
+				slog.Warn("failed to remove old jobs", slog.Any("error", err))
+			}
+		}
+	}
+}
+
+// Submit adds a pending job to the queue, the job is rejected if the queue is full.
+func (q *Queue) Submit(ctx context.Context, owner string, fn Func) (Job, error) {
+	created := now()
+	job := Job{
+		ID:        uuid.New().String(),
+		Owner:     owner,
+		Status:    StatusPending,
+		CreatedAt: created,
+		UpdatedAt: created,
+	}
+	if err := q.repo.Save(ctx, job); err != nil {
+		return Job{}, aerrors.NewUnknownError(err, "unable-to-save-job")
+	}
+
+	select {
+	case q.tasks <- task{id: job.ID, fn: fn}:
+		return job, nil
+	default:
+		job.Status = StatusFailed
+		job.ErrorKey = "queue-full"
+		job.UpdatedAt = now()
+		if err := q.repo.Save(ctx, job); err != nil {
+			slog.Warn("failed to save rejected job", slog.String("id", job.ID), slog.Any("error", err))
+		}
+
+		return Job{}, aerrors.NewUnavailableError(ErrQueueFull, "queue-full", "too many jobs, try again later")
+	}
+}
+
+// Get returns the job, jobs of other users are not found. Jobs of anonymous users can be read by everyone
+// who knows the id.
+func (q *Queue) Get(ctx context.Context, owner string, id string) (Job, error) {
+	job, err := q.repo.Find(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrJobNotFound) {
+			return Job{}, aerrors.NewNotFoundError(err, "job-not-found")
+		}
+
+		return Job{}, aerrors.NewUnknownError(err, "unable-to-find-job")
+	}
+
+	if job.Owner != "" && job.Owner != owner {
+		return Job{}, aerrors.NewNotFoundError(fmt.Errorf("%s, %w", id, ErrJobNotFound), "job-not-found")
+	}
+
+	return job, nil
+}
+
+// Cancel stops a pending or running job, finished jobs are returned unchanged.
+func (q *Queue) Cancel(ctx context.Context, owner string, id string) (Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job, err := q.Get(ctx, owner, id)
+	if err != nil {
+		return Job{}, err
+	}
+	if job.Status.Finished() {
+		return job, nil
+	}
+
+	if cancel, ok := q.running[id]; ok {
+		cancel()
+	}
+	job.Status = StatusCanceled
+	job.UpdatedAt = now()
+	if err := q.repo.Save(ctx, job); err != nil {
+		return Job{}, aerrors.NewUnknownError(err, "unable-to-save-job")
+	}
+
+	return job, nil
+}
+
+// failInterrupted fails the unfinished jobs that were not updated within the timeout before the queue was
+// created. With a shared repository the jobs of other instances are in progress if they were updated within
+// the timeout. Without a timeout a job of another instance can run for any time, no job is failed.
+func (q *Queue) failInterrupted(ctx context.Context) {
+	if q.cfg.Timeout <= 0 {
+		return
+	}
+
+	before := q.created.Add(-q.cfg.Timeout)
+
+	n, err := q.repo.FailUnfinished(ctx, before, now(), "interrupted")
+	if err != nil {
+		slog.Warn("failed to fail interrupted jobs", slog.Any("error", err))
+
+		return
+	}
+	if n > 0 {
+		slog.Info("interrupted jobs failed", slog.Int("jobs", n))
+	}
+}
+
+func (q *Queue) execute(ctx context.Context, t task) {
+	var jobCtx context.Context
+	var cancel context.CancelFunc
+	if q.cfg.Timeout > 0 {
+		jobCtx, cancel = context.WithTimeout(ctx, q.cfg.Timeout)
+	} else {
+		jobCtx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	job, ok := q.start(ctx, t.id, cancel)
+	if !ok {
+		return
+	}
+
+	result, err := call(jobCtx, t.fn)
+
+	q.finish(ctx, job, result, err, jobCtx.Err())
+}
+
+// call runs the job function, a panic fails the job instead of stopping the process.
+func call(ctx context.Context, fn Func) (any, error) {
+	var result any
+	var err error
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				slog.Error("job panicked", slog.Any("panic", r), slog.String("stack", string(debug.Stack())))
+				err = fmt.Errorf("%w, %v", ErrJobPanic, r)
+			}
+		}()
+
+		result, err = fn(ctx)
+	}()
+
+	return result, err
+}
+
+// start marks the job as running, canceled jobs are skipped.
+func (q *Queue) start(ctx context.Context, id string, cancel context.CancelFunc) (Job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job, err := q.repo.Find(ctx, id)
+	if err != nil {
+		slog.Warn("failed to load job", slog.String("id", id), slog.Any("error", err))
+
+		return Job{}, false
+	}
+	if job.Status != StatusPending {
+		return Job{}, false
+	}
+
+	job.Status = StatusRunning
+	job.UpdatedAt = now()
+	if err := q.repo.Save(ctx, job); err != nil {
+		slog.Warn("failed to save job", slog.String("id", id), slog.Any("error", err))
+
+		return Job{}, false
+	}
+	q.running[id] = cancel
+
+	return job, true
+}
+
+// finish saves the result of the job, unless the job was canceled in the meantime.
+func (q *Queue) finish(ctx context.Context, job Job, result any, err error, ctxErr error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	delete(q.running, job.ID)
+	current, fErr := q.repo.Find(ctx, job.ID)
+	if fErr != nil || current.Status == StatusCanceled {
+		return
+	}
+
+	job.UpdatedAt = now()
+	switch {
+	case errors.Is(ctxErr, context.DeadlineExceeded):
+		job.Status = StatusFailed
+		job.ErrorKey = "job-timeout"
+	case err != nil:
+		job.Status = StatusFailed
+		job.ErrorKey = "internal-error"
+		var appErr aerrors.AppError
+		if errors.As(err, &appErr) {
+			job.ErrorKey = appErr.Key
+			job.ErrorMsg = appErr.Msg
+		}
+		slog.Warn("job failed", slog.String("id", job.ID), slog.Any("error", err))
+	default:
+		data, mErr := json.Marshal(result)
+		if mErr != nil {
+			job.Status = StatusFailed
+			job.ErrorKey = "invalid-result"
+			slog.Warn("failed to encode job result", slog.String("id", job.ID), slog.Any("error", mErr))
+
+			break
+		}
+		job.Status = StatusDone
+		job.Result = data
+	}
+
+	if err := q.repo.Save(ctx, job); err != nil {
+		slog.Warn("failed to save job", slog.String("id", job.ID), slog.Any("error", err))
+	}
+}
+
+func now() time.Time {
+	return time.Now().UTC()
+}
@@ -0,0 +1,260 @@
+package jobs_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/konstantinfoerster/card-service-go/internal/aerrors"
+	"github.com/konstantinfoerster/card-service-go/internal/cards/memory"
+	"github.com/konstantinfoerster/card-service-go/internal/jobs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueueRunsJobs(t *testing.T) {
+	cases := []struct {
+		name        string
+		fn          jobs.Func
+		expected    jobs.Status
+		expectedKey string
+	}{
+		{
+			name: "done",
+			fn: func(_ context.Context) (any, error) {
+				return map[string]int{"regions": 2}, nil
+			},
+			expected: jobs.StatusDone,
+		},
+		{
+			name: "app error",
+			fn: func(_ context.Context) (any, error) {
+				return nil, aerrors.NewInvalidInputError(errors.New("broken"), "invalid-file", "broken image")
+			},
+			expected:    jobs.StatusFailed,
+			expectedKey: "invalid-file",
+		},
+		{
+			name: "unknown error",
+			fn: func(_ context.Context) (any, error) {
+				return nil, errors.New("broken")
+			},
+			expected:    jobs.StatusFailed,
+			expectedKey: "internal-error",
+		},
+		{
+			name: "panic",
+			fn: func(_ context.Context) (any, error) {
+				panic("broken image")
+			},
+			expected:    jobs.StatusFailed,
+			expectedKey: "internal-error",
+		},
+		{
+			name: "timeout",
+			fn: func(ctx context.Context) (any, error) {
+				<-ctx.Done()
+
+				return nil, ctx.Err()
+			},
+			expected:    jobs.StatusFailed,
+			expectedKey: "job-timeout",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			queue := runQueue(t, jobs.Config{Workers: 1, QueueSize: 1, Timeout: 50 * time.Millisecond})
+
+			job, err := queue.Submit(ctx, "myUser", tc.fn)
+
+			require.NoError(t, err)
+			assert.Equal(t, jobs.StatusPending, job.Status)
+			finished := waitFor(t, queue, job.ID, tc.expected)
+			assert.Equal(t, tc.expectedKey, finished.ErrorKey)
+			if tc.expected == jobs.StatusDone {
+				assert.JSONEq(t, `{"regions":2}`, string(finished.Result))
+			}
+		})
+	}
+}
+
+func TestQueueCancel(t *testing.T) {
+	ctx := context.Background()
+	queue := runQueue(t, jobs.Config{Workers: 1, QueueSize: 1})
+	started := make(chan struct{})
+	stopped := make(chan struct{})
+	running, err := queue.Submit(ctx, "myUser", func(ctx context.Context) (any, error) {
+		close(started)
+		<-ctx.Done()
+		close(stopped)
+
+		return nil, ctx.Err()
+	})
+	require.NoError(t, err)
+	<-started
+	// waits for the only worker
+	pending, err := queue.Submit(ctx, "myUser", func(_ context.Context) (any, error) {
+		t.Error("canceled job must not run")
+
+		return nil, nil
+	})
+	require.NoError(t, err)
+
+	canceled, err := queue.Cancel(ctx, "myUser", pending.ID)
+	require.NoError(t, err)
+	assert.Equal(t, jobs.StatusCanceled, canceled.Status)
+
+	canceled, err = queue.Cancel(ctx, "myUser", running.ID)
+	require.NoError(t, err)
+	assert.Equal(t, jobs.StatusCanceled, canceled.Status)
+	<-stopped
+
+	// the canceled pending job is skipped and the running job keeps its status
+	done, err := queue.Submit(ctx, "myUser", func(_ context.Context) (any, error) {
+		return true, nil
+	})
+	require.NoError(t, err)
+	waitFor(t, queue, done.ID, jobs.StatusDone)
+	for _, id := range []string{running.ID, pending.ID} {
+		job, err := queue.Get(ctx, "myUser", id)
+		require.NoError(t, err)
+		assert.Equal(t, jobs.StatusCanceled, job.Status)
+	}
+}
+
+func TestQueueRejectsJobsIfFull(t *testing.T) {
+	// without running workers the queue only accepts one job
+	queue := jobs.NewQueue(jobs.Config{QueueSize: 1}, memory.NewJobRepository())
+	noop := func(_ context.Context) (any, error) { return nil, nil }
+	_, err := queue.Submit(context.Background(), "myUser", noop)
+	require.NoError(t, err)
+
+	_, err = queue.Submit(context.Background(), "myUser", noop)
+
+	require.ErrorIs(t, err, jobs.ErrQueueFull)
+	var appErr aerrors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, aerrors.ErrUnavailable, appErr.ErrorType)
+}
+
+func TestQueueGet(t *testing.T) {
+	ctx := context.Background()
+	queue := jobs.NewQueue(jobs.Config{QueueSize: 2}, memory.NewJobRepository())
+	noop := func(_ context.Context) (any, error) { return nil, nil }
+	owned, err := queue.Submit(ctx, "myUser", noop)
+	require.NoError(t, err)
+	anonymous, err := queue.Submit(ctx, "", noop)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name  string
+		owner string
+		id    string
+		found bool
+	}{
+		{name: "own job", owner: "myUser", id: owned.ID, found: true},
+		{name: "job of other user", owner: "otherUser", id: owned.ID},
+		{name: "anonymous job", owner: "otherUser", id: anonymous.ID, found: true},
+		{name: "unknown job", owner: "myUser", id: "unknown"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			job, err := queue.Get(ctx, tc.owner, tc.id)
+
+			if !tc.found {
+				require.ErrorIs(t, err, jobs.ErrJobNotFound)
+
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.id, job.ID)
+		})
+	}
+}
+
+func TestQueueFailsInterruptedJobs(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewJobRepository()
+	old := time.Now().UTC().Add(-time.Hour)
+	recent := time.Now().UTC()
+	saved := []jobs.Job{
+		{ID: "pending", Owner: "myUser", Status: jobs.StatusPending, CreatedAt: old, UpdatedAt: old},
+		{ID: "running", Owner: "myUser", Status: jobs.StatusRunning, CreatedAt: old, UpdatedAt: old},
+		{ID: "done", Owner: "myUser", Status: jobs.StatusDone, CreatedAt: old, UpdatedAt: old},
+		{ID: "other instance", Owner: "myUser", Status: jobs.StatusRunning, CreatedAt: recent, UpdatedAt: recent},
+	}
+	for _, j := range saved {
+		require.NoError(t, repo.Save(ctx, j))
+	}
+
+	queue := runQueueWith(t, jobs.Config{Workers: 1, QueueSize: 1, Timeout: time.Minute}, repo)
+
+	for _, id := range []string{"pending", "running"} {
+		job := waitFor(t, queue, id, jobs.StatusFailed)
+		assert.Equal(t, "interrupted", job.ErrorKey)
+	}
+	done, err := queue.Get(ctx, "myUser", "done")
+	require.NoError(t, err)
+	assert.Equal(t, jobs.StatusDone, done.Status)
+	other, err := queue.Get(ctx, "myUser", "other instance")
+	require.NoError(t, err)
+	assert.Equal(t, jobs.StatusRunning, other.Status)
+}
+
+func TestQueueKeepsUnfinishedJobsWithoutTimeout(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewJobRepository()
+	old := time.Now().UTC().Add(-time.Hour)
+	running := jobs.Job{ID: "other instance", Owner: "myUser", Status: jobs.StatusRunning, CreatedAt: old, UpdatedAt: old}
+	require.NoError(t, repo.Save(ctx, running))
+
+	queue := runQueueWith(t, jobs.Config{Workers: 1, QueueSize: 1}, repo)
+	job, err := queue.Submit(ctx, "myUser", func(_ context.Context) (any, error) {
+		return "ok", nil
+	})
+	require.NoError(t, err)
+	waitFor(t, queue, job.ID, jobs.StatusDone)
+
+	other, err := queue.Get(ctx, "myUser", "other instance")
+	require.NoError(t, err)
+	assert.Equal(t, jobs.StatusRunning, other.Status)
+}
+
+func runQueue(t *testing.T, cfg jobs.Config) *jobs.Queue {
+	t.Helper()
+
+	return runQueueWith(t, cfg, memory.NewJobRepository())
+}
+
+func runQueueWith(t *testing.T, cfg jobs.Config, repo jobs.Repository) *jobs.Queue {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	queue := jobs.NewQueue(cfg, repo)
+	done := make(chan error)
+	go func() {
+		done <- queue.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-done)
+	})
+
+	return queue
+}
+
+func waitFor(t *testing.T, queue *jobs.Queue, id string, status jobs.Status) jobs.Job {
+	t.Helper()
+
+	var job jobs.Job
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = queue.Get(context.Background(), "myUser", id)
+
+		return err == nil && job.Status == status
+	}, time.Second, 5*time.Millisecond)
+
+	return job
+}